@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/StackExchange/dnscontrol/v4/pkg/credsfile"
+	"github.com/StackExchange/dnscontrol/v4/pkg/diff2"
+	"github.com/StackExchange/dnscontrol/v4/pkg/normalize"
+	"github.com/urfave/cli/v2"
+)
+
+var _ = cmd(catMain, func() *cli.Command {
+	var args CheckConsistencyArgs
+	return &cli.Command{
+		Name:  "check-consistency",
+		Usage: "compare the records served by each DNS provider of a dual-hosted domain",
+		Action: func(ctx *cli.Context) error {
+			return exit(CheckConsistency(args))
+		},
+		Flags: args.flags(),
+	}
+}())
+
+// CheckConsistencyArgs contains all data/flags needed to run check-consistency, independently of CLI.
+type CheckConsistencyArgs struct {
+	GetDNSConfigArgs
+	GetCredentialsArgs
+	FilterArgs
+	IncludeSOA bool
+}
+
+func (args *CheckConsistencyArgs) flags() []cli.Flag {
+	flags := args.GetDNSConfigArgs.flags()
+	flags = append(flags, args.GetCredentialsArgs.flags()...)
+	flags = append(flags, args.FilterArgs.flags()...)
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "include-soa",
+		Destination: &args.IncludeSOA,
+		Usage:       `Also compare SOA records (they usually differ between providers)`,
+	})
+	return flags
+}
+
+// CheckConsistency fetches each domain from all of its DNS providers and
+// reports where the providers disagree.
+func CheckConsistency(args CheckConsistencyArgs) error {
+	cfg, err := GetDNSConfig(args.GetDNSConfigArgs)
+	if err != nil {
+		return err
+	}
+	providerConfigs, err := credsfile.LoadProviderConfigs(args.CredsFile)
+	if err != nil {
+		return err
+	}
+	if _, err := PInitializeProviders(cfg, providerConfigs, false); err != nil {
+		return err
+	}
+	errs := normalize.ValidateAndNormalizeConfig(cfg)
+	if PrintValidationErrors(errs) {
+		return errors.New("exiting due to validation errors")
+	}
+
+	// Unlike preview/push, the default is every provider of the domain.
+	pfilter := args.Providers
+	if pfilter == "" {
+		pfilter = "all"
+	}
+
+	var totalDiffs int
+	var anyErrors bool
+	for _, zone := range whichZonesToProcess(cfg.Domains, args.Domains) {
+		provs := whichProvidersToProcess(zone.DNSProviderInstances, pfilter)
+		if len(provs) < 2 {
+			continue
+		}
+		fmt.Printf("******************** Domain: %s\n", zone.GetUniqueName())
+
+		n, err := compareProviders(zone, provs, args.IncludeSOA)
+		if err != nil {
+			fmt.Printf("ERROR: %s\n", err)
+			anyErrors = true
+		}
+		totalDiffs += n
+	}
+
+	if anyErrors {
+		return errors.New("completed with errors")
+	}
+	if totalDiffs != 0 {
+		return fmt.Errorf("found %d difference(s) between providers", totalDiffs)
+	}
+	fmt.Println("Done. All providers agree.")
+	return nil
+}
+
+// compareProviders compares the zone as served by provs[0] with the zone
+// as served by each of the other providers. It prints the differences and
+// returns how many there were.
+func compareProviders(zone *models.DomainConfig, provs []*models.DNSProviderInstance, includeSOA bool) (int, error) {
+	zones := make([]models.Records, len(provs))
+	for i, p := range provs {
+		recs, err := p.Driver.GetZoneRecords(zone.Name, zone.Metadata)
+		if err != nil {
+			return 0, fmt.Errorf("domain %q provider %s: %w", zone.Name, p.Name, err)
+		}
+		// Compare them the way preview/push would.
+		models.Downcase(recs)
+		models.CanonicalizeTargets(recs, zone.Name)
+		if !includeSOA {
+			recs = filterOutSOA(recs)
+		}
+		zones[i] = recs
+	}
+
+	var count int
+	for i := 1; i < len(provs); i++ {
+		msgs := diff2.Compare(zone.Name, zones[0], zones[i], nil)
+		if len(msgs) == 0 {
+			fmt.Printf("----- %s and %s agree (%d records)\n", provs[0].Name, provs[i].Name, len(zones[0]))
+			continue
+		}
+		fmt.Printf("----- %s and %s differ. Changes that would turn %s's zone into %s's:\n", provs[0].Name, provs[i].Name, provs[0].Name, provs[i].Name)
+		for _, m := range msgs {
+			fmt.Println(m)
+		}
+		count += len(msgs)
+	}
+	return count, nil
+}
+
+func filterOutSOA(recs models.Records) models.Records {
+	var r models.Records
+	for _, rec := range recs {
+		if rec.Type != "SOA" {
+			r = append(r, rec)
+		}
+	}
+	return r
+}
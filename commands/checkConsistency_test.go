@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/StackExchange/dnscontrol/v4/pkg/zonerecs"
+	"github.com/StackExchange/dnscontrol/v4/providers"
+	_ "github.com/StackExchange/dnscontrol/v4/providers/_all"
+)
+
+func Test_compareProviders(t *testing.T) {
+	mkRec := func(label, rtype, target string, ttl uint32) *models.RecordConfig {
+		r := &models.RecordConfig{Type: rtype, TTL: ttl}
+		r.SetLabel(label, "example.com")
+		if err := r.SetTarget(target); err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	// Two in-memory providers, loaded with slightly different zones.
+	var provs []*models.DNSProviderInstance
+	for _, p := range []struct {
+		name string
+		recs models.Records
+	}{
+		{"primary", models.Records{mkRec("@", "A", "1.2.3.4", 300), mkRec("www", "A", "1.2.3.4", 300)}},
+		{"secondary", models.Records{mkRec("@", "A", "1.2.3.4", 300), mkRec("www", "A", "1.2.3.4", 600)}},
+	} {
+		driver, err := providers.CreateDNSProvider("MEMORY", map[string]string{}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dc := &models.DomainConfig{Name: "example.com", Records: p.recs, Metadata: map[string]string{}}
+		_, corrections, _, err := zonerecs.CorrectZoneRecords(driver, dc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range corrections {
+			if err := c.F(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		provs = append(provs, &models.DNSProviderInstance{Driver: driver, ProviderBase: models.ProviderBase{Name: p.name}})
+	}
+
+	zone := &models.DomainConfig{Name: "example.com", Metadata: map[string]string{}}
+	n, err := compareProviders(zone, provs, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("compareProviders() = %d differences, want 1 (the TTL of www)", n)
+	}
+
+	n, err = compareProviders(zone, []*models.DNSProviderInstance{provs[0], provs[0]}, false)
+	if err != nil || n != 0 {
+		t.Errorf("compareProviders(same) = %d, %v; want 0, nil", n, err)
+	}
+}
@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/StackExchange/dnscontrol/v4/pkg/bindserial"
+	"github.com/StackExchange/dnscontrol/v4/pkg/credsfile"
+	"github.com/StackExchange/dnscontrol/v4/pkg/dnsverify"
+	"github.com/StackExchange/dnscontrol/v4/pkg/nameservers"
+	"github.com/StackExchange/dnscontrol/v4/pkg/normalize"
+	"github.com/StackExchange/dnscontrol/v4/pkg/soaserial"
+	"github.com/StackExchange/dnscontrol/v4/providers"
+	"github.com/urfave/cli/v2"
+)
+
+var _ = cmd(catMain, func() *cli.Command {
+	var args CheckSerialsArgs
+	return &cli.Command{
+		Name:  "check-serials",
+		Usage: "warn if the next SOA serial number would not be greater than the secondaries' (RFC 1982)",
+		Action: func(ctx *cli.Context) error {
+			return exit(CheckSerials(args))
+		},
+		Flags: args.flags(),
+	}
+}())
+
+// CheckSerialsArgs contains all data/flags needed to run check-serials, independently of CLI.
+type CheckSerialsArgs struct {
+	GetDNSConfigArgs
+	GetCredentialsArgs
+	FilterArgs
+	Servers string
+}
+
+func (args *CheckSerialsArgs) flags() []cli.Flag {
+	flags := args.GetDNSConfigArgs.flags()
+	flags = append(flags, args.GetCredentialsArgs.flags()...)
+	flags = append(flags, args.FilterArgs.flags()...)
+	flags = append(flags, &cli.StringFlag{
+		Name:        "servers",
+		Destination: &args.Servers,
+		Usage:       `Secondaries to query (comma separated list); default is the nameservers of the domain`,
+	})
+	flags = append(flags, &cli.Int64Flag{
+		Name:        "bindserial",
+		Destination: &bindserial.ForcedValue,
+		Usage:       `Force BIND serial numbers to this value, as push --bindserial does`,
+	})
+	return flags
+}
+
+// CheckSerials compares, for each domain, the serial number that the
+// next change would give the SOA with the serial numbers that the
+// secondaries serve. A secondary doesn't transfer a zone whose serial
+// number isn't greater than its own, so such changes would not reach
+// it.
+func CheckSerials(args CheckSerialsArgs) error {
+	cfg, err := GetDNSConfig(args.GetDNSConfigArgs)
+	if err != nil {
+		return err
+	}
+	providerConfigs, err := credsfile.LoadProviderConfigs(args.CredsFile)
+	if err != nil {
+		return err
+	}
+	if _, err := PInitializeProviders(cfg, providerConfigs, false); err != nil {
+		return err
+	}
+	errs := normalize.ValidateAndNormalizeConfig(cfg)
+	if PrintValidationErrors(errs) {
+		return errors.New("exiting due to validation errors")
+	}
+
+	pfilter := args.Providers
+	if pfilter == "" {
+		pfilter = "all"
+	}
+
+	var totalWarnings int
+	var anyErrors bool
+	for _, zone := range whichZonesToProcess(cfg.Domains, args.Domains) {
+		fmt.Printf("******************** Domain: %s\n", zone.GetUniqueName())
+		servers, err := args.secondaries(zone)
+		if err != nil {
+			fmt.Printf("ERROR: %s\n", err)
+			anyErrors = true
+			continue
+		}
+		for _, p := range whichProvidersToProcess(zone.DNSProviderInstances, pfilter) {
+			n, err := checkSerials(zone, p, servers)
+			if err != nil {
+				fmt.Printf("ERROR: %s\n", err)
+				anyErrors = true
+			}
+			totalWarnings += n
+		}
+	}
+
+	if anyErrors {
+		return errors.New("completed with errors")
+	}
+	if totalWarnings != 0 {
+		return fmt.Errorf("found %d serial number(s) that would not move forward", totalWarnings)
+	}
+	fmt.Println("Done. All serial numbers would move forward.")
+	return nil
+}
+
+// secondaries returns the servers to check the serial numbers of zone
+// against: those of --servers, or else the nameservers of the zone, as
+// push determines them.
+func (args *CheckSerialsArgs) secondaries(zone *models.DomainConfig) ([]string, error) {
+	if args.Servers != "" {
+		return strings.Split(args.Servers, ","), nil
+	}
+	nsList, err := nameservers.DetermineNameserversForProviders(zone, zone.DNSProviderInstances, true)
+	if err != nil {
+		return nil, err
+	}
+	var servers []string
+	for _, ns := range nsList {
+		servers = append(servers, ns.Name)
+	}
+	return servers, nil
+}
+
+// serialOf returns the serial number of zone at server. Replaced by
+// tests.
+var serialOf = dnsverify.Serial
+
+// checkSerials checks the next serial number of the zone at the
+// provider p against servers. It prints the results and returns how
+// many servers would not accept it.
+func checkSerials(zone *models.DomainConfig, p *models.DNSProviderInstance, servers []string) (int, error) {
+	sp, ok := p.Driver.(providers.SerialPolicier)
+	if !ok {
+		fmt.Printf("----- %s: the provider chooses the serial numbers. Skipping.\n", p.Name)
+		return 0, nil
+	}
+	policy := sp.SerialPolicy()
+	if policy == "" {
+		fmt.Printf("----- %s: the server chooses the serial numbers. Skipping.\n", p.Name)
+		return 0, nil
+	}
+	recs, err := p.Driver.GetZoneRecords(zone.Name, zone.Metadata)
+	if err != nil {
+		return 0, fmt.Errorf("domain %q provider %s: %w", zone.Name, p.Name, err)
+	}
+	var soa *models.RecordConfig
+	for _, r := range recs {
+		if r.Type == "SOA" && r.Name == "@" {
+			soa = r
+		}
+	}
+	if soa == nil {
+		fmt.Printf("----- %s: the zone has no SOA yet. Skipping.\n", p.Name)
+		return 0, nil
+	}
+
+	next := sp.NextSerial(soa.SoaSerial, soaserial.Content(zone.Records))
+	fmt.Printf("----- %s: serial %d, next %d (serial-policy %s)\n", p.Name, soa.SoaSerial, next, policy)
+	if len(servers) == 0 {
+		return 0, fmt.Errorf("domain %q provider %s: no nameservers to check. Use --servers", zone.Name, p.Name)
+	}
+	var count int
+	for _, server := range servers {
+		serial, err := serialOf(server, zone.Name)
+		switch {
+		case err != nil:
+			fmt.Printf("WARNING: %s: %s\n", server, err)
+		case !soaserial.Greater(next, serial):
+			fmt.Printf("WARNING: %s: serial %d; it would ignore serial %d\n", server, serial, next)
+			count++
+		default:
+			fmt.Printf("%s: serial %d: OK\n", server, serial)
+		}
+	}
+	return count, nil
+}
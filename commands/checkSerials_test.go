@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/StackExchange/dnscontrol/v4/pkg/bindserial"
+	"github.com/StackExchange/dnscontrol/v4/pkg/zonerecs"
+	"github.com/StackExchange/dnscontrol/v4/providers"
+)
+
+func Test_secondaries(t *testing.T) {
+	driver, err := providers.CreateDNSProvider("MEMORY", map[string]string{"nameservers": "ns1.example.net,ns2.example.net"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zone := &models.DomainConfig{Name: "example.com", Metadata: map[string]string{}, DNSProviderInstances: []*models.DNSProviderInstance{
+		{Driver: driver, ProviderBase: models.ProviderBase{Name: "memory"}, NumberOfNameservers: -1},
+	}}
+
+	var args CheckSerialsArgs
+	if got, err := args.secondaries(zone); err != nil || !reflect.DeepEqual(got, []string{"ns1.example.net", "ns2.example.net"}) {
+		t.Errorf("secondaries() = %v, %v; want the nameservers of the provider", got, err)
+	}
+	args.Servers = "192.0.2.1,192.0.2.2"
+	if got, err := args.secondaries(zone); err != nil || !reflect.DeepEqual(got, []string{"192.0.2.1", "192.0.2.2"}) {
+		t.Errorf("secondaries() = %v, %v; want --servers", got, err)
+	}
+}
+
+func Test_checkSerials(t *testing.T) {
+	savedSerialOf, savedForced := serialOf, bindserial.ForcedValue
+	defer func() { serialOf, bindserial.ForcedValue = savedSerialOf, savedForced }()
+	bindserial.ForcedValue = 2024030500
+	serialOf = func(server, zone string) (uint32, error) {
+		switch server {
+		case "ns1.example.net":
+			return 2024030400, nil
+		case "ns2.example.net":
+			return 2024030600, nil
+		}
+		return 0, errors.New("timeout")
+	}
+
+	driver, err := providers.CreateDNSProvider("BIND", map[string]string{"directory": t.TempDir()}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &models.RecordConfig{Type: "A", TTL: 300}
+	a.SetLabel("www", "example.com")
+	if err := a.SetTarget("192.0.2.1"); err != nil {
+		t.Fatal(err)
+	}
+	zone := &models.DomainConfig{Name: "example.com", Records: models.Records{a}, Metadata: map[string]string{models.DomainUniqueName: "example.com"}}
+	_, corrections, _, err := zonerecs.CorrectZoneRecords(driver, zone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range corrections {
+		if err := c.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := &models.DNSProviderInstance{Driver: driver, ProviderBase: models.ProviderBase{Name: "bind"}}
+
+	n, err := checkSerials(zone, p, []string{"ns1.example.net", "ns2.example.net", "ns3.example.net"})
+	if err != nil || n != 1 {
+		t.Errorf("checkSerials() = %d, %v; want 1 (ns2)", n, err)
+	}
+
+	// Without servers, nothing is checked: that must not pass.
+	if _, err := checkSerials(zone, p, nil); err == nil {
+		t.Error("checkSerials() without servers succeeded, want an error")
+	}
+}
@@ -59,6 +59,24 @@ func Run(v string) int {
 			Usage:       "Enable JS fetch(), dangerous on untrusted code!",
 			Destination: &js.EnableFetch,
 		},
+		&cli.StringFlag{
+			Name:        "fetch-lockfile",
+			Usage:       "File that JS fetch() responses are recorded in and replayed from (default: fetch.lock.json next to the config)",
+			Destination: &js.FetchLockfile,
+		},
+		&cli.BoolFlag{
+			Name:        "refresh-fetch",
+			Usage:       "Ignore the fetch lockfile and fetch everything again (needs --allow-fetch)",
+			Destination: &js.RefreshFetch,
+		},
+		&cli.StringSliceFlag{
+			Name:  "fetch-allow",
+			Usage: "URL prefix that JS fetch() may use (repeatable; default: any URL)",
+			Action: func(ctx *cli.Context, prefixes []string) error {
+				js.FetchAllow = prefixes
+				return nil
+			},
+		},
 		&cli.BoolFlag{
 			Name:   "diff2",
 			Usage:  "Obsolete flag. Will be removed in v5 or later",
@@ -213,6 +231,7 @@ type ExecuteDSLArgs struct {
 	JSONFile string
 	DevMode  bool
 	Variable cli.StringSlice
+	VarsFile string
 }
 
 func (args *ExecuteDSLArgs) flags() []cli.Flag {
@@ -221,7 +240,7 @@ func (args *ExecuteDSLArgs) flags() []cli.Flag {
 			Name:        "config",
 			Value:       "dnsconfig.js",
 			Destination: &args.JSFile,
-			Usage:       "File containing dns config in javascript DSL",
+			Usage:       "File containing dns config in javascript DSL (or YAML, if the name ends in .yaml or .yml)",
 		},
 		&cli.StringFlag{
 			Name:        "js",
@@ -241,6 +260,11 @@ func (args *ExecuteDSLArgs) flags() []cli.Flag {
 			Destination: &args.Variable,
 			Usage:       "Add variable that is passed to JS",
 		},
+		&cli.StringFlag{
+			Name:        "vars-file",
+			Destination: &args.VarsFile,
+			Usage:       "JSON (or YAML) file whose top-level keys are passed to JS as variables",
+		},
 	}
 }
 
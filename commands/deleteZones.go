@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/StackExchange/dnscontrol/v4/pkg/credsfile"
+	"github.com/StackExchange/dnscontrol/v4/providers"
+	"github.com/urfave/cli/v2"
+)
+
+var _ = cmd(catUtils, func() *cli.Command {
+	var args DeleteZonesArgs
+	return &cli.Command{
+		Name:  "delete-zones",
+		Usage: "deletes zones at a provider (stand-alone)",
+		Action: func(ctx *cli.Context) error {
+			if ctx.NArg() < 2 {
+				return cli.Exit("Arguments should be: credskey zone(s) (Ex: axfr example.com)", 1)
+			}
+			args.CredName = ctx.Args().Get(0)
+			args.ZoneNames = ctx.Args().Slice()[1:]
+			return exit(DeleteZones(args))
+		},
+		Flags:     args.flags(),
+		UsageText: "dnscontrol delete-zones [command options] credkey zone [...]",
+		Description: `Delete zones at a provider.  This is a stand-alone utility.
+
+Only some providers can delete zones. BIND and AXFRDDNS remove the zone
+from their catalog zone (see "catalog" in their documentation).
+
+ARGUMENTS:
+   credkey:  The name used in creds.json (first parameter to NewDnsProvider() in dnsconfig.js)
+   zone:     One or more zones (domains) to delete.
+
+EXAMPLES:
+   dnscontrol delete-zones axfr old.example.com`,
+	}
+}())
+
+// DeleteZonesArgs args required for the delete-zones subcommand.
+type DeleteZonesArgs struct {
+	GetCredentialsArgs          // Args related to creds.json
+	CredName           string   // key in creds.json
+	ZoneNames          []string // The zones to delete
+}
+
+func (args *DeleteZonesArgs) flags() []cli.Flag {
+	return args.GetCredentialsArgs.flags()
+}
+
+// DeleteZones contains all data/flags needed to run delete-zones, independently of CLI.
+func DeleteZones(args DeleteZonesArgs) error {
+	providerConfigs, err := credsfile.LoadProviderConfigs(args.CredsFile)
+	if err != nil {
+		return fmt.Errorf("failed DeleteZones LoadProviderConfigs(%q): %w", args.CredsFile, err)
+	}
+	provider, err := providers.CreateDNSProvider("-", providerConfigs[args.CredName], nil)
+	if err != nil {
+		return fmt.Errorf("failed DeleteZones CDP: %w", err)
+	}
+	deleter, ok := provider.(providers.ZoneDeleter)
+	if !ok {
+		return fmt.Errorf("provider %s cannot delete zones", args.CredName)
+	}
+
+	for _, zone := range args.ZoneNames {
+		if err := deleter.DeleteZone(zone); err != nil {
+			return err
+		}
+		fmt.Printf("Deleted %s\n", zone)
+	}
+	return nil
+}
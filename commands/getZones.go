@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/StackExchange/dnscontrol/v4/models"
@@ -53,6 +54,7 @@ FORMATS:
    --format=djs       js with disco commas (leading commas)
    --format=zone      BIND zonefile format
    --format=tsv       TAB separated value (useful for AWK)
+   --format=json      IR (json) for "preview --ir"; nothing is lost
    --format=nameonly  Just print the zone names
 
 The columns in --format=tsv are:
@@ -70,7 +72,8 @@ EXAMPLES:
    dnscontrol get-zones gmain GANDI_V5 example.com other.com
    dnscontrol get-zones cfmain CLOUDFLAREAPI all
    dnscontrol get-zones --format=tsv bind BIND example.com
-   dnscontrol get-zones --format=djs --out=draft.js gcloud GCLOUD example.com`,
+   dnscontrol get-zones --format=djs --out=draft.js gcloud GCLOUD example.com
+   dnscontrol get-zones --format=json --out=ir.json cfmain - example.com`,
 	}
 }())
 
@@ -137,7 +140,7 @@ func (args *GetZoneArgs) flags() []cli.Flag {
 		Name:        "format",
 		Destination: &args.OutputFormat,
 		Value:       "zone",
-		Usage:       `Output format: js djs zone tsv nameonly`,
+		Usage:       `Output format: js djs zone tsv json nameonly`,
 	})
 	flags = append(flags, &cli.StringFlag{
 		Name:        "out",
@@ -207,6 +210,15 @@ func GetZone(args GetZoneArgs) error {
 		zoneRecs[i] = recs
 	}
 
+	if args.OutputFormat == "json" {
+		dat, err := json.MarshalIndent(zonesIR(args.CredName, args.ProviderName, zones, zoneRecs), "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed GetZone json: %w", err)
+		}
+		_, err = fmt.Fprintf(w, "%s\n", dat)
+		return err
+	}
+
 	// Write the heading:
 
 	dspVariableName := "DSP_" + strings.ToUpper(args.CredName)
@@ -303,6 +315,42 @@ func GetZone(args GetZoneArgs) error {
 	return nil
 }
 
+// zonesIR returns the zones as an IR document that "preview --ir" can
+// read. Unlike the js format, the records are kept exactly as the
+// provider returned them, metadata and all, so that previewing the
+// result finds nothing to change. The apex NS records become the
+// domain's nameservers, which is how the IR expresses them.
+func zonesIR(credName, providerName string, zones []string, zoneRecs []models.Records) *models.DNSConfig {
+	if providerName == "" {
+		providerName = "-"
+	}
+	cfg := &models.DNSConfig{
+		Registrars:   []*models.RegistrarConfig{{Name: "none", Type: "NONE"}},
+		DNSProviders: []*models.DNSProviderConfig{{Name: credName, Type: providerName}},
+	}
+	for i, recs := range zoneRecs {
+		dc := &models.DomainConfig{
+			Name:          zones[i],
+			RegistrarName: "none",
+			// The nameservers are listed explicitly (below), so don't
+			// ask the provider for them again.
+			DNSProviderNames: map[string]int{credName: 0},
+			Metadata:         map[string]string{},
+			Records:          models.Records{},
+		}
+		for _, rec := range recs {
+			if rec.Type == "NS" && rec.GetLabel() == "@" {
+				dc.Nameservers = append(dc.Nameservers, &models.Nameserver{Name: rec.GetTargetField()})
+				dc.Metadata["ns_ttl"] = strconv.FormatUint(uint64(rec.TTL), 10)
+				continue
+			}
+			dc.Records = append(dc.Records, rec)
+		}
+		cfg.Domains = append(cfg.Domains, dc)
+	}
+	return cfg
+}
+
 // jsonQuoted returns a properly escaped JSON string (without quotes).
 func jsonQuoted(i string) string {
 	// https://stackoverflow.com/questions/51691901
@@ -329,6 +377,9 @@ func formatDsl(rec *models.RecordConfig, defaultTTL uint32) string {
 			cfproxy = ", CF_PROXY_ON"
 		}
 	}
+	if c := rec.Metadata[models.RecordComment]; c != "" {
+		ttlop += ", COMMENT(" + jsonQuoted(c) + ")"
+	}
 
 	switch rec.Type { // #rtype_variations
 	case "CAA":
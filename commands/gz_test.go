@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/StackExchange/dnscontrol/v4/pkg/credsfile"
+	"github.com/StackExchange/dnscontrol/v4/pkg/normalize"
 	_ "github.com/StackExchange/dnscontrol/v4/providers/_all"
 	"github.com/andreyvit/diff"
 )
@@ -16,6 +19,7 @@ func TestFormatTypes(t *testing.T) {
 	  test_data/$DOMAIN.zone   js              test_data/$DOMAIN.zone.js
 	  test_data/$DOMAIN.zone   tsv             test_data/$DOMAIN.zone.tsv
 	  test_data/$DOMAIN.zone   zone            test_data/$DOMAIN.zone.zone
+	  test_data/$DOMAIN.zone   json            test_data/$DOMAIN.zone.json
 	*/
 
 	for _, domain := range []string{"simple.com", "example.org", "apex.com", "ds.com"} {
@@ -23,6 +27,7 @@ func TestFormatTypes(t *testing.T) {
 		t.Run(domain+"/djs", func(t *testing.T) { testFormat(t, domain, "djs") })
 		t.Run(domain+"/tsv", func(t *testing.T) { testFormat(t, domain, "tsv") })
 		t.Run(domain+"/zone", func(t *testing.T) { testFormat(t, domain, "zone") })
+		t.Run(domain+"/json", func(t *testing.T) { testFormat(t, domain, "json") })
 	}
 }
 
@@ -75,3 +80,80 @@ func testFormat(t *testing.T, domain, format string) {
 		t.Errorf("testFormat mismatch (-got +want):\n%s", diff.LineDiff(g, w))
 	}
 }
+
+func TestRoundTrip(t *testing.T) {
+	// Importing a zone with --format=json and previewing the result
+	// must find nothing to change, and must keep the records' metadata.
+	tests := []struct {
+		domain, credName, providerName, credsFile string
+	}{
+		{"simple.com", "bind", "BIND", "test_data/bind-creds.json"},
+		{"example.org", "bind", "BIND", "test_data/bind-creds.json"},
+		{"ds.com", "bind", "BIND", "test_data/bind-creds.json"},
+		{"meta.com", "memory", "MEMORY", "test_data/memory-creds.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.providerName+"/"+tt.domain, func(t *testing.T) {
+			irFile := filepath.Join(t.TempDir(), "ir.json")
+			gzargs := GetZoneArgs{
+				ZoneNames:    []string{tt.domain},
+				OutputFormat: "json",
+				OutputFile:   irFile,
+				CredName:     tt.credName,
+				ProviderName: tt.providerName,
+			}
+			gzargs.CredsFile = tt.credsFile
+			if err := GetZone(gzargs); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := GetDNSConfig(GetDNSConfigArgs{JSONFile: irFile})
+			if err != nil {
+				t.Fatal(err)
+			}
+			providerConfigs, err := credsfile.LoadProviderConfigs(gzargs.CredsFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := PInitializeProviders(cfg, providerConfigs, false); err != nil {
+				t.Fatal(err)
+			}
+			if errs := normalize.ValidateAndNormalizeConfig(cfg); len(errs) != 0 {
+				t.Fatalf("validation errors: %v", errs)
+			}
+
+			dc := cfg.FindDomain(tt.domain)
+			provider := dc.DNSProviderInstances[0]
+			existing, err := provider.Driver.GetZoneRecords(tt.domain, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			delegation, _ := generateDelegationCorrections(dc, dc.DNSProviderInstances, dc.RegistrarInstance)
+			corrections, _, _ := generateZoneCorrections(dc, provider, nil)
+			for _, c := range append(delegation, corrections...) {
+				if c.F != nil {
+					t.Errorf("unexpected change: %s", c.Msg)
+				}
+			}
+
+			// Not every provider compares the metadata, so check it too.
+			for _, e := range existing {
+				found := false
+				for _, rec := range dc.Records {
+					if rec.Key() != e.Key() || rec.GetTargetCombined() != e.GetTargetCombined() {
+						continue
+					}
+					found = true
+					for k, v := range e.Metadata {
+						if rec.Metadata[k] != v {
+							t.Errorf("%s %s: metadata %s = %q, want %q", e.NameFQDN, e.Type, k, rec.Metadata[k], v)
+						}
+					}
+				}
+				if !found {
+					t.Errorf("%s %s %s was not imported", e.NameFQDN, e.Type, e.GetTargetCombined())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/StackExchange/dnscontrol/v4/pkg/credsfile"
+	"github.com/StackExchange/dnscontrol/v4/pkg/prettyzone"
+	"github.com/StackExchange/dnscontrol/v4/providers"
+	"github.com/urfave/cli/v2"
+)
+
+var _ = cmd(catUtils, func() *cli.Command {
+	var args ImportArgs
+	return &cli.Command{
+		Name:  "import",
+		Usage: "write every zone of a provider account as a dnsconfig.js tree (stand-alone)",
+		Action: func(ctx *cli.Context) error {
+			if ctx.NArg() != 1 {
+				return cli.Exit("Arguments should be: credkey (Ex: r53)", 1)
+			}
+			args.CredName = ctx.Args().First()
+			return exit(Import(args))
+		},
+		Flags:     args.flags(),
+		UsageText: "dnscontrol import [command options] credkey",
+		Description: `Download all the zones of a provider account and write them as a
+dnsconfig.js tree.  This is a stand-alone utility.
+
+The output directory gets a dnsconfig.js, a macros.js with the record
+sets that many zones have in common, and one file per zone in
+domains/.  Records that can't be expressed in dnsconfig.js are
+protected with IGNORE().
+
+ARGUMENTS:
+   credkey:  The name used in creds.json. The provider must be able to list its zones.
+
+EXAMPLES:
+   dnscontrol import --out=acquired cfmain
+   dnscontrol import --out=acquired --shared=5 r53`,
+	}
+}())
+
+// ImportArgs contains all data/flags needed to run import, independently of CLI.
+type ImportArgs struct {
+	GetCredentialsArgs        // Args related to creds.json
+	CredName           string // key in creds.json
+	OutputDir          string // Directory to write the tree to
+	MinShared          int    // A record set in this many zones becomes a macro
+}
+
+func (args *ImportArgs) flags() []cli.Flag {
+	flags := args.GetCredentialsArgs.flags()
+	flags = append(flags, &cli.StringFlag{
+		Name:        "out",
+		Destination: &args.OutputDir,
+		Required:    true,
+		Usage:       `Directory to write dnsconfig.js and the domains to`,
+	})
+	flags = append(flags, &cli.IntFlag{
+		Name:        "shared",
+		Destination: &args.MinShared,
+		Value:       2,
+		Usage:       `Move record sets found in at least this many zones into macros.js (0 disables)`,
+	})
+	return flags
+}
+
+// importable lists the rtypes that formatDsl turns into working
+// dnsconfig.js. Anything else is protected with IGNORE() instead.
+var importable = map[string]bool{
+	"A": true, "AAAA": true, "ALIAS": true, "CAA": true, "CNAME": true,
+	"DHCID": true, "DNAME": true, "DNSKEY": true, "DS": true, "HTTPS": true,
+	"MX": true, "NAPTR": true, "NS": true, "PTR": true, "R53_ALIAS": true,
+	"SRV": true, "SSHFP": true, "SVCB": true, "TLSA": true, "TXT": true,
+}
+
+// importRRSet is the records of one label and type of a zone.
+type importRRSet struct {
+	label, rtype string
+	key          string   // Identical record sets of different zones have the same key.
+	lines        []string // The records in dnsconfig.js, relative to the zone's DefaultTTL.
+	macroLines   []string // The records in dnsconfig.js, with explicit TTLs.
+}
+
+// importZone is a zone, ready to be written.
+type importZone struct {
+	name       string
+	defaultTTL uint32
+	sets       []*importRRSet
+	extra      []string // Commented-out records and IGNORE()s, each with its comma.
+}
+
+// Import contains all data/flags needed to run import, independently of CLI.
+func Import(args ImportArgs) error {
+	providerConfigs, err := credsfile.LoadProviderConfigs(args.CredsFile)
+	if err != nil {
+		return err
+	}
+	creds, ok := providerConfigs[args.CredName]
+	if !ok {
+		return fmt.Errorf("%q is not in %s", args.CredName, args.CredsFile)
+	}
+	provider, err := providers.CreateDNSProvider("-", creds, nil)
+	if err != nil {
+		return err
+	}
+	lister, ok := provider.(providers.ZoneLister)
+	if !ok {
+		return fmt.Errorf("provider %s cannot list its zones", args.CredName)
+	}
+	names, err := lister.ListZones()
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	topFile := filepath.Join(args.OutputDir, "dnsconfig.js")
+	if _, err := os.Stat(topFile); err == nil {
+		return fmt.Errorf("%s already exists", topFile)
+	}
+	if err := os.MkdirAll(filepath.Join(args.OutputDir, "domains"), 0o755); err != nil {
+		return err
+	}
+
+	zones := make([]*importZone, 0, len(names))
+	for _, name := range names {
+		fmt.Printf("Importing %s\n", name)
+		recs, err := provider.GetZoneRecords(name, nil)
+		if err != nil {
+			return fmt.Errorf("zone %s: %w", name, err)
+		}
+		zones = append(zones, newImportZone(name, recs))
+	}
+
+	dspVariableName := "DSP_" + strings.ToUpper(jsIdentifier(args.CredName))
+	macros, macroNames := findSharedRRSets(zones, args.MinShared)
+
+	var errs []error
+	for _, z := range zones {
+		errs = append(errs, writeImportZone(filepath.Join(args.OutputDir, "domains", z.name+".js"), z, dspVariableName, macroNames))
+	}
+	if len(macros) != 0 {
+		errs = append(errs, os.WriteFile(filepath.Join(args.OutputDir, "macros.js"), []byte(strings.Join(macros, "\n")), 0o644))
+	}
+
+	var top strings.Builder
+	fmt.Fprintf(&top, "// Imported from %q by \"dnscontrol import\".\n\n", args.CredName)
+	fmt.Fprintf(&top, "var REG_CHANGEME = NewRegistrar(\"none\");\n")
+	fmt.Fprintf(&top, "var %s = NewDnsProvider(%s);\n\n", dspVariableName, jsonQuoted(args.CredName))
+	if len(macros) != 0 {
+		fmt.Fprintf(&top, "require(\"./macros.js\");\n")
+	}
+	fmt.Fprintf(&top, "require_glob(\"./domains/\");\n")
+	errs = append(errs, os.WriteFile(topFile, []byte(top.String()), 0o644))
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote %d zone(s) and %d macro(s) to %s\n", len(zones), len(macros), args.OutputDir)
+	return nil
+}
+
+// newImportZone groups the records of a zone into record sets.
+func newImportZone(name string, recs models.Records) *importZone {
+	z := &importZone{name: name, defaultTTL: prettyzone.MostCommonTTL(recs)}
+	bySet := map[models.RecordKey]*importRRSet{}
+	ignored := map[string]bool{}
+	for _, rec := range recs {
+		label := rec.GetLabel()
+		switch {
+		case rec.Type == "SOA" || (rec.Type == "NS" && label == "@"):
+			// formatDsl comments these out.
+			z.extra = append(z.extra, formatDsl(rec, z.defaultTTL))
+			continue
+		case !importable[rec.Type]:
+			rtype := rec.Type
+			if rtype == "UNKNOWN" {
+				rtype = rec.UnknownTypeName
+			}
+			if !ignored[label+"/"+rtype] {
+				ignored[label+"/"+rtype] = true
+				z.extra = append(z.extra, fmt.Sprintf("IGNORE(%s, %s), // %s %s is not supported by the importer",
+					jsonQuoted(label), jsonQuoted(rtype), rtype, rec.GetTargetCombined()))
+			}
+			continue
+		}
+
+		k := rec.Key()
+		set, ok := bySet[k]
+		if !ok {
+			set = &importRRSet{label: label, rtype: rec.Type}
+			bySet[k] = set
+			z.sets = append(z.sets, set)
+		}
+		set.lines = append(set.lines, formatDsl(rec, z.defaultTTL))
+		set.macroLines = append(set.macroLines, formatDsl(rec, 0))
+	}
+	for _, set := range z.sets {
+		sort.Strings(set.macroLines)
+		set.key = strings.Join(set.macroLines, "\n")
+	}
+	return z
+}
+
+// findSharedRRSets returns the definitions of the macros for the record
+// sets that are in at least minShared zones, and the macro name of each
+// of those record sets, by key.
+func findSharedRRSets(zones []*importZone, minShared int) ([]string, map[string]string) {
+	names := map[string]string{}
+	if minShared <= 0 {
+		return nil, names
+	}
+
+	count := map[string]int{}
+	var order []*importRRSet
+	for _, z := range zones {
+		for _, set := range z.sets {
+			if count[set.key] == 0 {
+				order = append(order, set)
+			}
+			count[set.key]++
+		}
+	}
+
+	taken := map[string]bool{}
+	var macros []string
+	for _, set := range order {
+		if count[set.key] < minShared {
+			continue
+		}
+		label := "APEX"
+		if set.label != "@" {
+			label = strings.ToUpper(jsIdentifier(set.label))
+		}
+		base := set.rtype + "_" + label
+		name := base
+		for i := 2; taken[name]; i++ {
+			name = fmt.Sprintf("%s_%d", base, i)
+		}
+		taken[name] = true
+		names[set.key] = name
+		macros = append(macros, fmt.Sprintf("// Used by %d zones.\nvar %s = [\n\t%s,\n];\n", count[set.key], name, strings.Join(set.macroLines, ",\n\t")))
+	}
+	return macros, names
+}
+
+// writeImportZone writes the D() of one zone.
+func writeImportZone(filename string, z *importZone, dspVariableName string, macroNames map[string]string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "D(%s, REG_CHANGEME,\n", jsonQuoted(z.name))
+	fmt.Fprintf(&b, "\tDnsProvider(%s),\n", dspVariableName)
+	if z.defaultTTL != models.DefaultTTL && z.defaultTTL != 0 {
+		fmt.Fprintf(&b, "\tDefaultTTL(%d),\n", z.defaultTTL)
+	}
+	for _, set := range z.sets {
+		if name, ok := macroNames[set.key]; ok {
+			fmt.Fprintf(&b, "\t%s,\n", name)
+			continue
+		}
+		if set.rtype == "CNAME" && set.label == "@" {
+			fmt.Fprintf(&b, "\t// NOTE: CNAME at apex may require manual editing.\n")
+		}
+		for _, line := range set.lines {
+			fmt.Fprintf(&b, "\t%s,\n", line)
+		}
+	}
+	for _, line := range z.extra {
+		// These are comments, or end in one.
+		fmt.Fprintf(&b, "\t%s\n", line)
+	}
+	fmt.Fprintf(&b, "END);\n")
+	return os.WriteFile(filename, []byte(b.String()), 0o644)
+}
+
+// jsIdentifier replaces the characters that can't be in a JavaScript
+// variable name with "_".
+func jsIdentifier(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, s)
+}
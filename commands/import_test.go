@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+)
+
+func TestImport(t *testing.T) {
+	dir := t.TempDir()
+	mkRec := func(zone, label, rtype, target string, ttl uint32) *models.RecordConfig {
+		r := &models.RecordConfig{Type: rtype, TTL: ttl}
+		r.SetLabel(label, zone)
+		if err := r.PopulateFromString(rtype, target, zone); err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	zones := map[string]models.Records{}
+	for _, zone := range []string{"a.com", "b.com", "c.com"} {
+		zones[zone] = models.Records{
+			mkRec(zone, "@", "MX", "10 aspmx.l.google.com.", 3600),
+			mkRec(zone, "@", "MX", "20 alt1.aspmx.l.google.com.", 3600),
+			mkRec(zone, "www", "A", "192.0.2.1", 300),
+		}
+	}
+	// Only in one zone, so it stays there.
+	zones["a.com"] = append(zones["a.com"], mkRec("a.com", "@", "TXT", "only in a", 300))
+	// Not supported by the importer.
+	zones["b.com"] = append(zones["b.com"], mkRec("b.com", "geo", "LOC", "52 22 23.000 N 4 53 32.000 E -2.00m 0.00m 10000m 10m", 300))
+
+	storeFile := filepath.Join(dir, "zones.json")
+	credsFile := filepath.Join(dir, "creds.json")
+	content, err := json.Marshal(zones)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(storeFile, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	content, err = json.Marshal(map[string]map[string]string{"mem": {"TYPE": "MEMORY", "filename": storeFile}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(credsFile, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "out")
+	args := ImportArgs{CredName: "mem", OutputDir: out, MinShared: 2}
+	args.CredsFile = credsFile
+	if err := Import(args); err != nil {
+		t.Fatal(err)
+	}
+
+	macros, err := os.ReadFile(filepath.Join(out, "macros.js"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"var MX_APEX = [", "var A_WWW = ["} {
+		if !strings.Contains(string(macros), want) {
+			t.Errorf("macros.js does not contain %q:\n%s", want, macros)
+		}
+	}
+	b, err := os.ReadFile(filepath.Join(out, "domains", "b.com.js"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `IGNORE("geo", "LOC")`) {
+		t.Errorf("b.com.js does not IGNORE() the LOC record:\n%s", b)
+	}
+
+	// The tree must be valid dnsconfig.js.
+	cfg, err := ExecuteDSL(ExecuteDSLArgs{JSFile: filepath.Join(out, "dnsconfig.js")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Domains) != 3 {
+		t.Fatalf("got %d domains, want 3", len(cfg.Domains))
+	}
+	for _, dc := range cfg.Domains {
+		want := 3
+		if dc.Name == "a.com" {
+			want = 4
+		}
+		if len(dc.Records) != want {
+			t.Errorf("%s has %d records, want %d", dc.Name, len(dc.Records), want)
+		}
+	}
+
+	// Never overwrite an earlier import.
+	if err := Import(args); err == nil {
+		t.Errorf("expected an error when dnsconfig.js exists")
+	}
+}
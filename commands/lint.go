@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v4/pkg/js"
+	"github.com/StackExchange/dnscontrol/v4/pkg/jslint"
+	"github.com/urfave/cli/v2"
+)
+
+var _ = cmd(catUtils, func() *cli.Command {
+	var args LintArgs
+	return &cli.Command{
+		Name:  "lint",
+		Usage: "check dnsconfig.js for common mistakes (stand-alone)",
+		Action: func(ctx *cli.Context) error {
+			return exit(Lint(args))
+		},
+		Flags: args.flags(),
+	}
+}())
+
+// LintArgs contains all data/flags needed to run lint, independently of CLI.
+type LintArgs struct {
+	ExecuteDSLArgs
+	Fix bool
+}
+
+func (args *LintArgs) flags() []cli.Flag {
+	flags := args.ExecuteDSLArgs.flags()
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "fix",
+		Destination: &args.Fix,
+		Usage:       `Fix the problems that can be fixed safely`,
+	})
+	return flags
+}
+
+// Lint checks dnsconfig.js (and the files it requires) without
+// running it, and optionally fixes what it finds.
+func Lint(args LintArgs) error {
+	switch strings.ToLower(filepath.Ext(args.JSFile)) {
+	case ".yaml", ".yml":
+		return errors.New("lint only checks JavaScript configurations")
+	}
+
+	vars, err := args.variables()
+	if err != nil {
+		return err
+	}
+	var variables []string
+	for name := range vars {
+		variables = append(variables, name)
+	}
+	helpers := js.GetHelpers(args.DevMode)
+
+	findings, err := jslint.Lint(args.JSFile, helpers, variables)
+	if err != nil {
+		return err
+	}
+	if args.Fix {
+		n, err := jslint.ApplyFixes(findings)
+		if err != nil {
+			return err
+		}
+		if n != 0 {
+			fmt.Printf("Fixed %d problem(s)\n", n)
+			// Report what is left.
+			if findings, err = jslint.Lint(args.JSFile, helpers, variables); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, f := range findings {
+		fmt.Println(f)
+	}
+	if len(findings) != 0 {
+		return fmt.Errorf("%d problem(s) found", len(findings))
+	}
+	return nil
+}
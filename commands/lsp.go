@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/StackExchange/dnscontrol/v4/pkg/lsp"
+	"github.com/StackExchange/dnscontrol/v4/pkg/normalize"
+	"github.com/StackExchange/dnscontrol/v4/pkg/printer"
+	"github.com/robertkrimen/otto"
+	"github.com/urfave/cli/v2"
+)
+
+var _ = cmd(catUtils, func() *cli.Command {
+	var args LSPArgs
+	return &cli.Command{
+		Name:  "lsp",
+		Usage: "[BETA] Run a Language Server Protocol server for editors (on stdin/stdout)",
+		Action: func(ctx *cli.Context) error {
+			return exit(LSP(args))
+		},
+		Flags: args.flags(),
+	}
+}())
+
+// LSPArgs contains all data/flags needed to run the language server,
+// independently of CLI.
+type LSPArgs struct {
+	ExecuteDSLArgs
+}
+
+func (args *LSPArgs) flags() []cli.Flag {
+	return args.ExecuteDSLArgs.flags()
+}
+
+// LSP serves the Language Server Protocol on stdin and stdout until
+// the editor stops it.
+func LSP(args LSPArgs) error {
+	// stdout belongs to the protocol. Anything else that would be
+	// printed there (console.log() in dnsconfig.js, warnings, ...)
+	// goes to stderr, which editors show in their log.
+	out := os.Stdout
+	os.Stdout = os.Stderr
+	printer.DefaultPrinter.Writer = os.Stderr
+
+	docs := lsp.ParseDocs(dtsContent)
+	return lsp.NewServer(os.Stdin, out, args.check, docs).Run()
+}
+
+// check runs the configuration, whichever of its files was saved, and
+// validates it like "dnscontrol check" does.
+func (args LSPArgs) check(string) []lsp.Diagnostic {
+	file, err := filepath.Abs(args.JSFile)
+	if err != nil {
+		return []lsp.Diagnostic{{Msg: err.Error()}}
+	}
+	args.JSFile = file
+
+	cfg, err := ExecuteDSL(args.ExecuteDSLArgs)
+	if err != nil {
+		text := err.Error()
+		// The stack trace has the position of JavaScript errors.
+		var oe *otto.Error
+		if errors.As(err, &oe) {
+			text = oe.String()
+		}
+		return []lsp.Diagnostic{lsp.ParseError(file, text, false, "helpers.js")}
+	}
+
+	var diags []lsp.Diagnostic
+	for _, err := range normalize.ValidateAndNormalizeConfig(cfg) {
+		_, warning := err.(normalize.Warning)
+		diags = append(diags, lsp.ParseError(file, err.Error(), warning))
+	}
+	return diags
+}
@@ -0,0 +1,582 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/StackExchange/dnscontrol/v4/pkg/credsfile"
+	"github.com/StackExchange/dnscontrol/v4/pkg/diff2"
+	"github.com/StackExchange/dnscontrol/v4/pkg/normalize"
+	"github.com/StackExchange/dnscontrol/v4/pkg/zonerecs"
+	"github.com/StackExchange/dnscontrol/v4/providers"
+	"github.com/urfave/cli/v2"
+)
+
+var _ = cmd(catUtils, func() *cli.Command {
+	var args MigrateArgs
+	return &cli.Command{
+		Name:  "migrate",
+		Usage: "move a domain from one DNS provider to another, step by step",
+		Action: func(ctx *cli.Context) error {
+			if args.Domain == "" || args.From == "" || args.To == "" {
+				return cli.Exit("--domain, --from and --to are required", 1)
+			}
+			return exit(Migrate(args))
+		},
+		Flags: args.flags(),
+		Description: `Move a domain from one DNS provider to another.
+
+Each run performs as many steps as possible, then stops and explains
+why (usually: caches must expire first). Progress is kept in a state
+file so that the command can simply be re-run until the migration is
+complete. Don't push the domain until then, and afterwards update
+dnsconfig.js to use the new provider.
+
+STEPS:
+   copy       Copy the records from the old provider to the new one.
+   verify     Verify that both providers serve the same records.
+   lower-ttl  Lower the NS TTL to --ns-ttl.
+   dual-host  Add the new provider next to the old one; delegate to both.
+   switch     Delegate to the new provider only.
+   remove     Stop updating the old provider.
+
+EXAMPLES:
+   dnscontrol migrate --domain example.com --from r53 --to gcloud`,
+	}
+}())
+
+// MigrateArgs contains all data/flags needed to run migrate, independently of CLI.
+type MigrateArgs struct {
+	GetDNSConfigArgs
+	GetCredentialsArgs
+	Domain    string
+	From      string
+	To        string
+	StateFile string
+	NSTTL     time.Duration
+	Drain     time.Duration
+}
+
+func (args *MigrateArgs) flags() []cli.Flag {
+	flags := args.GetDNSConfigArgs.flags()
+	flags = append(flags, args.GetCredentialsArgs.flags()...)
+	flags = append(flags, &cli.StringFlag{
+		Name:        "domain",
+		Destination: &args.Domain,
+		Usage:       `The domain to migrate`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "from",
+		Destination: &args.From,
+		Usage:       `The current DNS provider (the name used in creds.json)`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "to",
+		Destination: &args.To,
+		Usage:       `The new DNS provider (the name used in creds.json)`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "state",
+		Destination: &args.StateFile,
+		Usage:       `File that records the progress (default: dnscontrol-migrate-DOMAIN.json)`,
+	})
+	flags = append(flags, &cli.DurationFlag{
+		Name:        "ns-ttl",
+		Destination: &args.NSTTL,
+		Value:       5 * time.Minute,
+		Usage:       `The highest NS TTL accepted by the lower-ttl step`,
+	})
+	flags = append(flags, &cli.DurationFlag{
+		Name:        "drain",
+		Destination: &args.Drain,
+		Value:       48 * time.Hour,
+		Usage:       `How long to keep the old provider after the delegation is switched (parent zones cache NS records)`,
+	})
+	return flags
+}
+
+// migrateState is the progress of a migration. It is stored as JSON
+// between runs.
+type migrateState struct {
+	Domain    string    `json:"domain"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Completed []string  `json:"completed"`
+	OldNSTTL  uint32    `json:"old_ns_ttl,omitempty"` // The NS TTL before the migration started.
+	WaitUntil time.Time `json:"wait_until,omitempty"` // Don't start the next step before this time.
+}
+
+// migration is everything a migration step needs.
+type migration struct {
+	args     MigrateArgs
+	state    *migrateState
+	from, to providers.DNSServiceProvider
+	toType   string
+
+	cfg *models.DNSConfig // Loaded on demand by the steps that need it.
+}
+
+type migrateStep struct {
+	name string
+	desc string
+	// run performs the step. It returns false if the step can't be
+	// completed yet (after explaining why).
+	run func(m *migration) (bool, error)
+}
+
+var migrateSteps = []migrateStep{
+	{"copy", "copy the records to the new provider", (*migration).stepCopy},
+	{"verify", "verify that both providers serve the same records", (*migration).stepVerify},
+	{"lower-ttl", "lower the NS TTL", (*migration).stepLowerTTL},
+	{"dual-host", "serve the domain from both providers", (*migration).stepDualHost},
+	{"switch", "delegate to the new provider only", (*migration).stepSwitch},
+	{"remove", "remove the old provider", (*migration).stepRemove},
+}
+
+// Migrate implements the migrate subcommand.
+func Migrate(args MigrateArgs) error {
+	if args.StateFile == "" {
+		args.StateFile = "dnscontrol-migrate-" + args.Domain + ".json"
+	}
+	state, err := loadMigrateState(args.StateFile, args.Domain, args.From, args.To)
+	if err != nil {
+		return err
+	}
+
+	providerConfigs, err := credsfile.LoadProviderConfigs(args.CredsFile)
+	if err != nil {
+		return err
+	}
+	m := &migration{args: args, state: state}
+	for _, p := range []struct {
+		name string
+		dsp  *providers.DNSServiceProvider
+	}{{args.From, &m.from}, {args.To, &m.to}} {
+		pcfg, ok := providerConfigs[p.name]
+		if !ok {
+			return fmt.Errorf("%q is not in %s", p.name, args.CredsFile)
+		}
+		*p.dsp, err = providers.CreateDNSProvider("-", pcfg, nil)
+		if err != nil {
+			return fmt.Errorf("provider %s: %w", p.name, err)
+		}
+	}
+	m.toType = providerConfigs[args.To]["TYPE"]
+	if m.toType == "" || m.toType == "-" {
+		return fmt.Errorf("%s has no TYPE in %s", args.To, args.CredsFile)
+	}
+
+	return m.run(migrateSteps)
+}
+
+func (m *migration) run(steps []migrateStep) error {
+	for _, st := range steps {
+		if slices.Contains(m.state.Completed, st.name) {
+			fmt.Printf("[done] %s: %s\n", st.name, st.desc)
+			continue
+		}
+		fmt.Printf("[....] %s: %s\n", st.name, st.desc)
+		ok, err := st.run(m)
+		if err != nil {
+			return fmt.Errorf("step %s: %w", st.name, err)
+		}
+		if !ok {
+			fmt.Printf("Re-run this command to continue. Progress is saved in %s\n", m.args.StateFile)
+			return nil
+		}
+		m.state.Completed = append(m.state.Completed, st.name)
+		if err := m.state.save(m.args.StateFile); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Migration of %s from %s to %s is complete. The zone still exists at %s; delete it there when you are ready.\n",
+		m.state.Domain, m.state.From, m.state.To, m.state.From)
+	fmt.Printf("In D(%q) in dnsconfig.js, replace DnsProvider(%q) with DnsProvider(%q) and set NAMESERVER_TTL() before the next push.\n",
+		m.state.Domain, m.state.From, m.state.To)
+	return nil
+}
+
+func loadMigrateState(filename, domain, from, to string) (*migrateState, error) {
+	state := &migrateState{Domain: domain, From: from, To: to}
+	content, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, fmt.Errorf("can't parse %s: %w", filename, err)
+	}
+	if state.Domain != domain || state.From != from || state.To != to {
+		return nil, fmt.Errorf("%s is for migrating %s from %s to %s. Use a different --state file", filename, state.Domain, state.From, state.To)
+	}
+	return state, nil
+}
+
+func (s *migrateState) save(filename string) error {
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, content, 0o644)
+}
+
+// isApexNSorSOA returns true for the records that each provider manages
+// for itself.
+func isApexNSorSOA(rec *models.RecordConfig) bool {
+	return rec.Type == "SOA" || (rec.Type == "NS" && rec.GetLabel() == "@")
+}
+
+// fetch returns the records of the domain at a provider, ready to compare.
+func (m *migration) fetch(p providers.DNSServiceProvider) (models.Records, error) {
+	recs, err := p.GetZoneRecords(m.state.Domain, map[string]string{})
+	if err != nil {
+		return nil, err
+	}
+	models.Downcase(recs)
+	models.CanonicalizeTargets(recs, m.state.Domain)
+	return recs, nil
+}
+
+func (m *migration) stepCopy() (bool, error) {
+	old, err := m.fetch(m.from)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", m.state.From, err)
+	}
+
+	if creator, ok := m.to.(providers.ZoneCreator); ok {
+		if err := creator.EnsureZoneExists(m.state.Domain); err != nil {
+			return false, fmt.Errorf("%s: %w", m.state.To, err)
+		}
+	}
+	current, err := m.fetch(m.to)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", m.state.To, err)
+	}
+
+	// Everything from the old provider, except that the new provider
+	// keeps its own SOA and apex NS records.
+	var desired models.Records
+	for _, rec := range old {
+		if isApexNSorSOA(rec) {
+			if rec.Type == "NS" && rec.TTL > m.state.OldNSTTL {
+				m.state.OldNSTTL = rec.TTL
+			}
+			continue
+		}
+		desired = append(desired, rec)
+	}
+	for _, rec := range current {
+		if isApexNSorSOA(rec) {
+			desired = append(desired, rec)
+		}
+	}
+	dc := &models.DomainConfig{
+		Name:                 m.state.Domain,
+		Records:              desired,
+		Metadata:             map[string]string{},
+		DNSProviderInstances: []*models.DNSProviderInstance{m.toInstance()},
+	}
+	if err := normalize.CheckProviderCapabilities(dc); err != nil {
+		return false, fmt.Errorf("%s can't hold the records of %s: %w", m.state.To, m.state.Domain, err)
+	}
+	if es := providers.AuditRecords(m.toType, desired); len(es) != 0 {
+		for _, e := range es {
+			fmt.Printf("ERROR: %s\n", e)
+		}
+		return false, fmt.Errorf("%s can't hold the records of %s", m.state.To, m.state.Domain)
+	}
+
+	_, corrections, _, err := zonerecs.CorrectZoneRecords(m.to, dc)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range corrections {
+		fmt.Println(c.Msg)
+		if err := c.F(); err != nil {
+			return false, err
+		}
+	}
+	fmt.Printf("Copied %d records (%d corrections).\n", len(desired), len(corrections))
+	return true, nil
+}
+
+func (m *migration) stepVerify() (bool, error) {
+	var zones [2]models.Records
+	for i, p := range []providers.DNSServiceProvider{m.from, m.to} {
+		recs, err := m.fetch(p)
+		if err != nil {
+			return false, err
+		}
+		for _, rec := range recs {
+			if !isApexNSorSOA(rec) {
+				zones[i] = append(zones[i], rec)
+			}
+		}
+	}
+	msgs := diff2.Compare(m.state.Domain, zones[0], zones[1], nil)
+	if len(msgs) != 0 {
+		fmt.Printf("%s and %s differ. Changes that would turn %s's zone into %s's:\n", m.state.From, m.state.To, m.state.From, m.state.To)
+		for _, msg := range msgs {
+			fmt.Println(msg)
+		}
+		return false, fmt.Errorf("%d difference(s); make both providers identical (perhaps in dnsconfig.js) then re-run", len(msgs))
+	}
+	fmt.Printf("Both providers serve the same %d records.\n", len(zones[0]))
+	return true, nil
+}
+
+func (m *migration) stepLowerTTL() (bool, error) {
+	dc, err := m.domain()
+	if err != nil {
+		return false, err
+	}
+	// The migration shouldn't change anything but the delegation.
+	if ok, err := m.inSync(dc); !ok || err != nil {
+		return false, err
+	}
+	if err := m.applyStage(dc, stageLowTTL); err != nil {
+		return false, err
+	}
+	// Resolvers may cache the old NS records for the old TTL.
+	m.state.WaitUntil = time.Now().Add(time.Duration(m.state.OldNSTTL) * time.Second)
+	return true, nil
+}
+
+func (m *migration) stepDualHost() (bool, error) {
+	if !m.waited() {
+		return false, nil
+	}
+	dc, err := m.domain()
+	if err != nil {
+		return false, err
+	}
+	if err := m.applyStage(dc, stageDualHost); err != nil {
+		return false, err
+	}
+	// Now that both are updated from dnsconfig.js, check parity again.
+	return m.stepVerify()
+}
+
+func (m *migration) stepSwitch() (bool, error) {
+	dc, err := m.domain()
+	if err != nil {
+		return false, err
+	}
+	if err := m.applyStage(dc, stageSwitched); err != nil {
+		return false, err
+	}
+	// The parent zone caches the delegation for a long time.
+	m.state.WaitUntil = time.Now().Add(m.args.Drain)
+	return true, nil
+}
+
+func (m *migration) stepRemove() (bool, error) {
+	if !m.waited() {
+		return false, nil
+	}
+	dc, err := m.domain()
+	if err != nil {
+		return false, err
+	}
+	return true, m.applyStage(dc, stageMoved)
+}
+
+// waited returns true if the wait started by a previous step is over.
+func (m *migration) waited() bool {
+	if left := time.Until(m.state.WaitUntil); left > 0 {
+		fmt.Printf("Waiting for caches to expire. Try again after %s (in %s).\n", m.state.WaitUntil.Format(time.RFC3339), left.Round(time.Second))
+		return false
+	}
+	return true
+}
+
+// domain returns the domain as configured in dnsconfig.js.
+func (m *migration) domain() (*models.DomainConfig, error) {
+	if m.cfg == nil {
+		cfg, err := GetDNSConfig(m.args.GetDNSConfigArgs)
+		if err != nil {
+			return nil, err
+		}
+		providerConfigs, err := credsfile.LoadProviderConfigs(m.args.CredsFile)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := PInitializeProviders(cfg, providerConfigs, false); err != nil {
+			return nil, err
+		}
+		if PrintValidationErrors(normalize.ValidateAndNormalizeConfig(cfg)) {
+			return nil, errors.New("exiting due to validation errors")
+		}
+		m.cfg = cfg
+	}
+	dc := m.cfg.FindDomain(m.state.Domain)
+	if dc == nil {
+		return nil, fmt.Errorf("%s is not in dnsconfig.js", m.state.Domain)
+	}
+	return dc, nil
+}
+
+// The stages of a migration, as the domain is served in each.
+const (
+	stageLowTTL   = iota // The old provider, with a low NS TTL.
+	stageDualHost        // Both providers.
+	stageSwitched        // Both providers, but only the new one is delegated.
+	stageMoved           // The new provider.
+)
+
+// toInstance returns the new provider as a provider of a domain.
+func (m *migration) toInstance() *models.DNSProviderInstance {
+	return &models.DNSProviderInstance{
+		ProviderBase:        models.ProviderBase{Name: m.state.To, ProviderType: m.toType},
+		Driver:              m.to,
+		NumberOfNameservers: -1, // All of them.
+	}
+}
+
+// stage returns a copy of the domain of dnsconfig.js, as it is served
+// at the given stage of the migration. Other providers of the domain
+// are kept as they are.
+func (m *migration) stage(dc *models.DomainConfig, stage int) (*models.DomainConfig, error) {
+	var insts []*models.DNSProviderInstance
+	found := false
+	for _, p := range dc.DNSProviderInstances {
+		switch p.Name {
+		case m.state.To:
+			// Added below.
+		case m.state.From:
+			found = true
+			if stage == stageMoved {
+				continue
+			}
+			from := *p
+			if stage == stageSwitched {
+				from.NumberOfNameservers = 0
+			}
+			insts = append(insts, &from)
+		default:
+			insts = append(insts, p)
+		}
+	}
+	if !found && stage != stageMoved {
+		return nil, fmt.Errorf("dnsconfig.js doesn't use %s for %s", m.state.From, m.state.Domain)
+	}
+	if stage != stageLowTTL {
+		insts = append(insts, m.toInstance())
+	}
+
+	recs, err := dc.Records.Copy()
+	if err != nil {
+		return nil, err
+	}
+	s := copyDomain(dc, recs, insts)
+	limit := uint64(m.args.NSTTL.Seconds())
+	if ttl, err := strconv.ParseUint(s.Metadata["ns_ttl"], 10, 32); err != nil || ttl > limit {
+		s.Metadata["ns_ttl"] = strconv.FormatUint(limit, 10)
+	}
+	return s, nil
+}
+
+// copyDomain returns a copy of dc with other records and providers,
+// that can be pushed without changing dc.
+func copyDomain(dc *models.DomainConfig, recs models.Records, insts []*models.DNSProviderInstance) *models.DomainConfig {
+	names := map[string]int{}
+	for _, p := range insts {
+		names[p.Name] = p.NumberOfNameservers
+	}
+	meta := maps.Clone(dc.Metadata)
+	if meta == nil {
+		meta = map[string]string{}
+	}
+	return &models.DomainConfig{
+		Name:                 dc.Name,
+		RegistrarName:        dc.RegistrarName,
+		DNSProviderNames:     names,
+		Metadata:             meta,
+		Records:              recs,
+		Nameservers:          slices.Clone(dc.Nameservers),
+		EnsureAbsent:         dc.EnsureAbsent,
+		KeepUnknown:          dc.KeepUnknown,
+		Unmanaged:            dc.Unmanaged,
+		UnmanagedUnsafe:      dc.UnmanagedUnsafe,
+		AutoDNSSEC:           dc.AutoDNSSEC,
+		Downgrades:           dc.Downgrades,
+		AutoPTR:              dc.AutoPTR,
+		RegistrarInstance:    dc.RegistrarInstance,
+		DNSProviderInstances: insts,
+	}
+}
+
+// corrections returns what "dnscontrol push" would do for dc: the
+// changes to the zones, and then to the delegation. Like push, it adds
+// the apex NS records to dc.
+func corrections(dc *models.DomainConfig) (zone, delegation []*models.Correction, err error) {
+	delegation, _ = generateDelegationCorrections(dc, dc.DNSProviderInstances, dc.RegistrarInstance)
+	for _, p := range dc.DNSProviderInstances {
+		cs, _, _ := generateZoneCorrections(dc, p, nil)
+		for _, c := range cs {
+			if c.F == nil {
+				return nil, nil, errors.New(c.Msg)
+			}
+		}
+		zone = append(zone, cs...)
+	}
+	return zone, delegation, nil
+}
+
+// applyStage makes the providers and the registrar serve the domain as
+// it is at the given stage of the migration.
+func (m *migration) applyStage(dc *models.DomainConfig, stage int) error {
+	s, err := m.stage(dc, stage)
+	if err != nil {
+		return err
+	}
+	zone, delegation, err := corrections(s)
+	if err != nil {
+		return err
+	}
+	for _, c := range append(zone, delegation...) {
+		fmt.Println(c.Msg)
+		if c.F == nil {
+			continue
+		}
+		if err := c.F(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inSync returns true if "dnscontrol push" has nothing to do for the
+// domain: neither its DNS providers nor its registrar.
+func (m *migration) inSync(dc *models.DomainConfig) (bool, error) {
+	recs, err := dc.Records.Copy()
+	if err != nil {
+		return false, err
+	}
+	zone, delegation, err := corrections(copyDomain(dc, recs, dc.DNSProviderInstances))
+	if err != nil {
+		return false, err
+	}
+	n := len(zone)
+	for _, c := range delegation {
+		if c.F == nil {
+			fmt.Println(c.Msg)
+			continue
+		}
+		n++
+	}
+	if n != 0 {
+		fmt.Printf("%s has %d pending change(s). Run \"dnscontrol push\" first.\n", m.state.Domain, n)
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,238 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/StackExchange/dnscontrol/v4/pkg/zonerecs"
+	"github.com/StackExchange/dnscontrol/v4/providers"
+)
+
+func Test_migrateState(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state.json")
+
+	state, err := loadMigrateState(filename, "example.com", "old", "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Completed) != 0 {
+		t.Errorf("new state has completed steps: %v", state.Completed)
+	}
+	state.Completed = append(state.Completed, "copy")
+	state.OldNSTTL = 86400
+	if err := state.save(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err = loadMigrateState(filename, "example.com", "old", "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Completed) != 1 || state.Completed[0] != "copy" || state.OldNSTTL != 86400 {
+		t.Errorf("loaded state = %+v", state)
+	}
+
+	// The state of one migration can't be used for another.
+	if _, err := loadMigrateState(filename, "example.com", "old", "other"); err == nil {
+		t.Errorf("expected an error for a different migration")
+	}
+}
+
+func Test_migrateCopyVerify(t *testing.T) {
+	mkRec := func(label, rtype, target string, ttl uint32) *models.RecordConfig {
+		r := &models.RecordConfig{Type: rtype, TTL: ttl}
+		r.SetLabel(label, "example.com")
+		if err := r.SetTarget(target); err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	from, err := providers.CreateDNSProvider("MEMORY", map[string]string{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := providers.CreateDNSProvider("MEMORY", map[string]string{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dc := &models.DomainConfig{Name: "example.com", Metadata: map[string]string{}, Records: models.Records{
+		mkRec("@", "A", "1.2.3.4", 300),
+		mkRec("@", "NS", "ns1.old.example.net.", 86400),
+		mkRec("www", "CNAME", "example.com.", 300),
+	}}
+	_, corrections, _, err := zonerecs.CorrectZoneRecords(from, dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range corrections {
+		if err := c.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := &migration{
+		state:  &migrateState{Domain: "example.com", From: "old", To: "new"},
+		from:   from,
+		to:     to,
+		toType: "MEMORY",
+	}
+
+	// Before the copy, the providers differ.
+	if ok, err := m.stepVerify(); ok || err == nil {
+		t.Errorf("stepVerify() before copy = %v, %v; want false, error", ok, err)
+	}
+
+	if ok, err := m.stepCopy(); !ok || err != nil {
+		t.Fatalf("stepCopy() = %v, %v", ok, err)
+	}
+	if m.state.OldNSTTL != 86400 {
+		t.Errorf("OldNSTTL = %d, want 86400", m.state.OldNSTTL)
+	}
+	if ok, err := m.stepVerify(); !ok || err != nil {
+		t.Errorf("stepVerify() after copy = %v, %v", ok, err)
+	}
+
+	// The apex NS records stay behind.
+	recs, err := to.GetZoneRecords("example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range recs {
+		if rec.Type == "NS" {
+			t.Errorf("apex NS copied to the new provider: %s", rec.GetTargetField())
+		}
+	}
+}
+
+func Test_migrateCopyCapabilities(t *testing.T) {
+	from, err := providers.CreateDNSProvider("MEMORY", map[string]string{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := providers.CreateDNSProvider("MEMORY", map[string]string{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &models.RecordConfig{Type: "PTR", TTL: 300}
+	r.SetLabel("host", "example.com")
+	r.MustSetTarget("www.example.com.")
+	_, corrections, _, err := zonerecs.CorrectZoneRecords(from, &models.DomainConfig{Name: "example.com", Records: models.Records{r}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range corrections {
+		if err := c.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := &migration{
+		state:  &migrateState{Domain: "example.com", From: "old", To: "new"},
+		from:   from,
+		to:     to,
+		toType: "HETZNER", // Can't do PTR records.
+	}
+	if ok, err := m.stepCopy(); ok || err == nil {
+		t.Errorf("stepCopy() = %v, %v; want false, error", ok, err)
+	}
+	if recs, _ := to.GetZoneRecords("example.com", nil); len(recs) != 0 {
+		t.Errorf("records copied to a provider that can't hold them: %v", recs)
+	}
+}
+
+func Test_migrateSteps(t *testing.T) {
+	newProvider := func(ns string) providers.DNSServiceProvider {
+		p, err := providers.CreateDNSProvider("MEMORY", map[string]string{"nameservers": ns}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	from := newProvider("ns1.old.example.net")
+	to := newProvider("ns1.new.example.net")
+	registrar, err := providers.CreateRegistrar("NONE", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The domain as in dnsconfig.js, pushed to the old provider.
+	a := &models.RecordConfig{Type: "A", TTL: 300}
+	a.SetLabel("@", "example.com")
+	a.MustSetTarget("1.2.3.4")
+	dc := &models.DomainConfig{
+		Name:                 "example.com",
+		Metadata:             map[string]string{"ns_ttl": "86400"},
+		Records:              models.Records{a},
+		DNSProviderNames:     map[string]int{"old": -1},
+		RegistrarInstance:    &models.RegistrarInstance{ProviderBase: models.ProviderBase{Name: "none", ProviderType: "NONE"}, Driver: registrar},
+		DNSProviderInstances: []*models.DNSProviderInstance{{ProviderBase: models.ProviderBase{Name: "old", ProviderType: "MEMORY"}, Driver: from, NumberOfNameservers: -1}},
+	}
+	zone, _, err := corrections(copyDomain(dc, models.Records{a}, dc.DNSProviderInstances))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range zone {
+		if err := c.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := &migration{
+		args:   MigrateArgs{NSTTL: 5 * time.Minute},
+		state:  &migrateState{Domain: "example.com", From: "old", To: "new"},
+		from:   from,
+		to:     to,
+		toType: "MEMORY",
+		cfg:    &models.DNSConfig{Domains: []*models.DomainConfig{dc}},
+	}
+	// The apex NS records of a provider, as "TARGET TTL".
+	apexNS := func(p providers.DNSServiceProvider) string {
+		recs, err := p.GetZoneRecords("example.com", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ns []string
+		for _, rec := range recs {
+			if rec.Type == "NS" {
+				ns = append(ns, fmt.Sprintf("%s %d", rec.GetTargetField(), rec.TTL))
+			}
+		}
+		slices.Sort(ns)
+		return strings.Join(ns, ", ")
+	}
+
+	tests := []struct {
+		step     func() (bool, error)
+		from, to string
+	}{
+		{m.stepCopy, "ns1.old.example.net. 86400", ""},
+		{m.stepVerify, "ns1.old.example.net. 86400", ""},
+		{m.stepLowerTTL, "ns1.old.example.net. 300", ""},
+		{m.stepDualHost, "ns1.new.example.net. 300, ns1.old.example.net. 300", "ns1.new.example.net. 300, ns1.old.example.net. 300"},
+		{m.stepSwitch, "ns1.new.example.net. 300", "ns1.new.example.net. 300"},
+		{m.stepRemove, "ns1.new.example.net. 300", "ns1.new.example.net. 300"},
+	}
+	for i, tt := range tests {
+		m.state.WaitUntil = time.Time{}
+		if ok, err := tt.step(); !ok || err != nil {
+			t.Fatalf("step %s = %v, %v", migrateSteps[i].name, ok, err)
+		}
+		if got := apexNS(from); got != tt.from {
+			t.Errorf("after %s: old provider has NS %q, want %q", migrateSteps[i].name, got, tt.from)
+		}
+		if got := apexNS(to); got != tt.to {
+			t.Errorf("after %s: new provider has NS %q, want %q", migrateSteps[i].name, got, tt.to)
+		}
+	}
+
+	// The domain of dnsconfig.js is left alone.
+	if len(dc.Records) != 1 || dc.Metadata["ns_ttl"] != "86400" || len(dc.Nameservers) != 0 {
+		t.Errorf("dnsconfig.js domain changed: %+v", dc)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/StackExchange/dnscontrol/v4/models"
 	"github.com/StackExchange/dnscontrol/v4/pkg/bindserial"
@@ -54,6 +55,8 @@ type PPreviewArgs struct {
 	PopulateOnPreview bool
 	Report            string
 	Full              bool
+	Verify            bool
+	VerifyTimeout     time.Duration
 }
 
 // ReportItem is a record of corrections for a particular domain/provider/registrar.
@@ -159,6 +162,17 @@ func (args *PPushArgs) flags() []cli.Flag {
 		Destination: &args.Interactive,
 		Usage:       "Interactive. Confirm or Exclude each correction before they run",
 	})
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "verify",
+		Destination: &args.Verify,
+		Usage:       "After pushing, query each provider's nameservers until they serve the changes",
+	})
+	flags = append(flags, &cli.DurationFlag{
+		Name:        "verify-timeout",
+		Destination: &args.VerifyTimeout,
+		Value:       2 * time.Minute,
+		Usage:       "How long --verify waits for the nameservers to serve the changes",
+	})
 	return flags
 }
 
@@ -210,6 +224,11 @@ func prun(args PPreviewArgs, push bool, interactive bool, out printer.CLI, repor
 
 	zcache := NewZoneCache()
 
+	var verifier *pushVerifier
+	if push && args.Verify {
+		verifier = newPushVerifier()
+	}
+
 	// Loop over all (or some) zones:
 	zonesToProcess := whichZonesToProcess(cfg.Domains, args.Domains)
 	zonesSerial, zonesConcurrent := splitConcurrent(zonesToProcess, args.ConcurMode)
@@ -272,13 +291,13 @@ func prun(args PPreviewArgs, push bool, interactive bool, out printer.CLI, repor
 		out.PrintfIf(fullMode, "Concurrently gathering: %q\n", zone.Name)
 		go func(zone *models.DomainConfig, args PPreviewArgs, zcache *zoneCache) {
 			defer wg.Done()
-			oneZone(zone, args)
+			oneZone(zone, args, verifier)
 		}(zone, args, zcache)
 	}
 	out.Printf("SERIALLY gathering %d zone(s)\n", len(zonesSerial))
 	for _, zone := range zonesSerial {
 		out.Printf("Serially Gathering: %q\n", zone.Name)
-		oneZone(zone, args)
+		oneZone(zone, args, verifier)
 	}
 	out.PrintfIf(len(zonesConcurrent) > 0, "Waiting for concurrent gathering(s) to complete...")
 	wg.Wait()
@@ -300,7 +319,11 @@ func prun(args PPreviewArgs, push bool, interactive bool, out printer.CLI, repor
 				totalCorrections += numActions
 				out.EndProvider2(provider.Name, numActions)
 				reportItems = append(reportItems, genReportItem(zone.Name, corrections, provider.Name))
-				anyErrors = cmp.Or(anyErrors, pprintOrRunCorrections(zone.Name, provider.Name, corrections, out, push, interactive, notifier, report))
+				failed := pprintOrRunCorrections(zone.Name, provider.Name, corrections, out, push, interactive, notifier, report)
+				anyErrors = cmp.Or(anyErrors, failed)
+				if verifier != nil && !failed {
+					anyErrors = cmp.Or(anyErrors, verifier.verify(zone, provider, args.VerifyTimeout, out))
+				}
 			}
 		}
 
@@ -424,7 +447,7 @@ func oneZonePopulate(zone *models.DomainConfig, zc *zoneCache) {
 	}
 }
 
-func oneZone(zone *models.DomainConfig, args PPreviewArgs) {
+func oneZone(zone *models.DomainConfig, args PPreviewArgs, verifier *pushVerifier) {
 	// Fix the parent zone's delegation: (if able/needed)
 	delegationCorrections, dcCount := generateDelegationCorrections(zone, zone.DNSProviderInstances, zone.RegistrarInstance)
 
@@ -432,7 +455,7 @@ func oneZone(zone *models.DomainConfig, args PPreviewArgs) {
 	providersToProcess := whichProvidersToProcess(zone.DNSProviderInstances, args.Providers)
 	for _, provider := range providersToProcess {
 		// Update the zone's records at the provider:
-		zoneCor, rep, actualChangeCount := generateZoneCorrections(zone, provider)
+		zoneCor, rep, actualChangeCount := generateZoneCorrections(zone, provider, verifier)
 		zone.StoreCorrections(provider.Name, rep)
 		zone.StoreCorrections(provider.Name, zoneCor)
 		zone.IncrementChangeCount(provider.Name, actualChangeCount)
@@ -584,11 +607,28 @@ func generatePopulateCorrections(provider *models.DNSProviderInstance, zoneName
 	}}
 }
 
-func generateZoneCorrections(zone *models.DomainConfig, provider *models.DNSProviderInstance) ([]*models.Correction, []*models.Correction, int) {
-	reports, zoneCorrections, actualChangeCount, err := zonerecs.CorrectZoneRecords(provider.Driver, zone)
+func generateZoneCorrections(zone *models.DomainConfig, provider *models.DNSProviderInstance, verifier *pushVerifier) ([]*models.Correction, []*models.Correction, int) {
+	// Translate any records this provider can't handle (DOWNGRADE()).
+	dc, warnings, err := normalize.DowngradeRecords(zone, provider.ProviderType)
 	if err != nil {
 		return []*models.Correction{{Msg: fmt.Sprintf("Domain %q provider %s Error: %s", zone.Name, provider.Name, err)}}, nil, 0
 	}
+
+	var reports, zoneCorrections []*models.Correction
+	var actualChangeCount int
+	if verifier == nil {
+		reports, zoneCorrections, actualChangeCount, err = zonerecs.CorrectZoneRecords(provider.Driver, dc)
+	} else {
+		var changes map[models.RecordKey]models.Records
+		reports, zoneCorrections, actualChangeCount, changes, err = zonerecs.CorrectZoneRecordsWithChanges(provider.Driver, dc)
+		verifier.store(zone, provider, changes)
+	}
+	if err != nil {
+		return []*models.Correction{{Msg: fmt.Sprintf("Domain %q provider %s Error: %s", zone.Name, provider.Name, err)}}, nil, 0
+	}
+	for _, w := range warnings {
+		reports = append([]*models.Correction{{Msg: "WARNING: " + w.Error()}}, reports...)
+	}
 	return zoneCorrections, reports, actualChangeCount
 }
 
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/StackExchange/dnscontrol/v4/models"
@@ -13,6 +14,7 @@ import (
 	"github.com/StackExchange/dnscontrol/v4/pkg/normalize"
 	"github.com/StackExchange/dnscontrol/v4/pkg/rfc4183"
 	"github.com/StackExchange/dnscontrol/v4/pkg/rtypes"
+	"github.com/StackExchange/dnscontrol/v4/pkg/yamlconfig"
 	"github.com/urfave/cli/v2"
 )
 
@@ -51,6 +53,7 @@ var _ = cmd(catDebug, func() *cli.Command {
 			pargs.JSONFile = args.JSONFile
 			pargs.DevMode = args.DevMode
 			pargs.Variable = args.Variable
+			pargs.VarsFile = args.VarsFile
 			// Force these settings:
 			pargs.Pretty = false
 			pargs.Output = os.DevNull
@@ -119,15 +122,35 @@ func PrintValidationErrors(errs []error) (fatal bool) {
 	return
 }
 
-// ExecuteDSL executes the dnsconfig.js contents.
+// ExecuteDSL executes the dnsconfig.js contents (or reads the YAML
+// equivalent).
 func ExecuteDSL(args ExecuteDSLArgs) (*models.DNSConfig, error) {
 	if args.JSFile == "" {
 		return nil, errors.New("no config specified")
 	}
 
-	dnsConfig, err := js.ExecuteJavaScript(args.JSFile, args.DevMode, stringSliceToMap(args.Variable))
+	var dnsConfig *models.DNSConfig
+	var err error
+	switch strings.ToLower(filepath.Ext(args.JSFile)) {
+	case ".yaml", ".yml":
+		if args.VarsFile != "" || len(args.Variable.Value()) != 0 {
+			return nil, fmt.Errorf("%s: --variable and --vars-file can't be used with a YAML config", args.JSFile)
+		}
+		// The errors already include the filename and line number.
+		dnsConfig, err = yamlconfig.Load(args.JSFile)
+	default:
+		var variables map[string]interface{}
+		variables, err = args.variables()
+		if err != nil {
+			return nil, err
+		}
+		dnsConfig, err = js.ExecuteJavaScript(args.JSFile, args.DevMode, variables)
+		if err != nil {
+			err = fmt.Errorf("executing %s: %w", args.JSFile, err)
+		}
+	}
 	if err != nil {
-		return nil, fmt.Errorf("executing %s: %w", args.JSFile, err)
+		return nil, err
 	}
 
 	err = rtypes.PostProcess(dnsConfig.Domains)
@@ -169,14 +192,22 @@ func exit(err error) error {
 	return cli.Exit(err, 1)
 }
 
-// stringSliceToMap converts cli.StringSlice to map[string]string for further processing
-func stringSliceToMap(stringSlice cli.StringSlice) map[string]string {
-	mapString := make(map[string]string, len(stringSlice.Value()))
-	for _, values := range stringSlice.Value() {
+// variables returns the variables to define before running the
+// JavaScript: those in the --vars-file, then those given with
+// --variable, which override them.
+func (args *ExecuteDSLArgs) variables() (map[string]interface{}, error) {
+	variables := map[string]interface{}{}
+	if args.VarsFile != "" {
+		var err error
+		if variables, err = js.ReadVariables(args.VarsFile); err != nil {
+			return nil, fmt.Errorf("reading --vars-file: %w", err)
+		}
+	}
+	for _, values := range args.Variable.Value() {
 		parts := strings.SplitN(values, "=", 2)
 		if len(parts) == 2 {
-			mapString[parts[0]] = parts[1]
+			variables[parts[0]] = parts[1]
 		}
 	}
-	return mapString
+	return variables, nil
 }
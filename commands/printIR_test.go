@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+func Test_ExecuteDSL_yamlVariables(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "dnsconfig.yaml")
+	if err := os.WriteFile(filename, []byte("domains: []\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Variables can't be substituted into YAML, so they must not be
+	// silently ignored.
+	args := ExecuteDSLArgs{JSFile: filename, Variable: *cli.NewStringSlice("view=internal")}
+	if _, err := ExecuteDSL(args); err == nil || !strings.Contains(err.Error(), "--variable") {
+		t.Errorf("ExecuteDSL with --variable = %v; want an error", err)
+	}
+	args = ExecuteDSLArgs{JSFile: filename, VarsFile: "vars.json"}
+	if _, err := ExecuteDSL(args); err == nil || !strings.Contains(err.Error(), "--vars-file") {
+		t.Errorf("ExecuteDSL with --vars-file = %v; want an error", err)
+	}
+}
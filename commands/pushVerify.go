@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"sync"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/StackExchange/dnscontrol/v4/pkg/dnsverify"
+	"github.com/StackExchange/dnscontrol/v4/pkg/printer"
+)
+
+// verifyInterval is how long to wait between rounds of queries.
+const verifyInterval = 5 * time.Second
+
+// pushVerifier remembers which RRsets each provider is about to change,
+// so that "push --verify" can check that the provider's nameservers
+// serve the new data afterwards.
+type pushVerifier struct {
+	sync.Mutex
+	changes map[string]map[models.RecordKey]models.Records // Key: uniquename + "/" + provider name
+}
+
+func newPushVerifier() *pushVerifier {
+	return &pushVerifier{changes: map[string]map[models.RecordKey]models.Records{}}
+}
+
+func (pv *pushVerifier) store(zone *models.DomainConfig, provider *models.DNSProviderInstance, changes map[models.RecordKey]models.Records) {
+	pv.Lock()
+	defer pv.Unlock()
+	pv.changes[zone.GetUniqueName()+"/"+provider.Name] = changes
+}
+
+func (pv *pushVerifier) get(zone *models.DomainConfig, provider *models.DNSProviderInstance) map[models.RecordKey]models.Records {
+	pv.Lock()
+	defer pv.Unlock()
+	return pv.changes[zone.GetUniqueName()+"/"+provider.Name]
+}
+
+// verify queries the provider's nameservers until they serve the changed
+// RRsets or the timeout expires. It returns true if verification failed.
+func (pv *pushVerifier) verify(zone *models.DomainConfig, provider *models.DNSProviderInstance, timeout time.Duration, out printer.CLI) bool {
+	changes := pv.get(zone, provider)
+	if len(changes) == 0 {
+		return false
+	}
+
+	nss, err := provider.Driver.GetNameservers(zone.Name)
+	if err != nil {
+		out.Printf("VERIFY: can't get the nameservers of %s at %s: %s\n", zone.Name, provider.Name, err)
+		return true
+	}
+	if len(nss) == 0 {
+		out.Printf("VERIFY: %s reports no nameservers for %s. Skipping verification.\n", provider.Name, zone.Name)
+		return false
+	}
+	servers := make([]string, len(nss))
+	for i, ns := range nss {
+		servers[i] = ns.Name
+	}
+
+	out.Printf("VERIFY: waiting up to %s for %d nameserver(s) to serve %d changed RRset(s)\n", timeout, len(servers), len(changes))
+	failed := false
+	for _, st := range dnsverify.Verify(servers, changes, timeout, verifyInterval) {
+		out.Printf("VERIFY: %s\n", st)
+		if !st.OK() {
+			failed = true
+		}
+	}
+	return failed
+}
@@ -43,6 +43,22 @@ type Duration =
  *
  * `FETCH` is not enabled by default. Please read the warnings below.
  *
+ * The responses are recorded in `fetch.lock.json`, next to `dnsconfig.js`,
+ * along with a SHA-256 hash of each one. Later runs replay the responses
+ * from this lockfile instead of fetching them again, so they give the same
+ * result, even offline. Replaying doesn't need `--allow-fetch`. Commit the
+ * lockfile, so that reviewers can see exactly what external data changed.
+ * Requests are matched by method, URL, body and headers. Only a hash of
+ * the body and of the headers is recorded, so credentials in an
+ * `Authorization` header don't end up in the lockfile.
+ *
+ * * `--refresh-fetch` fetches everything again and rewrites the lockfile.
+ * * `--fetch-lockfile FILE` uses another lockfile.
+ * * `--fetch-allow PREFIX` only allows URLs that start with `PREFIX`. Repeat the flag to allow several prefixes.
+ *
+ * These are global flags, so they must come before the subcommand:
+ * `dnscontrol --allow-fetch --refresh-fetch preview`.
+ *
  * > WARNING:
  * >
  * > 1. Relying on external sources adds a point of failure. If the external source doesn't work, your script won't either. Please make sure you are aware of the consequences.
@@ -292,6 +308,64 @@ declare const AUTODNSSEC_OFF: DomainModifier;
  */
 declare const AUTODNSSEC_ON: DomainModifier;
 
+/**
+ * `AUTOPTR()` keeps a reverse domain (`in-addr.arpa` or `ip6.arpa`) in
+ * sync with the forward domains: each `A` and `AAAA` record of every
+ * forward domain in `dnsconfig.js` generates the matching `PTR` record, so
+ * forward and reverse can't drift apart.
+ *
+ * An address goes to the most specific reverse domain of the configuration
+ * that covers it (for example a [classless](RFC2317_BUILDER.md)
+ * `64/26.2.0.192.in-addr.arpa` rather than `2.0.192.in-addr.arpa`), and a
+ * `PTR` is only generated if that domain has `AUTOPTR()`. Addresses that
+ * no reverse domain covers, and wildcard names, are skipped.
+ *
+ * ```javascript
+ * D("example.com", REG_MY_PROVIDER, DnsProvider(DSP_MY_PROVIDER),
+ *   A("@", "192.0.2.1"),
+ *   A("mail", "192.0.2.25"),
+ *   AAAA("mail", "2001:db8::25"),
+ * );
+ * D(REV("192.0.2.0/24"), REG_MY_PROVIDER, DnsProvider(DSP_MY_PROVIDER),
+ *   AUTOPTR(),
+ *   // Generated: PTR("1", "example.com."), PTR("25", "mail.example.com.")
+ * );
+ * D(REV("2001:db8::/32"), REG_MY_PROVIDER, DnsProvider(DSP_MY_PROVIDER),
+ *   AUTOPTR(),
+ * );
+ * ```
+ *
+ * A [`PTR()`](PTR.md) written in the reverse domain takes precedence over
+ * the generated ones for its address. The generated records have the TTL
+ * of the `A` or `AAAA` record.
+ *
+ * ## Conflicts
+ *
+ * When several names claim the same address (the same name in several
+ * views of a [split horizon](../top-level-functions/D.md) domain is one
+ * claim), `policy` says what to do:
+ *
+ * | Policy | Result |
+ * |--------|--------|
+ * | `"error"` (the default) | The conflict is an error. |
+ * | `"first"` | A `PTR` to the name that comes first in `dnsconfig.js`, with a warning. |
+ * | `"all"` | A `PTR` to each name, with a warning. |
+ * | `"skip"` | No `PTR` for the address, with a warning. |
+ *
+ * To choose the name of a single address, write its `PTR()` in the reverse
+ * domain.
+ *
+ * ```javascript
+ * D(REV("192.0.2.0/24"), REG_MY_PROVIDER, DnsProvider(DSP_MY_PROVIDER),
+ *   AUTOPTR("first"),
+ *   PTR("192.0.2.80", "www.example.com."), // Also claimed by web1.example.com.
+ * );
+ * ```
+ *
+ * @see https://docs.dnscontrol.org/language-reference/domain-modifiers/autoptr
+ */
+declare function AUTOPTR(policy?: "error" | "first" | "all" | "skip"): DomainModifier;
+
 /**
  * AZURE_ALIAS is a Azure specific virtual record type that points a record at either another record or an Azure entity.
  * It is analogous to a CNAME, but is usually resolved at request-time and served as an A record.
@@ -617,6 +691,37 @@ declare function CLOUDNS_WR(name: string, target: string, ...modifiers: RecordMo
  */
 declare function CNAME(name: string, target: string, ...modifiers: RecordModifier[]): DomainModifier;
 
+/**
+ * COMMENT attaches a note to a single record, such as who asked for it or
+ * which ticket it came from. The text must be a single line.
+ *
+ * Providers that can store comments keep the note with the record, so that
+ * it is visible to anyone looking at the zone outside of DNSControl:
+ *
+ *   * `BIND` writes it as a comment (`; COMMENT: ...`) after the record.
+ *     Other comments in a zone file are not read back as `COMMENT()`.
+ *   * `CLOUDFLAREAPI` stores it in the record's comment field.
+ *
+ * On those providers, changing only the comment of a record is shown (and
+ * applied) as a modification of that record. See the
+ * [provider features](../../provider/index.md) table for the providers that
+ * support it. For every other provider, `preview` and `push` print a
+ * warning and the comments are ignored.
+ *
+ * `get-zones --format=js` (and `import`) output `COMMENT()` for records
+ * that have a comment.
+ *
+ * ```javascript
+ * D("example.com", REG_MY_PROVIDER, DnsProvider(DSP_MY_PROVIDER),
+ *   A("@", "1.2.3.4", COMMENT("load balancer, see OPS-123")),
+ *   MX("@", 10, "mx.example.com.", COMMENT("primary mail"), TTL(300)),
+ * );
+ * ```
+ *
+ * @see https://docs.dnscontrol.org/language-reference/record-modifiers/comment
+ */
+declare function COMMENT(text: string): RecordModifier;
+
 /**
  * `D` adds a new Domain for DNSControl to manage. The first two arguments are required: the domain name (fully qualified `example.com` without a trailing dot), and the
  * name of the registrar (as previously declared with [NewRegistrar](NewRegistrar.md)). Any number of additional arguments may be included to add DNS Providers with [DNSProvider](NewDnsProvider.md),
@@ -979,6 +1084,46 @@ declare function DOMAIN_ELSEWHERE(name: string, registrar: string, nameserver_na
  */
 declare function DOMAIN_ELSEWHERE_AUTO(name: string, domain: string, registrar: string, dnsProvider: string): void;
 
+/**
+ * `DOWNGRADE()` declares what to do with records of type `rtype` when
+ * they are sent to a DNS provider that does not support that type.
+ * Without it, DNSControl refuses to process a domain that uses a record
+ * type that one of its DNS providers can't handle.
+ *
+ * This makes it possible to dual-host a domain on providers with
+ * different feature sets. The translation is done separately for each
+ * provider: providers that support `rtype` receive the records unchanged.
+ * A warning is printed every time records are translated.
+ *
+ * The actions are:
+ *
+ * * `"RESOLVE"`: (ALIAS only) Look up the ALIAS target and replace the ALIAS with the A and AAAA records it resolves to.  The optional `arg` is the TTL of the generated records (default: the TTL of the ALIAS). See below about keeping them up to date.
+ * * `"DROP"`: Leave the records out.
+ * * `"MAP"`: Change the records to the rtype `arg`. The provider must support that rtype. Only the type changes, so the two rtypes must have the same data: `HTTPS` and `SVCB` can be mapped to each other.
+ *
+ * ```javascript
+ * D("example.com", REG_MY_PROVIDER, DnsProvider(DSP_HAS_ALIAS), DnsProvider(DSP_NO_ALIAS),
+ *   DOWNGRADE("ALIAS", "RESOLVE", "5m"),
+ *   DOWNGRADE("HTTPS", "DROP"),
+ *   ALIAS("@", "lb.example.net."),
+ *   HTTPS("@", 1, ".", "alpn=h2,h3"),
+ * );
+ * ```
+ *
+ * In this example `DSP_NO_ALIAS` receives A/AAAA records (TTL 5 minutes)
+ * in place of the ALIAS record, and no HTTPS record.
+ *
+ * `DOWNGRADE("ALIAS", "RESOLVE")` has no refresh schedule of its own.
+ * The target is looked up every time `preview` or `push` runs (the
+ * `check` command never does), and the A/AAAA records stay as they are
+ * until the next `push`. If the addresses of the target change, schedule
+ * `dnscontrol push` yourself (for example, from cron) at least as often
+ * as the TTL of the generated records.
+ *
+ * @see https://docs.dnscontrol.org/language-reference/domain-modifiers/downgrade
+ */
+declare function DOWNGRADE(rtype: string, action: "RESOLVE" | "DROP" | "MAP", arg?: (string|number)): DomainModifier;
+
 /**
  * DS adds a DS record to the domain.
  *
@@ -1534,6 +1679,92 @@ declare function IGNORE_TARGET(pattern: string, rType: string): DomainModifier;
  */
 declare function INCLUDE(domain: string): DomainModifier;
 
+/**
+ * `INVENTORY(path)` reads a host inventory, such as an export from a
+ * spreadsheet or an IPAM system, and returns its hosts. Pass them to
+ * [`INVENTORY_RECORDS()`](../domain-modifiers/INVENTORY_RECORDS.md) to
+ * generate the A, AAAA, CNAME and PTR records of each domain.
+ *
+ * `path` is relative to the directory of the file that is being executed
+ * (`dnsconfig.js`, or the file loaded with [`require()`](require.md)). If
+ * it ends in `.csv`, the file is read as CSV. Otherwise it is read as JSON.
+ *
+ * A CSV file must start with a header line that names its columns. The
+ * `hostname` column is required; `ipv4`, `ipv6` and `aliases` are optional
+ * and other columns are ignored. A cell may list several addresses or
+ * aliases, separated by spaces, commas or semicolons.
+ *
+ * ```text
+ * hostname,ipv4,ipv6,aliases,owner
+ * web1.example.com,10.1.2.1,2001:db8::1,www.example.com,web team
+ * db1.example.com,10.1.2.2,,db.example.com;mysql.example.net,dba
+ * ```
+ *
+ * A JSON file is a list of objects with the same keys. The addresses and
+ * aliases may be lists or strings.
+ *
+ * ```json
+ * [
+ *   { "hostname": "web1.example.com", "ipv4": "10.1.2.1", "ipv6": ["2001:db8::1"], "aliases": ["www.example.com"] },
+ *   { "hostname": "db1.example.com", "ipv4": "10.1.2.2", "aliases": "db.example.com mysql.example.net" }
+ * ]
+ * ```
+ *
+ * Hostnames and aliases must be fully qualified (the trailing dot is
+ * optional). `INVENTORY()` fails, listing every problem with its file and
+ * line, if:
+ *
+ * * a hostname or alias is used more than once, or
+ * * an address is used by more than one host (which would need two PTR
+ *   records), or
+ * * an address is not valid, or is in the wrong column.
+ *
+ * @see https://docs.dnscontrol.org/language-reference/top-level-functions/inventory
+ */
+declare function INVENTORY(path: string): { hostname: string; ipv4?: string[]; ipv6?: string[]; aliases?: string[]; source?: string }[];
+
+/**
+ * `INVENTORY_RECORDS(hosts)` adds the records that the hosts of an
+ * inventory (as returned by [`INVENTORY()`](../top-level-functions/INVENTORY.md))
+ * need in the domain:
+ *
+ * * In a forward domain, an `A` and `AAAA` record for each address of the
+ *   hosts whose hostname is in the domain, and a `CNAME` to the hostname
+ *   for each alias in the domain.
+ * * In a reverse domain (`in-addr.arpa` or `ip6.arpa`, including
+ *   [classless](../top-level-functions/REV.md) ones), a `PTR` record to
+ *   the hostname for each address in the domain.
+ *
+ * Hosts that don't belong in the domain are skipped, so the same list is
+ * used in every `D()`. The `modifiers` (such as `TTL()`) are applied to
+ * each record. Errors about the records refer to the inventory's file
+ * and line.
+ *
+ * ```javascript
+ * var HOSTS = INVENTORY("hosts.csv");
+ *
+ * D("example.com", REG_MY_PROVIDER, DnsProvider(DSP_MY_PROVIDER),
+ *   INVENTORY_RECORDS(HOSTS, TTL(600)),
+ * );
+ * D("example.net", REG_MY_PROVIDER, DnsProvider(DSP_MY_PROVIDER),
+ *   INVENTORY_RECORDS(HOSTS),
+ * );
+ * D(REV("10.1.2.0/24"), REG_MY_PROVIDER, DnsProvider(DSP_MY_PROVIDER),
+ *   INVENTORY_RECORDS(HOSTS),
+ * );
+ * D(REV("2001:db8::/32"), REG_MY_PROVIDER, DnsProvider(DSP_MY_PROVIDER),
+ *   INVENTORY_RECORDS(HOSTS),
+ * );
+ * ```
+ *
+ * The hosts can also be built in JavaScript, for example from a file read
+ * with [`READ_JSON()`](../top-level-functions/READ_JSON.md). They are
+ * checked the same way as those of `INVENTORY()`.
+ *
+ * @see https://docs.dnscontrol.org/language-reference/domain-modifiers/inventory_records
+ */
+declare function INVENTORY_RECORDS(hosts: { hostname: string; ipv4?: string[]; ipv6?: string[]; aliases?: string[] }[], ...modifiers: RecordModifier[]): DomainModifier;
+
 /**
  * Converts an IPv4 address from string to an integer. This allows performing mathematical operations with the IP address.
  *
@@ -2613,6 +2844,69 @@ declare function R53_EVALUATE_TARGET_HEALTH(enabled: boolean): RecordModifier;
  */
 declare function R53_ZONE(zone_id: string): DomainModifier & RecordModifier;
 
+/**
+ * `READ_JSON(path)` reads a JSON file and returns its contents as a
+ * JavaScript value (an object, array, string, number or boolean). Use it
+ * to build records from data that is maintained elsewhere, such as a host
+ * list exported from an inventory system, instead of generating JavaScript
+ * from it.
+ *
+ * `path` is relative to the directory of the file that is being executed
+ * (`dnsconfig.js`, or the file loaded with [`require()`](require.md)).
+ *
+ * To read a YAML file, use [`READ_YAML()`](READ_YAML.md).
+ *
+ * ```json
+ * [
+ *   { "name": "web1", "ip": "10.0.0.1" },
+ *   { "name": "web2", "ip": "10.0.0.2" }
+ * ]
+ * ```
+ *
+ * ```javascript
+ * var HOSTS = READ_JSON("hosts.json");
+ *
+ * D("example.com", REG_MY_PROVIDER, DnsProvider(DSP_MY_PROVIDER),
+ *   HOSTS.map(function (h) { return A(h.name, h.ip); }),
+ * );
+ * ```
+ *
+ * Variables can also be loaded from a JSON or YAML file before
+ * `dnsconfig.js` runs, with the `--vars-file` flag of
+ * [`preview` and `push`](../../preview-push.md).
+ *
+ * @see https://docs.dnscontrol.org/language-reference/top-level-functions/read_json
+ */
+declare function READ_JSON(path: string): any;
+
+/**
+ * `READ_YAML(path)` reads a YAML file and returns its contents as a
+ * JavaScript value (an object, array, string, number or boolean). It is
+ * the YAML equivalent of [`READ_JSON()`](READ_JSON.md).
+ *
+ * `path` is relative to the directory of the file that is being executed
+ * (`dnsconfig.js`, or the file loaded with [`require()`](require.md)).
+ * Mappings must have string keys.
+ *
+ * ```yaml
+ * - name: db1
+ *   ip: 10.0.1.1
+ * - name: db2
+ *   ip: 10.0.1.2
+ * ```
+ *
+ * ```javascript
+ * var DB = READ_YAML("inventory/hosts.yaml");
+ *
+ * D("example.com", REG_MY_PROVIDER, DnsProvider(DSP_MY_PROVIDER),
+ *   DB.map(function (h) { return A(h.name, h.ip); }),
+ * );
+ * ```
+ *
+ * @see https://docs.dnscontrol.org/language-reference/top-level-functions/read_yaml
+ */
+declare function READ_YAML(path: string): any;
+
 /**
  * `REV` returns the reverse lookup domain for an IP network. For
  * example `REV("1.2.3.0/24")` returns `3.2.1.in-addr.arpa.` and
@@ -2726,6 +3020,88 @@ declare function REV(address: string): string;
  */
 declare function REVCOMPAT(rfc: string): string;
 
+/**
+ * `RFC2317_BUILDER` generates the records of a classless reverse
+ * delegation ([RFC 2317](https://www.rfc-editor.org/rfc/rfc2317)): the
+ * delegation of the reverse zone of a block smaller than a /24, such as a
+ * customer's /26, to another zone.
+ *
+ * Such a delegation needs records in two zones:
+ *
+ * * The parent zone (the reverse zone of the /24) delegates the child zone
+ *   with `NS` records, and has a `CNAME` for each address of the block
+ *   (including the network and broadcast addresses) that points to the
+ *   address's name in the child zone.
+ * * The child zone (the zone named by [`REV(block)`](../top-level-functions/REV.md))
+ *   has the `PTR` records.
+ *
+ * Use the same builder in both `D()`s; it adds the records that belong in
+ * each. The child zone may be managed by someone else, in which case
+ * `ptrs` can be left out.
+ *
+ * ## Example
+ *
+ * ```javascript
+ * var CUSTOMER = RFC2317_BUILDER({
+ *   block: "192.0.2.64/26",
+ *   nameservers: ["ns1.customer.example", "ns2.customer.example"],
+ *   ptrs: {
+ *     "192.0.2.65": "router.customer.example",
+ *     "192.0.2.66": "www.customer.example",
+ *   },
+ *   ttl: 3600,
+ * });
+ *
+ * D(REV("192.0.2.0/24"), REG_MY_PROVIDER, DnsProvider(DSP_MY_PROVIDER),
+ *   PTR("1", "gateway.example.com."),
+ *   CUSTOMER,
+ * );
+ * D(REV("192.0.2.64/26"), REG_MY_PROVIDER, DnsProvider(DSP_CUSTOMER),
+ *   CUSTOMER,
+ * );
+ * ```
+ *
+ * This generates, in `2.0.192.in-addr.arpa`:
+ *
+ * ```text
+ * 64/26   IN NS    ns1.customer.example.
+ * 64/26   IN NS    ns2.customer.example.
+ * 64      IN CNAME 64.64/26.2.0.192.in-addr.arpa.
+ * 65      IN CNAME 65.64/26.2.0.192.in-addr.arpa.
+ * ...
+ * 127     IN CNAME 127.64/26.2.0.192.in-addr.arpa.
+ * ```
+ *
+ * and in `64/26.2.0.192.in-addr.arpa`:
+ *
+ * ```text
+ * 65      IN PTR   router.customer.example.
+ * 66      IN PTR   www.customer.example.
+ * ```
+ *
+ * The child zone is named the way `REV()` names it, so with
+ * [`REVCOMPAT("rfc4183")`](../top-level-functions/REVCOMPAT.md) it is
+ * `64-26.2.0.192.in-addr.arpa` and the `CNAME`s point there.
+ *
+ * ## Checks
+ *
+ * Every address must map to exactly one name. `RFC2317_BUILDER` fails if:
+ *
+ * * the block isn't an IPv4 block from /25 to /31, or has bits set beyond
+ *   the mask,
+ * * there are no nameservers, or they aren't fully qualified,
+ * * an address in `ptrs` is outside of the block, is listed twice, or has
+ *   no hostname,
+ * * it is used in a domain that is neither the parent nor the child zone.
+ *
+ * A record of the parent zone that uses the name of a delegated address
+ * (for example a `PTR("65", ...)`, or a second delegation that overlaps
+ * the block) is reported as a `CNAME` conflict.
+ *
+ * @see https://docs.dnscontrol.org/language-reference/domain-modifiers/rfc2317_builder
+ */
+declare function RFC2317_BUILDER(opts: { block: string; nameservers: string[]; ptrs?: { [address: string]: string }; ttl?: Duration }): DomainModifier;
+
 /**
  * `SOA` adds an `SOA` record to a domain. The name should be `@`.  ns and mbox are strings. The other fields are unsigned 32-bit ints.
  *
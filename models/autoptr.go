@@ -0,0 +1,15 @@
+package models
+
+// Conflict policies of AUTOPTR(), which say what to do when several
+// names claim the same address.
+const (
+	// AutoPTRError reports the conflict as an error.
+	AutoPTRError = "error"
+	// AutoPTRFirst generates the PTR to the first name (in the order of
+	// dnsconfig.js), with a warning.
+	AutoPTRFirst = "first"
+	// AutoPTRAll generates a PTR to each name, with a warning.
+	AutoPTRAll = "all"
+	// AutoPTRSkip generates no PTR for the address, with a warning.
+	AutoPTRSkip = "skip"
+)
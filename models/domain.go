@@ -36,6 +36,9 @@ type DomainConfig struct {
 	UnmanagedUnsafe bool               `json:"unmanaged_disable_safety_check,omitempty"` // DISABLE_IGNORE_SAFETY_CHECK
 
 	AutoDNSSEC string `json:"auto_dnssec,omitempty"` // "", "on", "off"
+
+	Downgrades []*DowngradeConfig `json:"downgrades,omitempty"` // DOWNGRADE()
+	AutoPTR    string             `json:"autoptr,omitempty"`    // AUTOPTR(): "", or the conflict policy
 	// DNSSEC        bool              `json:"dnssec,omitempty"`
 
 	// These fields contain instantiated provider instances once everything is linked up.
@@ -0,0 +1,23 @@
+package models
+
+// Actions that DOWNGRADE() may request.
+const (
+	// DowngradeResolve replaces ALIAS records with the A/AAAA records
+	// that the ALIAS target resolves to.
+	DowngradeResolve = "RESOLVE"
+	// DowngradeDrop removes the records (with a warning).
+	DowngradeDrop = "DROP"
+	// DowngradeMap changes the records to a different rtype.
+	DowngradeMap = "MAP"
+)
+
+// DowngradeConfig describes what to do with records of a type that a
+// DNS provider does not support.  The rule is only applied to the
+// providers that lack the capability; the others receive the records
+// unchanged.
+type DowngradeConfig struct {
+	RType  string `json:"rtype"`            // The rtype that may be unsupported.
+	Action string `json:"action"`           // DowngradeResolve, DowngradeDrop, or DowngradeMap.
+	MapTo  string `json:"map_to,omitempty"` // DowngradeMap: the replacement rtype.
+	TTL    uint32 `json:"ttl,omitempty"`    // DowngradeResolve: TTL of the generated records.
+}
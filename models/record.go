@@ -13,6 +13,11 @@ import (
 	"github.com/qdm12/reprint"
 )
 
+// RecordComment is the metadata key of the comment that COMMENT()
+// attaches to a record. Providers with the CanUseComments capability
+// store it along with the record.
+const RecordComment = "comment"
+
 // RecordConfig stores a DNS record.
 // Valid types:
 //
@@ -96,7 +101,8 @@ type RecordConfig struct {
 	target    string            // If a name, must end with "."
 	TTL       uint32            `json:"ttl,omitempty"`
 	Metadata  map[string]string `json:"meta,omitempty"`
-	Original  interface{}       `json:"-"` // Store pointer to provider-specific record object. Used in diffing.
+	Original  interface{}       `json:"-"`                // Store pointer to provider-specific record object. Used in diffing.
+	Source    string            `json:"source,omitempty"` // Where the record was defined ("file:line"), if known.
 
 	// If you add a field to this struct, also add it to the list in the UnmarshalJSON function.
 	MxPreference     uint16            `json:"mxpreference,omitempty"`
@@ -197,6 +203,7 @@ func (rc *RecordConfig) UnmarshalJSON(b []byte) error {
 		TTL       uint32            `json:"ttl,omitempty"`
 		Metadata  map[string]string `json:"meta,omitempty"`
 		Original  interface{}       `json:"-"` // Store pointer to provider-specific record object. Used in diffing.
+		Source    string            `json:"source,omitempty"`
 		Args      []any             `json:"args,omitempty"`
 
 		MxPreference     uint16            `json:"mxpreference,omitempty"`
@@ -544,6 +551,19 @@ func (rc *RecordConfig) GetSVCBValue() []dns.SVCBKeyValue {
 // Records is a list of *RecordConfig.
 type Records []*RecordConfig
 
+// Copy returns a deep copy of the records.
+func (recs Records) Copy() (Records, error) {
+	c := make(Records, 0, len(recs))
+	for _, rec := range recs {
+		n, err := rec.Copy()
+		if err != nil {
+			return nil, err
+		}
+		c = append(c, n)
+	}
+	return c, nil
+}
+
 // HasRecordTypeName returns True if there is a record with this rtype and name.
 func (recs Records) HasRecordTypeName(rtype, name string) bool {
 	for _, r := range recs {
@@ -1,28 +1,62 @@
 package acme
 
 import (
+	"fmt"
 	"log"
 	"time"
 
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/StackExchange/dnscontrol/v4/pkg/dnsverify"
 	"github.com/go-acme/lego/v4/challenge/dns01"
 )
 
 func (c *certManager) preCheckDNS(domain, fqdn, value string, native dns01.PreCheckFunc) (bool, error) {
 	// default record verification in the client library makes sure the authoritative nameservers
 	// have the expected records.
-	// Sometimes the Let's Encrypt verification fails anyway because records have not propagated the provider's network fully.
-	// So we add an additional 60 second sleep just for safety.
 	v, err := native(fqdn, value)
-	if err != nil {
+	if err != nil || !v {
 		return v, err
 	}
+
+	// It only asks the nameservers it finds in the public DNS, so also
+	// ask the ones of our DNS providers, which may not be delegated yet.
+	// The client library calls us again until we return true or it times out.
+	var d *models.DomainConfig
+	if dc := c.cfg.DomainContainingFQDN(fqdn); dc != nil {
+		d = c.domains[dc.Name]
+	}
+	if d == nil {
+		return false, fmt.Errorf("no challenge records were added for %s", fqdn)
+	}
+	if len(d.Nameservers) == 0 {
+		log.Printf("Waiting for %s: no nameservers to check", fqdn)
+		return false, nil
+	}
+	txt := &models.RecordConfig{Type: "TXT"}
+	if err := txt.SetTargetTXT(value); err != nil {
+		return false, err
+	}
+	txt.SetLabelFromFQDN(fqdn, d.Name)
+	for _, ns := range d.Nameservers {
+		if ok, err := dnsverify.Serves(ns.Name, txt); !ok {
+			if err != nil {
+				log.Printf("Waiting for %s: %s", ns.Name, err)
+			} else {
+				log.Printf("Waiting for %s: %s TXT not served yet", ns.Name, fqdn)
+			}
+			return false, nil
+		}
+	}
+
+	// Sometimes the Let's Encrypt verification fails anyway because records have not propagated the provider's network fully.
+	// So we add an additional 60 second sleep just for safety.
 	if !c.waitedOnce {
 		log.Printf("DNS ok. Waiting another 60s to ensure stability.")
 		time.Sleep(60 * time.Second)
 		c.waitedOnce = true
 	}
 	log.Printf("DNS records seem to exist. Proceeding to request validation")
-	return v, err
+	return true, nil
 }
 
 // Timeout increases the client-side polling check time to five minutes with one second waits in-between.
@@ -0,0 +1,100 @@
+// Package catalogzone implements the parts of DNS catalog zones
+// (RFC 9432) that providers need to list, add and remove the member
+// zones of a catalog.
+//
+// A catalog zone lists each member zone in a PTR record named
+// <unique-id>.zones.<catalog>, whose target is the member zone.
+// Secondary servers that consume the catalog provision the member
+// zones automatically.
+package catalogzone
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// Version is the version of the catalog zone schema, published in the
+// TXT record of the version label.
+const Version = "2"
+
+// MemberName returns the owner name (with a trailing dot) of the PTR
+// record of zone in catalog. The unique id is the SHA-1 hash of the
+// zone's name in wire format, as BIND and Knot generate it.
+func MemberName(catalog, zone string) string {
+	buf := make([]byte, 256)
+	n, err := dns.PackDomainName(dns.CanonicalName(zone), buf, 0, nil, false)
+	if err != nil {
+		// Not possible for a name that dnscontrol accepted.
+		panic(fmt.Sprintf("catalogzone: invalid zone name %q: %s", zone, err))
+	}
+	sum := sha1.Sum(buf[:n])
+	return hex.EncodeToString(sum[:]) + ".zones." + dns.CanonicalName(catalog)
+}
+
+// Members returns the member zones of catalog (without trailing dot)
+// listed in rrs, mapped to the owner names of their PTR records.
+func Members(catalog string, rrs []dns.RR) map[string]string {
+	suffix := ".zones." + dns.CanonicalName(catalog)
+	members := map[string]string{}
+	for _, rr := range rrs {
+		ptr, ok := rr.(*dns.PTR)
+		if !ok {
+			continue
+		}
+		owner := dns.CanonicalName(ptr.Hdr.Name)
+		id, ok := strings.CutSuffix(owner, suffix)
+		if !ok || id == "" || strings.Contains(id, ".") {
+			continue // A property (RFC 9432, section 4.3), not a member.
+		}
+		members[strings.TrimSuffix(dns.CanonicalName(ptr.Ptr), ".")] = owner
+	}
+	return members
+}
+
+// SortedZones returns the zones of members, sorted.
+func SortedZones(members map[string]string) []string {
+	zones := make([]string, 0, len(members))
+	for zone := range members {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+	return zones
+}
+
+// IsMemberData reports whether name is the owner name of a member's
+// PTR record (as returned by Members) or of one of its properties.
+// They are removed along with the member.
+func IsMemberData(owner, name string) bool {
+	name = dns.CanonicalName(name)
+	return name == owner || strings.HasSuffix(name, "."+owner)
+}
+
+// NewPTR returns the PTR record that adds zone to catalog.
+func NewPTR(catalog, zone string) *dns.PTR {
+	return &dns.PTR{
+		Hdr: dns.RR_Header{Name: MemberName(catalog, zone), Rrtype: dns.TypePTR, Class: dns.ClassINET},
+		Ptr: dns.CanonicalName(zone),
+	}
+}
+
+// Skeleton returns the records, other than the SOA, that a new
+// catalog zone needs: an NS record (which is required, but never
+// used) and the version.
+func Skeleton(catalog string) []dns.RR {
+	catalog = dns.CanonicalName(catalog)
+	return []dns.RR{
+		&dns.NS{
+			Hdr: dns.RR_Header{Name: catalog, Rrtype: dns.TypeNS, Class: dns.ClassINET},
+			Ns:  "invalid.",
+		},
+		&dns.TXT{
+			Hdr: dns.RR_Header{Name: "version." + catalog, Rrtype: dns.TypeTXT, Class: dns.ClassINET},
+			Txt: []string{Version},
+		},
+	}
+}
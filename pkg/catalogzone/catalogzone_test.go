@@ -0,0 +1,59 @@
+package catalogzone
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestMemberName(t *testing.T) {
+	want := "c5e4b4da1e5a620ddaa3635e55c3732a5b49c7f4.zones.catalog.example."
+	for _, zone := range []string{"example.com", "Example.COM."} {
+		if got := MemberName("catalog.example", zone); got != want {
+			t.Errorf("MemberName(%q) = %q, want %q", zone, got, want)
+		}
+	}
+}
+
+func TestMembers(t *testing.T) {
+	var rrs []dns.RR
+	for _, s := range []string{
+		"catalog.example. 0 IN SOA invalid. invalid. 1 3600 600 86400 0",
+		"catalog.example. 0 IN NS invalid.",
+		"version.catalog.example. 0 IN TXT \"2\"",
+		"a.zones.catalog.example. 0 IN PTR example.com.",
+		"group.a.zones.catalog.example. 0 IN TXT \"web\"",
+		"b.zones.catalog.example. 0 IN PTR Example.NET.",
+		"coo.b.zones.catalog.example. 0 IN PTR other.catalog.",
+	} {
+		rr, err := dns.NewRR(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rrs = append(rrs, rr)
+	}
+
+	members := Members("catalog.example", rrs)
+	want := map[string]string{
+		"example.com": "a.zones.catalog.example.",
+		"example.net": "b.zones.catalog.example.",
+	}
+	if !reflect.DeepEqual(members, want) {
+		t.Errorf("Members() = %v, want %v", members, want)
+	}
+	if got := SortedZones(members); !reflect.DeepEqual(got, []string{"example.com", "example.net"}) {
+		t.Errorf("SortedZones() = %v", got)
+	}
+
+	for name, want := range map[string]bool{
+		"a.zones.catalog.example.":       true,
+		"group.a.zones.catalog.example.": true,
+		"ba.zones.catalog.example.":      false,
+		"b.zones.catalog.example.":       false,
+	} {
+		if got := IsMemberData("a.zones.catalog.example.", name); got != want {
+			t.Errorf("IsMemberData(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
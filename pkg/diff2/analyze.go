@@ -182,7 +182,7 @@ func findTTLChanges(existing, desired []targetConfig) ([]targetConfig, []targetC
 		}
 
 		if ecomp == dcomp && er.TTL != dr.TTL {
-			m := color.YellowString("± MODIFY-TTL %s %s %s", dr.NameFQDN, dr.Type, humanDiff(existing[ei], desired[di]))
+			m := color.YellowString("± MODIFY-TTL %s %s %s%s", dr.NameFQDN, dr.Type, humanDiff(existing[ei], desired[di]), atSource(dr))
 			v := mkChange(dr.NameFQDN, dr.Type, []string{m},
 				models.Records{er},
 				models.Records{dr},
@@ -277,7 +277,7 @@ func diffTargets(existing, desired []targetConfig) ChangeList {
 		er := existing[i].rec
 		dr := desired[i].rec
 
-		m := color.YellowString("± MODIFY %s %s %s", dr.NameFQDN, dr.Type, humanDiff(existing[i], desired[i]))
+		m := color.YellowString("± MODIFY %s %s %s%s", dr.NameFQDN, dr.Type, humanDiff(existing[i], desired[i]), atSource(dr))
 
 		mkc := mkChange(dr.NameFQDN, dr.Type, []string{m}, models.Records{er}, models.Records{dr})
 		if len(existing) == 1 && len(desired) == 1 {
@@ -300,13 +300,22 @@ func diffTargets(existing, desired []targetConfig) ChangeList {
 	// any left-over desired are creates
 	for i := mi; i < len(desired); i++ {
 		dr := desired[i].rec
-		m := color.GreenString("+ CREATE %s %s %s", dr.NameFQDN, dr.Type, desired[i].comparableFull)
+		m := color.GreenString("+ CREATE %s %s %s%s", dr.NameFQDN, dr.Type, desired[i].comparableFull, atSource(dr))
 		instructions = append(instructions, mkAdd(dr.NameFQDN, dr.Type, []string{m}, models.Records{dr}))
 	}
 
 	return instructions
 }
 
+// atSource returns where a desired record was defined, for use in a
+// message, or "" if that isn't known.
+func atSource(rec *models.RecordConfig) string {
+	if rec.Source == "" {
+		return ""
+	}
+	return " (" + rec.Source + ")"
+}
+
 func justMsgs(cl ChangeList) []string {
 	var msgs []string
 	for _, c := range cl {
@@ -866,3 +866,71 @@ func Test_splitTTLOnly(t *testing.T) {
 		})
 	}
 }
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b models.Records
+		want []string
+	}{
+		{
+			name: "same",
+			a:    models.Records{testDataAA1234, testDataAMX10a},
+			b:    models.Records{testDataAMX10a, testDataAA1234clone},
+			want: nil,
+		},
+		{
+			name: "ttl",
+			a:    models.Records{testDataAA1234},
+			b:    models.Records{testDataAA1234ttl700},
+			want: []string{"± MODIFY-TTL laba.f.com A 1.2.3.4 ttl=(300->700)"},
+		},
+		{
+			name: "missing",
+			a:    models.Records{makeRec("laba", "A", "1.2.3.4"), makeRec("labc", "CNAME", "laba.f.com.")},
+			b:    models.Records{makeRec("laba", "A", "1.2.3.4")},
+			want: []string{"- DELETE labc.f.com CNAME laba.f.com. ttl=300"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Compare("f.com", tt.a, tt.b, nil); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Compare() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceInMessages(t *testing.T) {
+	existing := models.Records{makeRec("laba", "A", "1.2.3.4")}
+	desired := models.Records{makeRecTTL("laba", "A", "1.2.3.4", 700), makeRec("labb", "A", "1.2.3.4")}
+	desired[0].Source = "dnsconfig.js:3"
+	desired[1].Source = "dnsconfig.js:4"
+	want := []string{
+		"± MODIFY-TTL laba.f.com A 1.2.3.4 ttl=(300->700) (dnsconfig.js:3)",
+		"+ CREATE labb.f.com A 1.2.3.4 ttl=300 (dnsconfig.js:4)",
+	}
+	if got := Compare("f.com", existing, desired, nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("Compare() = %q, want %q", got, want)
+	}
+}
+
+func TestWithComments(t *testing.T) {
+	withComment := func(comment string) *models.RecordConfig {
+		r := makeRec("laba", "A", "1.2.3.4")
+		r.Metadata = map[string]string{models.RecordComment: comment}
+		return r
+	}
+	existing := models.Records{withComment("web server")}
+	desired := models.Records{withComment("old web server")}
+
+	// Providers that can't store comments don't see the difference.
+	if got := Compare("f.com", existing, desired, nil); got != nil {
+		t.Errorf("Compare() = %q, want nothing", got)
+	}
+
+	want := []string{`± MODIFY laba.f.com A (1.2.3.4 comment="web server" ttl=300) -> (1.2.3.4 comment="old web server" ttl=300)`}
+	if got := Compare("f.com", existing, desired, WithComments(nil)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Compare() = %q, want %q", got, want)
+	}
+}
@@ -46,6 +46,25 @@ exist.
 // blob for custom records and records with metadata.
 type ComparableFunc func(*models.RecordConfig) string
 
+// WithComments returns a ComparableFunc that adds the record's
+// COMMENT() to what f (which may be nil) returns. Providers that can
+// store comments use it so that a changed comment is a CHANGE.
+func WithComments(f ComparableFunc) ComparableFunc {
+	return func(rc *models.RecordConfig) string {
+		var s string
+		if f != nil {
+			s = f(rc)
+		}
+		if comment := rc.Metadata[models.RecordComment]; comment != "" {
+			if s != "" {
+				s += " "
+			}
+			s += fmt.Sprintf("comment=%q", comment)
+		}
+		return s
+	}
+}
+
 // CompareConfig stores a zone's records in a structure that makes comparing two zones convenient.
 type CompareConfig struct {
 	// The primary data. Each record stored once, grouped by label then
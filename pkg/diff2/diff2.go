@@ -212,6 +212,17 @@ func ByZone(existing models.Records, dc *models.DomainConfig, compFunc Comparabl
 	return result, err
 }
 
+// Compare takes two versions of a zone (for example, the zone as
+// served by two different DNS providers) and returns text describing
+// how to turn a into b. Unlike the By*() functions, IGNORE*(),
+// NO_PURGE and ENSURE_ABSENT are not processed: every difference,
+// including TTLs, is reported.
+func Compare(origin string, a, b models.Records, compFunc ComparableFunc) []string {
+	cc := NewCompareConfig(origin, a, b, compFunc)
+	instructions, _ := analyzeByRecord(cc)
+	return justMsgs(instructions)
+}
+
 // ByResults is the results of ByZone() and perhaps someday all the By*() functions.
 // It is partially populated by // byHelperStruct() and partially by the By*()
 // functions that use it.
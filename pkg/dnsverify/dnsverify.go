@@ -0,0 +1,195 @@
+// Package dnsverify checks that authoritative nameservers serve the
+// records we expect them to. It queries each server directly (no
+// recursion, no caches) and retries until the data matches or a
+// timeout expires.
+//
+// It is used by push --verify, and by pkg/acme to check that the
+// nameservers serve the DNS-01 challenges.
+package dnsverify
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/miekg/dns"
+)
+
+// Status is the propagation status of one nameserver.
+type Status struct {
+	Server  string             // The nameserver.
+	Pending []models.RecordKey // RRsets that didn't match before the timeout.
+	Err     error              // The most recent query error, if any.
+}
+
+// OK returns true if the server serves all the expected RRsets.
+func (s Status) OK() bool {
+	return len(s.Pending) == 0
+}
+
+func (s Status) String() string {
+	if s.OK() {
+		return fmt.Sprintf("%s: OK", s.Server)
+	}
+	keys := make([]string, len(s.Pending))
+	for i, k := range s.Pending {
+		keys[i] = k.NameFQDN + " " + k.Type
+	}
+	m := fmt.Sprintf("%s: %d RRset(s) not served yet: %s", s.Server, len(s.Pending), strings.Join(keys, ", "))
+	if s.Err != nil {
+		m += fmt.Sprintf(" (last error: %s)", s.Err)
+	}
+	return m
+}
+
+// exchange sends a query to a server. Replaced by tests.
+var exchange = func(m *dns.Msg, server string) (*dns.Msg, error) {
+	c := &dns.Client{Timeout: 5 * time.Second}
+	r, _, err := c.Exchange(m, server)
+	return r, err
+}
+
+// Verify queries each server for each RRset in expected until every
+// server serves exactly the expected records (ignoring TTLs) or the
+// timeout expires. An RRset that maps to an empty list is expected not
+// to exist. RRsets of types that don't exist in the DNS protocol (ALIAS,
+// URL, and similar pseudo-types) are not checked, nor is the SOA (the
+// provider picks the serial number).
+func Verify(servers []string, expected map[models.RecordKey]models.Records, timeout, interval time.Duration) []Status {
+	want := map[models.RecordKey][]string{}
+	for key, recs := range expected {
+		if _, ok := dns.StringToType[key.Type]; !ok || key.Type == "SOA" {
+			continue
+		}
+		want[key] = comparableRecs(recs)
+	}
+
+	statuses := make([]Status, len(servers))
+	pending := make([]map[models.RecordKey]bool, len(servers))
+	for i, server := range servers {
+		statuses[i].Server = server
+		pending[i] = map[models.RecordKey]bool{}
+		for key := range want {
+			pending[i][key] = true
+		}
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		done := true
+		for i, server := range servers {
+			for key := range pending[i] {
+				got, err := query(server, key)
+				if err != nil {
+					statuses[i].Err = err
+					continue
+				}
+				if slices.Equal(got, want[key]) {
+					delete(pending[i], key)
+				}
+			}
+			if len(pending[i]) != 0 {
+				done = false
+			}
+		}
+		if done || !time.Now().Add(interval).Before(deadline) {
+			break
+		}
+		time.Sleep(interval)
+	}
+
+	for i := range statuses {
+		for key := range pending[i] {
+			statuses[i].Pending = append(statuses[i].Pending, key)
+		}
+		sort.Slice(statuses[i].Pending, func(a, b int) bool {
+			pa, pb := statuses[i].Pending[a], statuses[i].Pending[b]
+			if pa.NameFQDN != pb.NameFQDN {
+				return pa.NameFQDN < pb.NameFQDN
+			}
+			return pa.Type < pb.Type
+		})
+	}
+	return statuses
+}
+
+// query asks server for an RRset and returns it in comparable form.
+func query(server string, key models.RecordKey) ([]string, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(key.NameFQDN), dns.StringToType[key.Type])
+	m.RecursionDesired = false
+	m.SetEdns0(4096, false)
+
+	r, err := exchange(m, net.JoinHostPort(server, "53"))
+	if err != nil {
+		return nil, err
+	}
+	if r.Rcode != dns.RcodeSuccess && r.Rcode != dns.RcodeNameError {
+		return nil, fmt.Errorf("%s %s: %s", key.NameFQDN, key.Type, dns.RcodeToString[r.Rcode])
+	}
+
+	var rrs []dns.RR
+	for _, rr := range r.Answer {
+		// Skip anything else that came along (a CNAME chain, RRSIGs, etc.)
+		if rr.Header().Rrtype == m.Question[0].Qtype && strings.EqualFold(rr.Header().Name, m.Question[0].Name) {
+			rrs = append(rrs, rr)
+		}
+	}
+	return comparableRRs(rrs), nil
+}
+
+// Serves returns true if server serves rec, whatever else is in its
+// RRset.
+func Serves(server string, rec *models.RecordConfig) (bool, error) {
+	got, err := query(server, rec.Key())
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(got, comparableRecs(models.Records{rec})[0]), nil
+}
+
+// Serial asks server for the SOA of zone and returns its serial number.
+func Serial(server, zone string) (uint32, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(zone), dns.TypeSOA)
+	m.RecursionDesired = false
+
+	r, err := exchange(m, net.JoinHostPort(server, "53"))
+	if err != nil {
+		return 0, err
+	}
+	if r.Rcode != dns.RcodeSuccess {
+		return 0, fmt.Errorf("%s SOA: %s", zone, dns.RcodeToString[r.Rcode])
+	}
+	for _, rr := range r.Answer {
+		if soa, ok := rr.(*dns.SOA); ok && strings.EqualFold(soa.Hdr.Name, m.Question[0].Name) {
+			return soa.Serial, nil
+		}
+	}
+	return 0, fmt.Errorf("%s SOA: no answer (the server isn't authoritative)", zone)
+}
+
+// comparableRecs returns the records as sorted strings, without TTLs.
+func comparableRecs(recs models.Records) []string {
+	rrs := make([]dns.RR, 0, len(recs))
+	for _, rec := range recs {
+		rrs = append(rrs, rec.ToRR())
+	}
+	return comparableRRs(rrs)
+}
+
+func comparableRRs(rrs []dns.RR) []string {
+	s := make([]string, 0, len(rrs))
+	for _, rr := range rrs {
+		rr = dns.Copy(rr)
+		rr.Header().Ttl = 0
+		rr.Header().Name = strings.ToLower(rr.Header().Name)
+		s = append(s, rr.String())
+	}
+	sort.Strings(s)
+	return s
+}
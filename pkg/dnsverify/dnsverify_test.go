@@ -0,0 +1,126 @@
+package dnsverify
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/miekg/dns"
+)
+
+func makeRec(label, rtype, target string) *models.RecordConfig {
+	r := &models.RecordConfig{Type: rtype, TTL: 300}
+	r.SetLabel(label, "example.com")
+	if err := r.SetTarget(target); err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func TestVerify(t *testing.T) {
+	// A fake "DNS": ns1 serves the new data, ns2 serves stale data and
+	// ns3 is unreachable.
+	saved := exchange
+	defer func() { exchange = saved }()
+	exchange = func(m *dns.Msg, server string) (*dns.Msg, error) {
+		r := new(dns.Msg)
+		r.SetReply(m)
+		q := m.Question[0]
+		switch server {
+		case "ns1.example.net:53":
+			if q.Name == "www.example.com." && q.Qtype == dns.TypeA {
+				rr, _ := dns.NewRR("WWW.example.com. 3600 IN A 192.0.2.1")
+				r.Answer = append(r.Answer, rr)
+			} else {
+				r.Rcode = dns.RcodeNameError
+			}
+		case "ns2.example.net:53":
+			rr, _ := dns.NewRR(q.Name + " 300 IN A 192.0.2.99")
+			r.Answer = append(r.Answer, rr)
+		default:
+			return nil, errors.New("timeout")
+		}
+		return r, nil
+	}
+
+	expected := map[models.RecordKey]models.Records{
+		{NameFQDN: "www.example.com", Type: "A"}: {makeRec("www", "A", "192.0.2.1")},
+		{NameFQDN: "old.example.com", Type: "A"}: {},                                         // Deleted.
+		{NameFQDN: "example.com", Type: "ALIAS"}: {makeRec("@", "ALIAS", "lb.example.net.")}, // Not checked.
+		{NameFQDN: "example.com", Type: "SOA"}:   {},                                         // Not checked.
+	}
+	got := Verify([]string{"ns1.example.net", "ns2.example.net", "ns3.example.net"}, expected, 0, time.Millisecond)
+
+	if len(got) != 3 {
+		t.Fatalf("got %d statuses, want 3", len(got))
+	}
+	if !got[0].OK() {
+		t.Errorf("ns1: %s; want OK", got[0])
+	}
+	if got[1].OK() || len(got[1].Pending) != 2 {
+		t.Errorf("ns2: %s; want 2 pending", got[1])
+	}
+	if got[2].OK() || got[2].Err == nil {
+		t.Errorf("ns3: %s; want pending with an error", got[2])
+	}
+}
+
+func TestSerial(t *testing.T) {
+	saved := exchange
+	defer func() { exchange = saved }()
+	exchange = func(m *dns.Msg, server string) (*dns.Msg, error) {
+		r := new(dns.Msg)
+		r.SetReply(m)
+		switch server {
+		case "ns1.example.net:53":
+			rr, _ := dns.NewRR("Example.COM. 3600 IN SOA ns1.example.net. hostmaster.example.com. 2024030501 3600 600 604800 300")
+			r.Answer = append(r.Answer, rr)
+		case "ns2.example.net:53":
+			r.Rcode = dns.RcodeRefused
+		}
+		return r, nil
+	}
+
+	if serial, err := Serial("ns1.example.net", "example.com"); serial != 2024030501 || err != nil {
+		t.Errorf("Serial(ns1) = %d, %v; want 2024030501", serial, err)
+	}
+	if _, err := Serial("ns2.example.net", "example.com"); err == nil {
+		t.Error("Serial(ns2) succeeded, want REFUSED")
+	}
+	if _, err := Serial("ns3.example.net", "example.com"); err == nil {
+		t.Error("Serial(ns3) succeeded, want no answer")
+	}
+}
+
+func TestServes(t *testing.T) {
+	saved := exchange
+	defer func() { exchange = saved }()
+	exchange = func(m *dns.Msg, server string) (*dns.Msg, error) {
+		r := new(dns.Msg)
+		r.SetReply(m)
+		switch server {
+		case "ns1.example.net:53":
+			for _, txt := range []string{"token1", "token2"} {
+				rr, _ := dns.NewRR(`_acme-challenge.example.com. 120 IN TXT "` + txt + `"`)
+				r.Answer = append(r.Answer, rr)
+			}
+		case "ns2.example.net:53":
+			r.Rcode = dns.RcodeNameError
+		default:
+			return nil, errors.New("timeout")
+		}
+		return r, nil
+	}
+
+	rec := makeRec("_acme-challenge", "TXT", "token2")
+	if ok, err := Serves("ns1.example.net", rec); !ok || err != nil {
+		t.Errorf("Serves(ns1) = %v, %v; want true", ok, err)
+	}
+	if ok, err := Serves("ns2.example.net", rec); ok || err != nil {
+		t.Errorf("Serves(ns2) = %v, %v; want false", ok, err)
+	}
+	if _, err := Serves("ns3.example.net", rec); err == nil {
+		t.Error("Serves(ns3) succeeded, want an error")
+	}
+}
@@ -0,0 +1,279 @@
+// Package inventory turns a host inventory (a CSV or JSON export of
+// hostnames, IP addresses and aliases) into DNS records: A, AAAA and
+// CNAME records for the forward zones, and PTR records for the reverse
+// zones.
+//
+// Each host has a fully qualified hostname, any number of IPv4 and
+// IPv6 addresses, and any number of aliases (also fully qualified).
+// A hostname or alias may only be used once, and an address may only
+// belong to one host, so that each address has a single PTR record.
+package inventory
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v4/pkg/transform"
+)
+
+// Host is a host of the inventory.
+type Host struct {
+	Name    string   `json:"hostname"`
+	IPv4    []string `json:"ipv4,omitempty"`
+	IPv6    []string `json:"ipv6,omitempty"`
+	Aliases []string `json:"aliases,omitempty"`
+	// Source is where the host is defined ("file:line"), for error
+	// messages.
+	Source string `json:"source,omitempty"`
+}
+
+// Record is a DNS record generated from the inventory.
+type Record struct {
+	Type   string `json:"type"` // A, AAAA, CNAME or PTR
+	Name   string `json:"name"` // Relative to the zone, "@" for the apex.
+	Target string `json:"target"`
+	Source string `json:"source,omitempty"`
+}
+
+// Load reads an inventory file. Files whose name ends in .csv are
+// read as CSV, with a header line naming the columns "hostname",
+// "ipv4", "ipv6" and "aliases" (other columns are ignored). Other
+// files are read as a JSON array of objects with the same keys. The
+// addresses and aliases may be lists, separated by spaces, commas or
+// semicolons in CSV, or JSON arrays.
+//
+// Load doesn't check the hosts; use Check for that.
+func Load(file string) ([]Host, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(file), ".csv") {
+		return parseCSV(f, file)
+	}
+	return parseJSON(f, file)
+}
+
+func parseCSV(r io.Reader, file string) ([]Host, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%s: reading the header: %w", file, err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["hostname"]; !ok {
+		return nil, fmt.Errorf("%s: the header has no hostname column", file)
+	}
+	cell := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var hosts []Host
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		line, _ := cr.FieldPos(0)
+		name := cell(row, "hostname")
+		if name == "" && strings.Join(row, "") == "" {
+			continue // Blank line.
+		}
+		hosts = append(hosts, Host{
+			Name:    name,
+			IPv4:    splitList(cell(row, "ipv4")),
+			IPv6:    splitList(cell(row, "ipv6")),
+			Aliases: splitList(cell(row, "aliases")),
+			Source:  fmt.Sprintf("%s:%d", file, line),
+		})
+	}
+	return hosts, nil
+}
+
+// list is a list of strings in JSON: either an array, or a string
+// that splitList splits.
+type list []string
+
+func (l *list) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*l = splitList(s)
+		return nil
+	}
+	return json.Unmarshal(b, (*[]string)(l))
+}
+
+func parseJSON(r io.Reader, file string) ([]Host, error) {
+	var entries []struct {
+		Name    string `json:"hostname"`
+		IPv4    list   `json:"ipv4"`
+		IPv6    list   `json:"ipv6"`
+		Aliases list   `json:"aliases"`
+	}
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	hosts := make([]Host, len(entries))
+	for i, e := range entries {
+		hosts[i] = Host{
+			Name:    e.Name,
+			IPv4:    e.IPv4,
+			IPv6:    e.IPv6,
+			Aliases: e.Aliases,
+			Source:  fmt.Sprintf("%s[%d]", file, i),
+		}
+	}
+	return hosts, nil
+}
+
+func splitList(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == ',' || r == ';'
+	})
+}
+
+// Check normalizes the hostnames, aliases and addresses of the hosts
+// (lower case, no trailing dot, canonical address format) and returns
+// the problems it finds: invalid names or addresses, names that are
+// used more than once, and addresses that belong to more than one
+// host.
+func Check(hosts []Host) (errs []error) {
+	names := map[string]string{} // name -> source of its first use
+	addrs := map[string]string{} // address -> hostname
+	fail := func(h *Host, format string, args ...any) {
+		msg := fmt.Sprintf(format, args...)
+		if h.Source != "" {
+			msg = h.Source + ": " + msg
+		}
+		errs = append(errs, errors.New(msg))
+	}
+	useName := func(h *Host, name string) {
+		if !strings.Contains(name, ".") {
+			fail(h, "%q is not a fully qualified name", name)
+			return
+		}
+		if where, ok := names[name]; ok {
+			fail(h, "%s is already defined (at %s)", name, where)
+			return
+		}
+		names[name] = h.Source
+		if h.Source == "" {
+			names[name] = h.Name // Hosts created in JavaScript have no source.
+		}
+	}
+	useAddrs := func(h *Host, list []string, is4 bool) {
+		family := "IPv6"
+		if is4 {
+			family = "IPv4"
+		}
+		for i, s := range list {
+			a, err := netip.ParseAddr(s)
+			if err != nil || a.Is4() != is4 || a.Zone() != "" {
+				fail(h, "%s: %q is not an %s address", h.Name, s, family)
+				continue
+			}
+			list[i] = a.String()
+			if other, ok := addrs[list[i]]; ok {
+				fail(h, "%s: %s is already used by %s", h.Name, list[i], other)
+				continue
+			}
+			addrs[list[i]] = h.Name
+		}
+	}
+
+	for i := range hosts {
+		h := &hosts[i]
+		h.Name = normalizeName(h.Name)
+		if h.Name == "" {
+			fail(h, "missing hostname")
+			continue
+		}
+		useName(h, h.Name)
+		for j, alias := range h.Aliases {
+			h.Aliases[j] = normalizeName(alias)
+			useName(h, h.Aliases[j])
+		}
+		useAddrs(h, h.IPv4, true)
+		useAddrs(h, h.IPv6, false)
+	}
+	return errs
+}
+
+func normalizeName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
+
+// Records returns the records that the hosts (which must have passed
+// Check) need in zone. If zone is a reverse zone (in-addr.arpa or
+// ip6.arpa), these are the PTR records of the addresses in it.
+// Otherwise they are the A and AAAA records of the hosts in zone, and
+// CNAME records for their aliases in zone.
+func Records(hosts []Host, zone string) []Record {
+	zone = normalizeName(zone)
+	var recs []Record
+
+	if strings.HasSuffix(zone, ".in-addr.arpa") || strings.HasSuffix(zone, ".ip6.arpa") {
+		addrs := func(h Host) []string { return h.IPv4 }
+		if strings.HasSuffix(zone, ".ip6.arpa") {
+			addrs = func(h Host) []string { return h.IPv6 }
+		}
+		for _, h := range hosts {
+			for _, addr := range addrs(h) {
+				// PtrNameMagic fails for addresses outside of the zone.
+				if label, err := transform.PtrNameMagic(addr, zone); err == nil {
+					recs = append(recs, Record{Type: "PTR", Name: label, Target: h.Name + ".", Source: h.Source})
+				}
+			}
+		}
+		return recs
+	}
+
+	for _, h := range hosts {
+		if label, ok := relativeName(h.Name, zone); ok {
+			for _, addr := range h.IPv4 {
+				recs = append(recs, Record{Type: "A", Name: label, Target: addr, Source: h.Source})
+			}
+			for _, addr := range h.IPv6 {
+				recs = append(recs, Record{Type: "AAAA", Name: label, Target: addr, Source: h.Source})
+			}
+		}
+		for _, alias := range h.Aliases {
+			if label, ok := relativeName(alias, zone); ok {
+				recs = append(recs, Record{Type: "CNAME", Name: label, Target: h.Name + ".", Source: h.Source})
+			}
+		}
+	}
+	return recs
+}
+
+// relativeName returns name relative to zone, if it is in zone.
+func relativeName(name, zone string) (string, bool) {
+	if name == zone {
+		return "@", true
+	}
+	if label, ok := strings.CutSuffix(name, "."+zone); ok {
+		return label, true
+	}
+	return "", false
+}
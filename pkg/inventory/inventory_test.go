@@ -0,0 +1,95 @@
+package inventory
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseJSON(t *testing.T) {
+	hosts, err := parseJSON(strings.NewReader(`[
+  {"hostname": "a.example.com.", "ipv4": "10.0.0.1, 10.0.0.2", "aliases": ["www.example.com"]},
+  {"hostname": "b.example.com", "ipv6": ["2001:db8::1"]}
+]`), "hosts.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Host{
+		{Name: "a.example.com.", IPv4: []string{"10.0.0.1", "10.0.0.2"}, Aliases: []string{"www.example.com"}, Source: "hosts.json[0]"},
+		{Name: "b.example.com", IPv6: []string{"2001:db8::1"}, Source: "hosts.json[1]"},
+	}
+	if !reflect.DeepEqual(hosts, want) {
+		t.Errorf("parseJSON() = %+v, want %+v", hosts, want)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	hosts, err := parseCSV(strings.NewReader(`hostname,ipv4,ipv6,aliases
+a.example.com,10.0.0.1,,www.example.com
+A.Example.com.,10.0.0.2,,
+b.example.com,10.0.0.1,10.0.0.3,
+c.example.com,,2001:DB8::1,www.example.com b
+`), "hosts.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, err := range Check(hosts) {
+		got = append(got, err.Error())
+	}
+	want := []string{
+		"hosts.csv:3: a.example.com is already defined (at hosts.csv:2)",
+		"hosts.csv:4: b.example.com: 10.0.0.1 is already used by a.example.com",
+		`hosts.csv:4: b.example.com: "10.0.0.3" is not an IPv6 address`,
+		"hosts.csv:5: www.example.com is already defined (at hosts.csv:2)",
+		`hosts.csv:5: "b" is not a fully qualified name`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check() = %q, want %q", got, want)
+	}
+	if hosts[3].IPv6[0] != "2001:db8::1" {
+		t.Errorf("Check() didn't normalize the address: %q", hosts[3].IPv6[0])
+	}
+}
+
+func TestRecords(t *testing.T) {
+	hosts := []Host{
+		{Name: "example.com", IPv4: []string{"192.0.2.1"}},
+		{Name: "web.sub.example.com", IPv4: []string{"192.0.2.70", "198.51.100.1"}, IPv6: []string{"2001:db8::1"}, Aliases: []string{"www.example.com", "www.example.net"}},
+	}
+	if errs := Check(hosts); len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	tests := []struct {
+		zone string
+		want []Record
+	}{
+		{"example.com", []Record{
+			{Type: "A", Name: "@", Target: "192.0.2.1"},
+			{Type: "A", Name: "web.sub", Target: "192.0.2.70"},
+			{Type: "A", Name: "web.sub", Target: "198.51.100.1"},
+			{Type: "AAAA", Name: "web.sub", Target: "2001:db8::1"},
+			{Type: "CNAME", Name: "www", Target: "web.sub.example.com."},
+		}},
+		{"example.net", []Record{
+			{Type: "CNAME", Name: "www", Target: "web.sub.example.com."},
+		}},
+		{"2.0.192.in-addr.arpa", []Record{
+			{Type: "PTR", Name: "1", Target: "example.com."},
+			{Type: "PTR", Name: "70", Target: "web.sub.example.com."},
+		}},
+		// RFC 4183 classless zone for 192.0.2.64/26.
+		{"64-26.2.0.192.in-addr.arpa", []Record{
+			{Type: "PTR", Name: "70", Target: "web.sub.example.com."},
+		}},
+		{"8.b.d.0.1.0.0.2.ip6.arpa", []Record{
+			{Type: "PTR", Name: "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0", Target: "web.sub.example.com."},
+		}},
+		{"example.org", nil},
+	}
+	for _, tt := range tests {
+		if got := Records(hosts, tt.zone); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Records(%s) = %+v, want %+v", tt.zone, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,379 @@
+package js
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/robertkrimen/otto"
+	"github.com/xddxdd/ottoext/fetch"
+	"github.com/xddxdd/ottoext/loop"
+)
+
+// FetchLockfile is the file that fetch() responses are recorded in and
+// replayed from. If empty, it is "fetch.lock.json" in the directory of
+// dnsconfig.js.
+var FetchLockfile string
+
+// RefreshFetch makes fetch() ignore the lockfile and fetch everything
+// again. The lockfile is then rewritten with only what was fetched.
+var RefreshFetch bool
+
+// FetchAllow lists the URL prefixes that fetch() may use. If empty,
+// any URL may be used.
+var FetchAllow []string
+
+// DefaultFetchLockfile is the name of the lockfile, if FetchLockfile
+// isn't set.
+const DefaultFetchLockfile = "fetch.lock.json"
+
+// fetchLock is the content of the lockfile.
+type fetchLock struct {
+	Version int            `json:"version"`
+	Fetches []*fetchRecord `json:"fetches"`
+}
+
+// fetchRecord is one response in the lockfile.
+type fetchRecord struct {
+	Method        string `json:"method"`
+	URL           string `json:"url"`
+	RequestSHA256 string `json:"request_sha256,omitempty"` // Of the request body, if there is one.
+	HeadersSHA256 string `json:"headers_sha256,omitempty"` // Of the request headers, if there are any.
+	Status        int    `json:"status"`
+	StatusText    string `json:"status_text"`
+	ContentType   string `json:"content_type,omitempty"`
+	SHA256        string `json:"sha256"` // Of Body.
+	Body          string `json:"body"`
+}
+
+func (r *fetchRecord) key() string {
+	return r.Method + " " + r.URL + " " + r.RequestSHA256 + " " + r.HeadersSHA256
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// headersSHA256 returns the sha256 of the request headers, sorted by
+// name, or "" if there are none. Only the hash goes in the lockfile,
+// since the headers may hold credentials.
+func headersSHA256(headers map[string]string) string {
+	if len(headers) == 0 {
+		return ""
+	}
+	lines := make([]string, 0, len(headers))
+	for k, v := range headers {
+		lines = append(lines, http.CanonicalHeaderKey(k)+": "+v+"\n")
+	}
+	sort.Strings(lines)
+	return sha256Hex(strings.Join(lines, ""))
+}
+
+// fetcher implements fetch(). Responses are replayed from the lockfile
+// when possible; only if live is true is the network used.
+type fetcher struct {
+	file    string // The lockfile. Empty if there is none.
+	live    bool
+	refresh bool
+	allow   []string
+
+	mu      sync.Mutex
+	records map[string]*fetchRecord
+	used    map[string]bool
+	changed bool
+}
+
+// newFetcher returns the fetcher for the configuration in filename
+// (which may be empty), or nil if fetch() is not available.
+func newFetcher(filename string) (*fetcher, error) {
+	f := &fetcher{
+		file:    FetchLockfile,
+		live:    EnableFetch,
+		refresh: RefreshFetch,
+		allow:   FetchAllow,
+		records: map[string]*fetchRecord{},
+		used:    map[string]bool{},
+	}
+	if f.file == "" && filename != "" {
+		f.file = filepath.Join(filepath.Dir(filename), DefaultFetchLockfile)
+	}
+	if f.refresh && !f.live {
+		return nil, errors.New("--refresh-fetch needs --allow-fetch")
+	}
+
+	if f.file != "" {
+		content, err := os.ReadFile(f.file)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			// Nothing has been recorded yet.
+		case err != nil:
+			return nil, err
+		default:
+			var lock fetchLock
+			if err := json.Unmarshal(content, &lock); err != nil {
+				return nil, fmt.Errorf("reading %s: %w", f.file, err)
+			}
+			for _, r := range lock.Fetches {
+				f.records[r.key()] = r
+			}
+		}
+	}
+
+	// Replaying doesn't need the network, so a lockfile is enough to
+	// enable fetch().
+	if !f.live && len(f.records) == 0 {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// define adds fetch() to the vm.
+func (f *fetcher) define(vm *otto.Otto, l *loop.Loop) error {
+	if err := fetch.Define(vm, l); err != nil {
+		return err
+	}
+	// Replace the function that fetch() (from ottoext) calls to do the
+	// actual request.
+	return vm.Set("__private__fetch_execute", func(call otto.FunctionCall) otto.Value {
+		req := call.Argument(0).Object()
+		t := &fetchTask{res: call.Argument(1).Object(), cb: call.Argument(2)}
+		l.Add(t)
+
+		method, url, body, headers, err := fetchRequest(req)
+		if err != nil {
+			t.err = err
+			go l.Ready(t)
+			return otto.UndefinedValue()
+		}
+		go func() {
+			defer l.Ready(t)
+			t.rec, t.err = f.do(method, url, body, headers)
+		}()
+		return otto.UndefinedValue()
+	})
+}
+
+// fetchRequest returns the parts of a JavaScript Request object.
+func fetchRequest(req *otto.Object) (method, url string, body *string, headers map[string]string, err error) {
+	get := func(o *otto.Object, name string) otto.Value {
+		if err != nil {
+			return otto.UndefinedValue()
+		}
+		var v otto.Value
+		v, err = o.Get(name)
+		return v
+	}
+
+	method = get(req, "method").String()
+	url = get(req, "url").String()
+	if b := get(req, "body"); b.IsString() {
+		s := b.String()
+		body = &s
+	}
+	headers = map[string]string{}
+	if h := get(req, "headers"); h.IsObject() {
+		if names := get(h.Object(), "_headers"); names.IsObject() {
+			for _, name := range names.Object().Keys() {
+				v, err := h.Object().Call("get", name)
+				if err == nil && !v.IsNull() && !v.IsUndefined() {
+					headers[name] = v.String()
+				}
+			}
+		}
+	}
+	return method, url, body, headers, err
+}
+
+// do returns the response to a request, from the lockfile or from the
+// network.
+func (f *fetcher) do(method, url string, body *string, headers map[string]string) (*fetchRecord, error) {
+	if !f.allowed(url) {
+		return nil, fmt.Errorf("fetch %s: not allowed by --fetch-allow", url)
+	}
+	want := &fetchRecord{Method: method, URL: url, HeadersSHA256: headersSHA256(headers)}
+	if body != nil {
+		want.RequestSHA256 = sha256Hex(*body)
+	}
+
+	f.mu.Lock()
+	rec, ok := f.records[want.key()]
+	if ok && !f.refresh {
+		f.used[want.key()] = true
+		f.mu.Unlock()
+		if sha256Hex(rec.Body) != rec.SHA256 {
+			return nil, fmt.Errorf("fetch %s: the response in %s doesn't match its sha256", url, f.file)
+		}
+		return rec, nil
+	}
+	f.mu.Unlock()
+
+	if !f.live {
+		return nil, fmt.Errorf("fetch %s %s: not in %s. Use --allow-fetch to fetch it", method, url, f.file)
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = strings.NewReader(*body)
+	}
+	req, err := http.NewRequest(method, url, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	client := &http.Client{CheckRedirect: f.checkRedirect}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	content, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only what a dnsconfig.js can depend on is recorded. The request
+	// headers are only hashed, since they may hold credentials.
+	rec = want
+	rec.Status = res.StatusCode
+	rec.StatusText = res.Status
+	rec.ContentType = res.Header.Get("Content-Type")
+	rec.Body = string(content)
+	rec.SHA256 = sha256Hex(rec.Body)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if old, ok := f.records[rec.key()]; !ok || *old != *rec {
+		f.changed = true
+	}
+	f.records[rec.key()] = rec
+	f.used[rec.key()] = true
+	return rec, nil
+}
+
+// allowed returns true if --fetch-allow permits rawURL. The scheme and
+// host (with the port) must be those of a prefix, and the path must be
+// the prefix's path or below it: "https://example.com/api" allows
+// neither "https://example.com.evil.net/api" nor
+// "https://example.com/api-keys".
+func (f *fetcher) allowed(rawURL string) bool {
+	if len(f.allow) == 0 {
+		return true
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Opaque != "" {
+		return false
+	}
+	p := path.Clean("/" + u.Path)
+	for _, prefix := range f.allow {
+		a, err := url.Parse(prefix)
+		if err != nil {
+			continue
+		}
+		if !strings.EqualFold(u.Scheme, a.Scheme) || !strings.EqualFold(u.Host, a.Host) {
+			continue
+		}
+		dir := strings.TrimSuffix(a.Path, "/")
+		if dir == "" || p == dir || strings.HasPrefix(p, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRedirect keeps redirects within --fetch-allow.
+func (f *fetcher) checkRedirect(req *http.Request, via []*http.Request) error {
+	if !f.allowed(req.URL.String()) {
+		return fmt.Errorf("redirect to %s: not allowed by --fetch-allow", req.URL)
+	}
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	return nil
+}
+
+// save writes the lockfile, if anything was fetched from the network
+// that isn't in it.
+func (f *fetcher) save() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == "" || (!f.changed && !(f.refresh && len(f.used) != len(f.records))) {
+		return nil
+	}
+
+	lock := fetchLock{Version: 1, Fetches: []*fetchRecord{}}
+	for key, r := range f.records {
+		// Refreshing drops what is no longer used.
+		if f.refresh && !f.used[key] {
+			continue
+		}
+		lock.Fetches = append(lock.Fetches, r)
+	}
+	sort.Slice(lock.Fetches, func(i, j int) bool {
+		return lock.Fetches[i].key() < lock.Fetches[j].key()
+	})
+	content, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(f.file, append(content, '\n'), 0o644)
+}
+
+// fetchTask passes a response to the callback of fetch() on the
+// JavaScript side.
+type fetchTask struct {
+	id  int64
+	res *otto.Object
+	cb  otto.Value
+	rec *fetchRecord
+	err error
+}
+
+func (t *fetchTask) SetID(id int64) { t.id = id }
+func (t *fetchTask) GetID() int64   { return t.id }
+func (t *fetchTask) Cancel()        {}
+
+func (t *fetchTask) Execute(vm *otto.Otto, l *loop.Loop) error {
+	if t.err != nil {
+		e, err := vm.Call(`new Error`, nil, t.err.Error())
+		if err != nil {
+			return err
+		}
+		_, err = t.cb.Call(otto.NullValue(), e)
+		return err
+	}
+
+	if err := t.res.Set("status", t.rec.Status); err != nil {
+		return err
+	}
+	if err := t.res.Set("statusText", t.rec.StatusText); err != nil {
+		return err
+	}
+	if t.rec.ContentType != "" {
+		h, err := t.res.Get("headers")
+		if err != nil {
+			return err
+		}
+		if _, err := h.Object().Call("append", "Content-Type", t.rec.ContentType); err != nil {
+			return err
+		}
+	}
+	if err := t.res.Set("_body", t.rec.Body); err != nil {
+		return err
+	}
+	_, err := t.cb.Call(otto.NullValue())
+	return err
+}
@@ -0,0 +1,190 @@
+package js
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFetchLockfile(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, "192.0.2.7")
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "dnsconfig.js")
+	// fetch(), not FETCH(), so that errors don't exit.
+	script := fmt.Sprintf(`
+D("example.com", NewRegistrar("none"));
+fetch(%q).then(function(r) { return r.text(); }).then(function(t) {
+  D_EXTEND("example.com", A("www", t));
+}).catch(function(e) {
+  D_EXTEND("example.com", TXT("@", e.message));
+});
+`, server.URL+"/ip")
+	if err := os.WriteFile(file, []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	lockfile := filepath.Join(dir, DefaultFetchLockfile)
+
+	run := func(live, refresh bool, allow ...string) (string, error) {
+		t.Helper()
+		EnableFetch, RefreshFetch, FetchAllow = live, refresh, allow
+		defer func() { EnableFetch, RefreshFetch, FetchAllow = false, false, nil }()
+		conf, err := ExecuteJavaScript(file, true, nil)
+		if err != nil {
+			return "", err
+		}
+		rec := conf.Domains[0].Records[0]
+		if rec.Type == "TXT" {
+			return "", errors.New(rec.GetTargetTXTJoined())
+		}
+		return rec.GetTargetField(), nil
+	}
+
+	// Without --allow-fetch or a lockfile, there is no fetch().
+	if _, err := run(false, false); err == nil {
+		t.Fatal("expected an error without --allow-fetch")
+	}
+
+	// The first run records the response.
+	if got, err := run(true, false); err != nil || got != "192.0.2.7" {
+		t.Fatalf("got %q, %v", got, err)
+	}
+	content, err := os.ReadFile(lockfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `"sha256": "`) {
+		t.Errorf("lockfile has no hash:\n%s", content)
+	}
+
+	// Later runs replay it, with or without --allow-fetch.
+	for _, live := range []bool{true, false} {
+		if got, err := run(live, false); err != nil || got != "192.0.2.7" {
+			t.Fatalf("live=%v: got %q, %v", live, got, err)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("the server was called %d times, want 1", hits)
+	}
+
+	// Unless the responses are refreshed.
+	if _, err := run(false, true); err == nil {
+		t.Error("expected an error for --refresh-fetch without --allow-fetch")
+	}
+	if _, err := run(true, true); err != nil {
+		t.Fatal(err)
+	}
+	if hits != 2 {
+		t.Errorf("the server was called %d times, want 2", hits)
+	}
+
+	// The allowlist applies to every fetch.
+	if _, err := run(true, false, "https://example.com/"); err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("expected a --fetch-allow error, got %v", err)
+	}
+	if _, err := run(true, false, server.URL+"/"); err != nil {
+		t.Error(err)
+	}
+
+	// A lockfile that was edited is refused.
+	edited := strings.Replace(string(content), "192.0.2.7", "192.0.2.8", 1)
+	if err := os.WriteFile(lockfile, []byte(edited), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run(false, false); err == nil || !strings.Contains(err.Error(), "sha256") {
+		t.Errorf("expected a sha256 error, got %v", err)
+	}
+}
+
+func TestFetchAllowed(t *testing.T) {
+	f := &fetcher{allow: []string{"https://example.com/api", "http://192.0.2.1:8080/"}}
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com/api", true},
+		{"https://example.com/api/v1?q=1", true},
+		{"https://EXAMPLE.com/api/", true},
+		{"https://example.com/api-keys", false},
+		{"https://example.com/api/../admin", false},
+		{"https://example.com/", false},
+		{"https://example.com.evil.net/api", false},
+		{"https://example.com@evil.net/api", false},
+		{"http://example.com/api", false},
+		{"http://192.0.2.1:8080/anything", true},
+		{"http://192.0.2.1/anything", false},
+		{"http://192.0.2.1:8081/anything", false},
+		{"not a url", false},
+	}
+	for _, tt := range tests {
+		if got := f.allowed(tt.url); got != tt.want {
+			t.Errorf("allowed(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestFetchRedirect(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "secret")
+	}))
+	defer other.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/local" {
+			fmt.Fprint(w, "192.0.2.7")
+			return
+		}
+		http.Redirect(w, r, r.URL.Query().Get("to"), http.StatusFound)
+	}))
+	defer server.Close()
+
+	f := &fetcher{live: true, allow: []string{server.URL + "/"}, records: map[string]*fetchRecord{}, used: map[string]bool{}}
+	rec, err := f.do("GET", server.URL+"/redirect?to=/local", nil, nil)
+	if err != nil || rec.Body != "192.0.2.7" {
+		t.Errorf("redirect within --fetch-allow: got %v, %v", rec, err)
+	}
+	if _, err := f.do("GET", server.URL+"/redirect?to="+other.URL, nil, nil); err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("expected a --fetch-allow error for a redirect elsewhere, got %v", err)
+	}
+}
+
+func TestFetchHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Header.Get("X-View"))
+	}))
+	defer server.Close()
+
+	f := &fetcher{live: true, records: map[string]*fetchRecord{}, used: map[string]bool{}}
+	for _, view := range []string{"internal", "external"} {
+		if _, err := f.do("GET", server.URL, nil, map[string]string{"x-view": view, "Authorization": "secret"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Requests that only differ by their headers get their own responses.
+	f.live = false
+	for _, view := range []string{"internal", "external"} {
+		rec, err := f.do("GET", server.URL, nil, map[string]string{"X-View": view, "Authorization": "secret"})
+		if err != nil || rec.Body != view {
+			t.Errorf("replay with X-View: %s = %v, %v", view, rec, err)
+		}
+	}
+	if _, err := f.do("GET", server.URL, nil, nil); err == nil {
+		t.Error("a request without the headers was replayed")
+	}
+	for _, rec := range f.records {
+		if rec.HeadersSHA256 == "" || strings.Contains(fmt.Sprint(*rec), "secret") {
+			t.Errorf("record %+v: want the headers hashed", *rec)
+		}
+	}
+}
@@ -112,6 +112,7 @@ function newDomain(name, registrar) {
         ignored_names: [],
         ignored_targets: [],
         unmanaged: [],
+        downgrades: [],
     };
 }
 
@@ -230,6 +231,12 @@ function TTL(v) {
     };
 }
 
+// COMMENT(text): Attach a comment to a DNS record, for providers that
+// can store it.
+function COMMENT(text) {
+    return { comment: String(text) };
+}
+
 function stringToDuration(v) {
     var matches = v.match(/^(\d+)([smhdwny]?)$/);
     if (matches == null) {
@@ -1102,6 +1109,99 @@ function AUTODNSSEC(d) {
     );
 }
 
+// ============================================================
+
+// Domain modifiers that translate or generate records:
+
+// DOWNGRADE(rtype, action, arg): What to do with rtype records at
+// DNS providers that don't support them.
+//   DOWNGRADE("ALIAS", "RESOLVE", ttl)  (ttl is optional)
+//   DOWNGRADE("HTTPS", "DROP")
+//   DOWNGRADE("HTTPS", "MAP", "SVCB")
+function DOWNGRADE(rtype, action, arg) {
+    var dg = { rtype: rtype, action: String(action).toUpperCase() };
+    if (dg.action === 'MAP') {
+        dg.map_to = arg;
+    } else if (dg.action === 'RESOLVE' && arg !== undefined) {
+        if (_.isString(arg)) {
+            arg = stringToDuration(arg);
+        }
+        dg.ttl = arg;
+    }
+    return function (d) {
+        d.downgrades.push(dg);
+    };
+}
+
+// INVENTORY_RECORDS(hosts, modifiers...): Add the records of the
+// hosts (as returned by INVENTORY()) that belong in the domain: A, AAAA
+// and CNAME records in forward zones, PTR records in reverse zones.
+// The modifiers are applied to each record.
+function INVENTORY_RECORDS(hosts) {
+    var modifiers = Array.prototype.slice.call(arguments, 1);
+    return function (d) {
+        var zone = d.subdomain ? d.subdomain + '.' + d.name : d.name;
+        var builders = { A: A, AAAA: AAAA, CNAME: CNAME, PTR: PTR };
+        var recs = inventoryRecords(hosts || [], zone) || [];
+        for (var i = 0; i < recs.length; i++) {
+            var r = recs[i];
+            var args = [r.name, r.target].concat(modifiers);
+            if (r.source) {
+                // Errors about the record refer to the inventory.
+                args.push(
+                    (function (source) {
+                        return function (rec) {
+                            rec.source = source;
+                        };
+                    })(r.source)
+                );
+            }
+            builders[r.type].apply(null, args)(d);
+        }
+    };
+}
+
+// RFC2317_BUILDER(value): Add the records of a classless reverse
+// delegation (RFC 2317) that belong in the domain. value is an object:
+// block: The block, from /25 to /31 (e.g. '192.0.2.64/26')
+// nameservers: The nameservers of the child zone
+// ptrs: The PTR records of the child zone, address to hostname (optional)
+// ttl: The TTL of the records (optional)
+// In the parent zone (the /24), it adds the NS records of the child
+// zone and a CNAME for each address; in the child zone (REV(block)), it
+// adds the PTR records.
+function RFC2317_BUILDER(value) {
+    var RFC2317_TTL = function () {};
+    if (value.ttl) {
+        RFC2317_TTL = TTL(value.ttl);
+    }
+    var delegation = {
+        block: value.block,
+        nameservers: value.nameservers || [],
+        ptrs: value.ptrs || {},
+    };
+    return function (d) {
+        var zone = d.subdomain ? d.subdomain + '.' + d.name : d.name;
+        var builders = { NS: NS, CNAME: CNAME, PTR: PTR };
+        var recs = rfc2317Records(delegation, zone) || [];
+        for (var i = 0; i < recs.length; i++) {
+            var r = recs[i];
+            builders[r.type](r.name, r.target, RFC2317_TTL)(d);
+        }
+    };
+}
+
+// AUTOPTR(policy): Generate the PTR records of this reverse domain from
+// the A and AAAA records of the forward domains. policy says what to do
+// when several names claim an address: 'error' (the default), 'first',
+// 'all' or 'skip'.
+function AUTOPTR(policy) {
+    var p = policy === undefined ? 'error' : String(policy).toLowerCase();
+    return function (d) {
+        d.autoptr = p;
+    };
+}
+
 /**
  * @deprecated
  */
@@ -1160,6 +1260,7 @@ function recordBuilder(type, opts) {
     return function () {
         var parsedArgs = {};
         var modifiers = [];
+        var source = srcLocation();
 
         if (arguments.length < opts.args.length) {
             var argumentsList = opts.args
@@ -1209,6 +1310,9 @@ function recordBuilder(type, opts) {
                 meta: {},
                 ttl: d.defaultTTL,
             };
+            if (source) {
+                record.source = source;
+            }
 
             opts.applyModifier(record, modifiers);
             opts.transform(record, parsedArgs, modifiers);
@@ -2055,6 +2159,7 @@ var DISABLE_REPEATED_DOMAIN_CHECK = { skip_fqdn_check: 'true' };
 
 function rawrecordBuilder(type) {
     return function () {
+        var source = srcLocation();
         // Copy the raw args:
         var rawArgs = [];
         for (var i = 0; i < arguments.length; i++) {
@@ -2065,6 +2170,9 @@ function rawrecordBuilder(type) {
             var record = {
                 type: type,
             };
+            if (source) {
+                record.source = source;
+            }
 
             // Process the args: Functions are executed, objects are assumed to
             // be meta and stored, strings are assumed to be args and are
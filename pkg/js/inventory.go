@@ -0,0 +1,64 @@
+package js
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/StackExchange/dnscontrol/v4/pkg/inventory"
+	"github.com/robertkrimen/otto"
+)
+
+// readInventory implements INVENTORY(file): it returns the checked
+// hosts of an inventory file, relative to the directory of the file
+// being executed.
+func readInventory(call otto.FunctionCall) otto.Value {
+	if len(call.ArgumentList) != 1 {
+		throw(call.Otto, "INVENTORY takes exactly one argument")
+	}
+	file := call.Argument(0).String()
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(currentDirectory, file)
+	}
+
+	hosts, err := inventory.Load(file)
+	if err != nil {
+		throw(call.Otto, fmt.Sprintf("INVENTORY: %s", err))
+	}
+	if errs := inventory.Check(hosts); len(errs) != 0 {
+		throw(call.Otto, fmt.Sprintf("INVENTORY: %s", errors.Join(errs...)))
+	}
+	value, err := toValue(call.Otto, hosts)
+	if err != nil {
+		throw(call.Otto, fmt.Sprintf("INVENTORY: %s", err))
+	}
+	return value
+}
+
+// inventoryRecords returns the records that the hosts (the first
+// argument) need in the zone (the second argument). It is used by
+// INVENTORY_RECORDS().
+func inventoryRecords(call otto.FunctionCall) otto.Value {
+	if len(call.ArgumentList) != 2 {
+		throw(call.Otto, "inventoryRecords takes exactly two arguments")
+	}
+	data, err := call.Otto.Call("JSON.stringify", nil, call.Argument(0))
+	if err != nil {
+		throw(call.Otto, err.Error())
+	}
+	var hosts []inventory.Host
+	if err := json.Unmarshal([]byte(data.String()), &hosts); err != nil {
+		throw(call.Otto, fmt.Sprintf("INVENTORY_RECORDS: the hosts must be a list of {hostname, ipv4, ipv6, aliases}: %s", err))
+	}
+	// The hosts may not come from INVENTORY().
+	if errs := inventory.Check(hosts); len(errs) != 0 {
+		throw(call.Otto, fmt.Sprintf("INVENTORY_RECORDS: %s", errors.Join(errs...)))
+	}
+
+	value, err := toValue(call.Otto, inventory.Records(hosts, call.Argument(1).String()))
+	if err != nil {
+		throw(call.Otto, err.Error())
+	}
+	return value
+}
@@ -7,6 +7,8 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/StackExchange/dnscontrol/v4/models"
@@ -15,7 +17,6 @@ import (
 	"github.com/StackExchange/dnscontrol/v4/pkg/transform"
 	"github.com/robertkrimen/otto"              // load underscore js into vm by default
 	_ "github.com/robertkrimen/otto/underscore" // required by otto
-	"github.com/xddxdd/ottoext/fetch"
 	"github.com/xddxdd/ottoext/loop"
 	"github.com/xddxdd/ottoext/promise"
 	"github.com/xddxdd/ottoext/timers"
@@ -25,6 +26,9 @@ import (
 var helpersJsStatic string
 var helpersJsFileName = "pkg/js/helpers.js"
 
+// helpersJsName is the filename of helpers.js in stack traces.
+const helpersJsName = "helpers.js"
+
 // currentDirectory is the current directory as used by require().
 // This is used to emulate nodejs-style require() directory handling.
 // If require("a/b/c.js") is called, any require() statement in c.js
@@ -37,7 +41,9 @@ var currentDirectory string
 var EnableFetch bool = false
 
 // ExecuteJavaScript accepts a javascript file and runs it, returning the resulting dnsConfig.
-func ExecuteJavaScript(file string, devMode bool, variables map[string]string) (*models.DNSConfig, error) {
+// Each of the variables is defined before the file is run. Their values
+// may be strings or, as decoded by ReadVariables, structured data.
+func ExecuteJavaScript(file string, devMode bool, variables map[string]interface{}) (*models.DNSConfig, error) {
 	script, err := os.ReadFile(file)
 	if err != nil {
 		return nil, err
@@ -46,11 +52,51 @@ func ExecuteJavaScript(file string, devMode bool, variables map[string]string) (
 	// Record the directory path leading up to this file.
 	currentDirectory = filepath.Dir(file)
 
-	return ExecuteJavascriptString(script, devMode, variables)
+	return executeJavascript(script, file, devMode, variables)
 }
 
 // ExecuteJavascriptString accepts a string containing javascript and runs it, returning the resulting dnsConfig.
-func ExecuteJavascriptString(script []byte, devMode bool, variables map[string]string) (*models.DNSConfig, error) {
+func ExecuteJavascriptString(script []byte, devMode bool, variables map[string]interface{}) (*models.DNSConfig, error) {
+	return executeJavascript(script, "", devMode, variables)
+}
+
+// functions are the Go functions that scripts can call.
+var functions = map[string]interface{}{
+	"require":   require,
+	"REV":       reverse,
+	"REVCOMPAT": reverseCompat,
+	"glob":      listFiles, // used for require_glob()
+	"PANIC":     jsPanic,
+	"HASH":      hashFunc,
+	"INVENTORY": readInventory,
+	"READ_JSON": readJSON,
+	"READ_YAML": readYAML,
+
+	"srcLocation":      srcLocation,      // used by the record builders
+	"inventoryRecords": inventoryRecords, // used by INVENTORY_RECORDS()
+	"rfc2317Records":   rfc2317Records,   // used by RFC2317_BUILDER()
+}
+
+// Builtins returns the names that scripts can use besides those of
+// JavaScript itself and of helpers.js, sorted.
+func Builtins() []string {
+	names := []string{
+		"_", // otto/underscore
+		// ottoext:
+		"Promise", "clearInterval", "clearTimeout", "setInterval", "setTimeout",
+		// Only defined with --fetch-allow, but a script may use it anyway.
+		"fetch",
+	}
+	for name := range functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// executeJavascript runs script. filename (if not empty) is used to
+// report where each record was defined.
+func executeJavascript(script []byte, filename string, devMode bool, variables map[string]interface{}) (*models.DNSConfig, error) {
 	vm := otto.New()
 	l := loop.New(vm)
 
@@ -61,22 +107,19 @@ func ExecuteJavascriptString(script []byte, devMode bool, variables map[string]s
 		return nil, err
 	}
 
-	// only define fetch() when explicitly enabled
-	if EnableFetch {
-		if err := fetch.Define(vm, l); err != nil {
+	// only define fetch() when explicitly enabled, or when there are
+	// responses to replay
+	fetcher, err := newFetcher(filename)
+	if err != nil {
+		return nil, err
+	}
+	if fetcher != nil {
+		if err := fetcher.define(vm, l); err != nil {
 			return nil, err
 		}
 	}
 
 	// add functions to otto
-	functions := map[string]interface{}{
-		"require":   require,
-		"REV":       reverse,
-		"REVCOMPAT": reverseCompat,
-		"glob":      listFiles, // used for require_glob()
-		"PANIC":     jsPanic,
-		"HASH":      hashFunc,
-	}
 	for name, fn := range functions {
 		if err := vm.Set(name, fn); err != nil {
 			return nil, err
@@ -85,19 +128,30 @@ func ExecuteJavascriptString(script []byte, devMode bool, variables map[string]s
 
 	// add cli variables to otto
 	for key, value := range variables {
-		if err := vm.Set(key, value); err != nil {
+		v, err := toValue(vm, value)
+		if err != nil {
+			return nil, fmt.Errorf("variable %s: %w", key, err)
+		}
+		if err := vm.Set(key, v); err != nil {
 			return nil, err
 		}
 	}
 
-	helperJs := GetHelpers(devMode)
+	helperJs, err := vm.Compile(helpersJsName, GetHelpers(devMode))
+	if err != nil {
+		return nil, err
+	}
 	// run helper script to prime vm and initialize variables
 	if err := l.Eval(helperJs); err != nil {
 		return nil, err
 	}
 
 	// run user script
-	if err := l.Eval(script); err != nil {
+	userJs, err := vm.Compile(filename, script)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.Eval(userJs); err != nil {
 		return nil, err
 	}
 
@@ -105,6 +159,11 @@ func ExecuteJavascriptString(script []byte, devMode bool, variables map[string]s
 	if err := l.Run(); err != nil {
 		return nil, err
 	}
+	if fetcher != nil {
+		if err := fetcher.save(); err != nil {
+			return nil, err
+		}
+	}
 
 	// export conf as string and unmarshal
 	value, err := vm.Run(`JSON.stringify(conf)`)
@@ -171,7 +230,11 @@ func require(call otto.FunctionCall) otto.Value {
 		cmd := fmt.Sprintf(`JSON.parse(JSON.stringify(%s))`, string(data))
 		value, err = call.Otto.Run(cmd)
 	} else {
-		_, err = call.Otto.Run(string(data))
+		var script *otto.Script
+		script, err = call.Otto.Compile(relFile, data)
+		if err == nil {
+			_, err = call.Otto.Run(script)
+		}
 	}
 
 	if err != nil {
@@ -315,3 +378,26 @@ func reverseCompat(call otto.FunctionCall) otto.Value {
 	v, _ := otto.ToValue(nil)
 	return v
 }
+
+// stackFrameRe matches a frame of an otto stack trace: "callee (file:line:col)"
+// or "file:line:col".
+var stackFrameRe = regexp.MustCompile(`^(?:.* \()?([^()]+):(\d+):\d+\)?$`)
+
+// srcLocation returns the "file:line" of the innermost caller that is
+// not in helpers.js, or "" if that isn't known. The record builders
+// call it so that each record can say where it was defined.
+func srcLocation(call otto.FunctionCall) otto.Value {
+	loc := ""
+	for _, frame := range call.Otto.ContextLimit(20).Stacktrace {
+		m := stackFrameRe.FindStringSubmatch(frame)
+		if m == nil || m[1] == helpersJsName {
+			continue
+		}
+		if m[1] != "<anonymous>" {
+			loc = m[1] + ":" + m[2]
+		}
+		break
+	}
+	v, _ := otto.ToValue(loc)
+	return v
+}
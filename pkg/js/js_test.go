@@ -61,6 +61,13 @@ func TestParsedFiles(t *testing.T) {
 			for _, dc := range conf.Domains {
 				// fmt.Printf("DEBUG: PrettySort: domain=%q #rec=%d\n", dc.Name, len(dc.Records))
 				// fmt.Printf("DEBUG: records = %d %v\n", len(dc.Records), dc.Records)
+				// Where each record was defined is tested in TestSourceLocations.
+				for _, rec := range append(dc.Records, dc.EnsureAbsent...) {
+					rec.Source = ""
+				}
+				for i := range dc.RawRecords {
+					dc.RawRecords[i].Source = ""
+				}
 				ps := prettyzone.PrettySort(dc.Records, dc.Name, 0, nil)
 				dc.Records = ps.Records
 				if len(dc.Records) == 0 {
@@ -167,3 +174,109 @@ func TestErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestSourceLocations(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.js": `var REG = NewRegistrar("none");
+require("./inc/more.js");
+D("example.com", REG,
+    A("@", "1.2.3.4"),
+    MORE,
+    SPF_BUILDER({label: "@", parts: ["v=spf1", "-all"]}),
+    CF_SINGLE_REDIRECT("name", 301, "when", "then")
+);
+`,
+		"inc/more.js": `var MORE = [
+    MX("@", 10, "mx.example.com."),
+];
+`,
+	}
+	for name, content := range files {
+		fn := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(fn), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fn, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conf, err := ExecuteJavaScript(filepath.Join(dir, "main.js"), true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dc := conf.Domains[0]
+	var got []string
+	for _, rec := range dc.Records {
+		got = append(got, rec.Type+" "+rec.Source)
+	}
+	for _, rec := range dc.RawRecords {
+		got = append(got, rec.Type+" "+rec.Source)
+	}
+	want := []string{
+		"A " + filepath.Join(dir, "main.js") + ":4",
+		"MX " + filepath.Join(dir, "inc/more.js") + ":2",
+		"TXT " + filepath.Join(dir, "main.js") + ":6",
+		"CLOUDFLAREAPI_SINGLE_REDIRECT " + filepath.Join(dir, "main.js") + ":7",
+	}
+	testifyrequire.Equal(t, want, got)
+
+	// Without a filename, the location is unknown.
+	conf, err = ExecuteJavascriptString([]byte(`D("example.com", "none", A("@", "1.2.3.4"))`), true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src := conf.Domains[0].Records[0].Source; src != "" {
+		t.Errorf("Source = %q, want empty", src)
+	}
+}
+
+func TestReadDataFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"vars.yaml": "prefix: web\nweights: {a: 1}\n",
+		"main.js": `require("./inc/hosts.js");
+D("example.com", "none", HOSTS, A(prefix + weights.a, "10.0.0.1"), A(site, "10.0.0.2"));
+`,
+		"inc/hosts.js": `var HOSTS = [];
+var hosts = READ_JSON("hosts.json");
+var extra = READ_YAML("../extra.yml");
+hosts.concat(extra).forEach(function (h) { HOSTS.push(A(h.name, h.ip)); });
+`,
+		"inc/hosts.json": `[{"name": "db", "ip": "10.0.1.1"}]`,
+		"extra.yml":      "- name: cache\n  ip: 10.0.1.2\n",
+	}
+	for name, content := range files {
+		fn := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(fn), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fn, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	variables, err := ReadVariables(filepath.Join(dir, "vars.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	variables["site"] = "www"
+	conf, err := ExecuteJavaScript(filepath.Join(dir, "main.js"), true, variables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, rec := range conf.Domains[0].Records {
+		got = append(got, rec.GetLabel()+" "+rec.GetTargetField())
+	}
+	want := []string{"db 10.0.1.1", "cache 10.0.1.2", "web1 10.0.0.1", "www 10.0.0.2"}
+	testifyrequire.Equal(t, want, got)
+
+	if err := os.WriteFile(filepath.Join(dir, "list.json"), []byte(`[1, 2]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadVariables(filepath.Join(dir, "list.json")); err == nil {
+		t.Error("ReadVariables() of a list succeeded, want an error")
+	}
+}
@@ -0,0 +1,6 @@
+D("foo.com", "none",
+    DOWNGRADE("ALIAS", "resolve", "5m"),
+    DOWNGRADE("HTTPS", "DROP"),
+    DOWNGRADE("SVCB", "MAP", "HTTPS"),
+    ALIAS("@", "foo.example.net."),
+);
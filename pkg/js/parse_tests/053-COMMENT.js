@@ -0,0 +1,4 @@
+D("foo.com", "none",
+  A("@", "1.2.3.4", COMMENT("load balancer")),
+  MX("@", 10, "mx.foo.com.", COMMENT("primary"), TTL(300)),
+);
@@ -0,0 +1,14 @@
+var HOSTS = INVENTORY("054-inventory.csv");
+
+D("foo.com", "none",
+    INVENTORY_RECORDS(HOSTS, TTL(600)),
+);
+D("bar.com", "none",
+    INVENTORY_RECORDS(HOSTS),
+);
+D(REV("10.1.2.0/24"), "none",
+    INVENTORY_RECORDS(HOSTS),
+);
+D(REV("2001:db8::/32"), "none",
+    INVENTORY_RECORDS(HOSTS),
+);
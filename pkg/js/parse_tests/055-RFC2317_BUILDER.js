@@ -0,0 +1,16 @@
+var CUSTOMER = RFC2317_BUILDER({
+    block: "192.0.2.64/30",
+    nameservers: ["ns1.customer.example", "ns2.customer.example"],
+    ptrs: {
+        "192.0.2.65": "router.customer.example",
+        "192.0.2.66": "www.customer.example",
+    },
+    ttl: 3600,
+});
+
+D(REV("192.0.2.0/24"), "none",
+    CUSTOMER,
+);
+D(REV("192.0.2.64/30"), "none",
+    CUSTOMER,
+);
@@ -0,0 +1,6 @@
+D(REV("192.0.2.0/24"), "none",
+    AUTOPTR(),
+);
+D(REV("2001:db8::/32"), "none",
+    AUTOPTR("First"),
+);
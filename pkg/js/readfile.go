@@ -0,0 +1,96 @@
+package js
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/robertkrimen/otto"
+	"gopkg.in/yaml.v3"
+)
+
+// ReadVariables reads a --vars-file. The file must be a JSON or YAML
+// (if the name ends in .yaml or .yml) object. Each of its keys becomes
+// a variable in the JavaScript VM, with the value as a JavaScript
+// object, array, string, number or boolean.
+func ReadVariables(file string) (map[string]interface{}, error) {
+	ext := strings.ToLower(filepath.Ext(file))
+	v, err := readDataFile(file, ext == ".yaml" || ext == ".yml")
+	if err != nil {
+		return nil, err
+	}
+	variables, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: must contain an object (name: value, ...)", file)
+	}
+	return variables, nil
+}
+
+// readDataFile reads and decodes a JSON file, or a YAML file if
+// isYAML is true.
+func readDataFile(file string, isYAML bool) (interface{}, error) {
+	data, err := os.ReadFile(filepath.ToSlash(file))
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if isYAML {
+		err = yaml.Unmarshal(data, &v)
+	} else {
+		err = json.Unmarshal(data, &v)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(file), err)
+	}
+	return v, nil
+}
+
+// toValue converts v (as decoded by encoding/json or yaml.v3) to a
+// plain JavaScript value. vm.ToValue() would wrap maps and slices in Go
+// objects, which JSON.stringify() and the helpers.js functions don't
+// treat like JavaScript objects and arrays.
+func toValue(vm *otto.Otto, v interface{}) (otto.Value, error) {
+	if s, ok := v.(string); ok {
+		return vm.ToValue(s)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return otto.UndefinedValue(), err
+	}
+	return vm.Call("JSON.parse", nil, string(data))
+}
+
+// readJSON implements READ_JSON(file).
+func readJSON(call otto.FunctionCall) otto.Value {
+	return readData(call, "READ_JSON", false)
+}
+
+// readYAML implements READ_YAML(file).
+func readYAML(call otto.FunctionCall) otto.Value {
+	return readData(call, "READ_YAML", true)
+}
+
+// readData reads the file named in the only argument of the call,
+// relative to the directory of the file being executed (as require()
+// does), and returns its contents as a JavaScript value.
+func readData(call otto.FunctionCall, name string, isYAML bool) otto.Value {
+	if len(call.ArgumentList) != 1 {
+		throw(call.Otto, name+" takes exactly one argument")
+	}
+	file := call.Argument(0).String()
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(currentDirectory, file)
+	}
+
+	v, err := readDataFile(file, isYAML)
+	if err != nil {
+		throw(call.Otto, fmt.Sprintf("%s: %s", name, err))
+	}
+	value, err := toValue(call.Otto, v)
+	if err != nil {
+		throw(call.Otto, fmt.Sprintf("%s: %s: %s", name, filepath.Base(file), err))
+	}
+	return value
+}
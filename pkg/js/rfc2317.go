@@ -0,0 +1,40 @@
+package js
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/StackExchange/dnscontrol/v4/pkg/rfc2317"
+	"github.com/robertkrimen/otto"
+)
+
+// rfc2317Records returns the records that the classless delegation (the
+// first argument) needs in the zone (the second argument). It is used by
+// RFC2317_BUILDER().
+func rfc2317Records(call otto.FunctionCall) otto.Value {
+	if len(call.ArgumentList) != 2 {
+		throw(call.Otto, "rfc2317Records takes exactly two arguments")
+	}
+	data, err := call.Otto.Call("JSON.stringify", nil, call.Argument(0))
+	if err != nil {
+		throw(call.Otto, err.Error())
+	}
+	var d rfc2317.Delegation
+	if err := json.Unmarshal([]byte(data.String()), &d); err != nil {
+		throw(call.Otto, fmt.Sprintf("RFC2317_BUILDER: the argument must be {block, nameservers, ptrs}: %s", err))
+	}
+	if errs := d.Check(); len(errs) != 0 {
+		throw(call.Otto, fmt.Sprintf("RFC2317_BUILDER: %s", errors.Join(errs...)))
+	}
+
+	recs, err := rfc2317.Records(d, call.Argument(1).String())
+	if err != nil {
+		throw(call.Otto, fmt.Sprintf("RFC2317_BUILDER: %s", err))
+	}
+	value, err := toValue(call.Otto, recs)
+	if err != nil {
+		throw(call.Otto, err.Error())
+	}
+	return value
+}
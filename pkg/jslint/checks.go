@@ -0,0 +1,213 @@
+package jslint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/robertkrimen/otto/ast"
+)
+
+// checkNames reports deprecated and misspelled names.
+func (l *linter) checkNames(s *source) {
+	handled := map[ast.Node]bool{}
+	walk(s.prog, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpression:
+			if n == nil {
+				return false
+			}
+			l.checkProviderType(s, n)
+			// A deprecated no-op that is passed to a function can simply
+			// be removed.
+			for i, arg := range n.ArgumentList {
+				id, ok := arg.(*ast.Identifier)
+				if !ok || id == nil || !deprecations[id.Name].remove || l.declared[id.Name] {
+					continue
+				}
+				l.report(s, id, "deprecated", removeArg(s, n, i), "%s", deprecations[id.Name].msg)
+				handled[id] = true
+			}
+
+		case *ast.Identifier:
+			if n == nil || handled[n] || l.declared[n.Name] {
+				return false
+			}
+			if dep, ok := deprecations[n.Name]; ok {
+				l.report(s, n, "deprecated", nil, "%s", dep.msg)
+				return false
+			}
+			if l.dsl.names[n.Name] {
+				return false
+			}
+			found := suggest(n.Name, l.dsl.names, l.declared)
+			switch len(found) {
+			case 0:
+				// Probably set with "--variable".
+			case 1:
+				fix := &Fix{Start: s.offset(n), End: s.offset(n) + len(n.Name), Text: found[0]}
+				l.report(s, n, "misspelled", fix, "%s is not defined. Did you mean %s?", n.Name, found[0])
+			default:
+				l.report(s, n, "misspelled", nil, "%s is not defined. Did you mean one of %s?", n.Name, strings.Join(found, ", "))
+			}
+		}
+		return true
+	})
+}
+
+// checkProviderType reports NewDnsProvider() and NewRegistrar() calls
+// that include the provider type, which belongs in creds.json.
+func (l *linter) checkProviderType(s *source, call *ast.CallExpression) {
+	fn := calleeName(call)
+	if (fn != "NewDnsProvider" && fn != "NewRegistrar") || len(call.ArgumentList) < 2 {
+		return
+	}
+	name, ok := call.ArgumentList[0].(*ast.StringLiteral)
+	if !ok {
+		return
+	}
+	typ, ok := call.ArgumentList[1].(*ast.StringLiteral)
+	if !ok {
+		return
+	}
+
+	// Removing the type is only safe if creds.json is known to agree.
+	// That is the case for "-" (which means "look in creds.json") and
+	// for the "none" registrar, which creds.json always has.
+	switch {
+	case typ.Value == "-":
+		l.report(s, typ, "deprecated", removeArg(s, call, 1), "%s(%q, \"-\"): \"-\" is the default and can be left out", fn, name.Value)
+	case fn == "NewRegistrar" && name.Value == "none" && typ.Value == "NONE":
+		l.report(s, typ, "deprecated", removeArg(s, call, 1), "%s(\"none\", \"NONE\") can be simplified to %s(\"none\")", fn, fn)
+	default:
+		l.report(s, typ, "deprecated", nil, "%s(%q, %q): the provider type belongs in creds.json (\"TYPE\": %q)",
+			fn, name.Value, typ.Value, typ.Value)
+	}
+}
+
+// checkTopLevel reports records and domain modifiers that are outside
+// of D(), where they do nothing.
+func (l *linter) checkTopLevel(s *source) {
+	for _, stmt := range s.prog.Body {
+		es, ok := stmt.(*ast.ExpressionStatement)
+		if !ok {
+			continue
+		}
+		call, ok := es.Expression.(*ast.CallExpression)
+		if !ok {
+			continue
+		}
+		fn := calleeName(call)
+		if l.dsl.modifiers[fn] && !sideEffects[fn] && !l.declared[fn] {
+			l.report(s, call, "outside-d", nil, "%s() outside of D() does nothing", fn)
+		}
+	}
+}
+
+// recordAt is where a record was first seen.
+type recordAt struct {
+	s    *source
+	node ast.Node
+}
+
+// checkDuplicates reports records that are listed twice, either in
+// the same D() or in a D_EXTEND() of it. Only records whose label is
+// a string literal are compared.
+func (l *linter) checkDuplicates() {
+	seen := map[string]recordAt{}
+	for _, s := range l.sources {
+		for _, stmt := range s.prog.Body {
+			es, ok := stmt.(*ast.ExpressionStatement)
+			if !ok {
+				continue
+			}
+			call, ok := es.Expression.(*ast.CallExpression)
+			if !ok {
+				continue
+			}
+			first := 0
+			switch calleeName(call) {
+			case "D":
+				first = 2
+			case "D_EXTEND":
+				first = 1
+			default:
+				continue
+			}
+			if len(call.ArgumentList) == 0 {
+				continue
+			}
+			domain, ok := call.ArgumentList[0].(*ast.StringLiteral)
+			if !ok {
+				continue
+			}
+
+			for i := first; i < len(call.ArgumentList); i++ {
+				rec, ok := call.ArgumentList[i].(*ast.CallExpression)
+				if !ok || !l.dsl.records[calleeName(rec)] || len(rec.ArgumentList) == 0 {
+					continue
+				}
+				label, ok := rec.ArgumentList[0].(*ast.StringLiteral)
+				if !ok {
+					continue
+				}
+				var rest []string
+				for _, arg := range rec.ArgumentList[1:] {
+					rest = append(rest, strings.Join(strings.Fields(s.text(arg)), " "))
+				}
+				key := fmt.Sprintf("%s %s %s", recordName(label.Value, domain.Value), calleeName(rec), strings.Join(rest, ", "))
+
+				if prev, ok := seen[key]; ok {
+					line, _ := prev.s.position(prev.s.offset(prev.node))
+					l.report(s, rec, "duplicate", removeArg(s, call, i), "%s() duplicates the record at %s:%d", calleeName(rec), prev.s.name, line)
+					continue
+				}
+				seen[key] = recordAt{s, rec}
+			}
+		}
+	}
+}
+
+// recordName returns the FQDN of a label in domain (which may be a
+// D_EXTEND() subdomain), followed by the domain's tag.
+func recordName(label, domain string) string {
+	tag := ""
+	if i := strings.IndexByte(domain, '!'); i >= 0 {
+		domain, tag = domain[:i], domain[i:]
+	}
+	label, domain = strings.ToLower(label), strings.ToLower(domain)
+	switch {
+	case label == "@":
+		return domain + tag
+	case strings.HasSuffix(label, "."):
+		return strings.TrimSuffix(label, ".") + tag
+	default:
+		return label + "." + domain + tag
+	}
+}
+
+// checkUnused reports registrars and DNS providers that are assigned
+// to a variable that is never used.
+func (l *linter) checkUnused() {
+	for _, s := range l.sources {
+		for _, stmt := range s.prog.Body {
+			vs, ok := stmt.(*ast.VariableStatement)
+			if !ok {
+				continue
+			}
+			for _, e := range vs.List {
+				v, ok := e.(*ast.VariableExpression)
+				if !ok {
+					continue
+				}
+				call, ok := v.Initializer.(*ast.CallExpression)
+				if !ok {
+					continue
+				}
+				fn := calleeName(call)
+				if (fn == "NewDnsProvider" || fn == "NewRegistrar") && l.refs[v.Name] == 0 {
+					l.report(s, v, "unused", nil, "%s is never used", v.Name)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package jslint
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v4/pkg/js"
+	"github.com/robertkrimen/otto/ast"
+	"github.com/robertkrimen/otto/parser"
+)
+
+// dsl describes the functions and variables of helpers.js.
+type dsl struct {
+	names     map[string]bool // Everything defined at the top level.
+	records   map[string]bool // The record builders (A, MX, ...).
+	modifiers map[string]bool // Functions that return a domain modifier, including the record builders.
+}
+
+// builtins are the names that exist before helpers.js runs, besides
+// those that pkg/js adds.
+var builtins = []string{
+	"Array", "Boolean", "Date", "Error", "Infinity", "JSON", "Math", "NaN",
+	"Number", "Object", "RangeError", "RegExp", "String", "SyntaxError",
+	"TypeError", "arguments", "console", "decodeURI", "decodeURIComponent",
+	"encodeURI", "encodeURIComponent", "escape", "eval", "isFinite", "isNaN",
+	"parseFloat", "parseInt", "undefined", "unescape",
+}
+
+// deprecations lists the deprecated names. If remove is true, the
+// name is a no-op and removing it is a safe fix.
+var deprecations = map[string]struct {
+	msg    string
+	remove bool
+}{
+	"AUTODNSSEC":       {"AUTODNSSEC is a no-op. Use AUTODNSSEC_ON or AUTODNSSEC_OFF", true},
+	"AUTOSPLIT":        {"AUTOSPLIT is a no-op. Long TXT records are split automatically", true},
+	"CF_REDIRECT":      {"CF_REDIRECT uses Cloudflare Page Rules, which are going away. Use CF_SINGLE_REDIRECT", false},
+	"CF_TEMP_REDIRECT": {"CF_TEMP_REDIRECT uses Cloudflare Page Rules, which are going away. Use CF_SINGLE_REDIRECT", false},
+}
+
+// sideEffects are the functions that do something when called outside
+// of D().
+var sideEffects = map[string]bool{
+	"D": true, "D_EXTEND": true, "DEFAULTS": true,
+	"DOMAIN_ELSEWHERE": true, "DOMAIN_ELSEWHERE_AUTO": true,
+}
+
+func newDSL(helpers string) (*dsl, error) {
+	prog, err := parser.ParseFile(nil, "helpers.js", helpers, 0)
+	if err != nil {
+		return nil, err
+	}
+	d := &dsl{names: map[string]bool{}, records: map[string]bool{}, modifiers: map[string]bool{}}
+	for _, name := range append(builtins, js.Builtins()...) {
+		d.names[name] = true
+	}
+	for _, stmt := range prog.Body {
+		switch stmt := stmt.(type) {
+		case *ast.VariableStatement:
+			for _, e := range stmt.List {
+				v, ok := e.(*ast.VariableExpression)
+				if !ok {
+					continue
+				}
+				d.names[v.Name] = true
+				if call, ok := v.Initializer.(*ast.CallExpression); ok {
+					if n := calleeName(call); n == "recordBuilder" || n == "rawrecordBuilder" {
+						d.records[v.Name] = true
+						d.modifiers[v.Name] = true
+					}
+				}
+			}
+		case *ast.FunctionStatement:
+			fn := stmt.Function
+			d.names[fn.Name.Name] = true
+			if returnsFunction(fn) {
+				d.modifiers[fn.Name.Name] = true
+			}
+		}
+	}
+	return d, nil
+}
+
+// returnsFunction reports whether fn returns a function literal, as
+// domain modifiers like DefaultTTL() do.
+func returnsFunction(fn *ast.FunctionLiteral) bool {
+	body, ok := fn.Body.(*ast.BlockStatement)
+	if !ok {
+		return false
+	}
+	for _, stmt := range body.List {
+		if ret, ok := stmt.(*ast.ReturnStatement); ok {
+			if _, ok := ret.Argument.(*ast.FunctionLiteral); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// suggest returns the names that name is probably a misspelling of.
+func suggest(name string, names ...map[string]bool) []string {
+	best := 3 // Anything further away is probably not a typo.
+	if len(name) <= 3 {
+		best = 1
+	}
+	var found []string
+	for _, m := range names {
+		for candidate := range m {
+			if strings.HasPrefix(candidate, "_") || candidate == name || slices.Contains(found, candidate) {
+				continue
+			}
+			dist := distance(strings.ToUpper(name), strings.ToUpper(candidate))
+			switch {
+			case dist < best:
+				best = dist
+				found = []string{candidate}
+			case dist == best && found != nil:
+				found = append(found, candidate)
+			}
+		}
+	}
+	sort.Strings(found)
+	return found
+}
+
+// distance returns the Levenshtein distance between a and b.
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,352 @@
+// Package jslint finds mistakes in dnsconfig.js without running it.
+//
+// The files are parsed, not executed, so no credentials (or even
+// network access) are needed. The checks know the DSL: the names of
+// its functions come from helpers.js itself.
+package jslint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/robertkrimen/otto/ast"
+	"github.com/robertkrimen/otto/parser"
+)
+
+// Finding is one problem found by Lint.
+type Finding struct {
+	File   string
+	Line   int
+	Column int
+	Rule   string // "deprecated", "misspelled", "outside-d", "duplicate" or "unused"
+	Msg    string
+	Fix    *Fix // nil if the problem can't be fixed safely
+}
+
+func (f Finding) String() string {
+	s := fmt.Sprintf("%s:%d:%d: %s [%s]", f.File, f.Line, f.Column, f.Msg, f.Rule)
+	if f.Fix != nil {
+		s += " (fixable)"
+	}
+	return s
+}
+
+// Fix replaces the bytes Start to End of the file with Text.
+type Fix struct {
+	Start, End int
+	Text       string
+}
+
+// source is one parsed file.
+type source struct {
+	name string
+	src  string
+	prog *ast.Program
+}
+
+// offset returns the byte offset of idx in the file.
+func (s *source) offset(idx ast.Node) int {
+	return int(idx.Idx0()) - s.prog.File.Base()
+}
+
+// position returns the line and column (both starting at 1) of a byte offset.
+func (s *source) position(offset int) (int, int) {
+	before := s.src[:offset]
+	line := strings.Count(before, "\n") + 1
+	return line, offset - strings.LastIndex(before, "\n")
+}
+
+// text returns the source code of a node.
+func (s *source) text(n ast.Node) string {
+	return s.src[int(n.Idx0())-s.prog.File.Base() : int(n.Idx1())-s.prog.File.Base()]
+}
+
+// linter holds the state of one run of Lint.
+type linter struct {
+	dsl      *dsl
+	sources  []*source
+	declared map[string]bool // Every name the user's files declare.
+	refs     map[string]int  // How often each name is used.
+	findings []Finding
+}
+
+// Lint checks filename and all the files that it loads with require()
+// and require_glob(). helpers is the content of helpers.js. variables
+// are the names of the variables set with "--variable".
+func Lint(filename, helpers string, variables []string) ([]Finding, error) {
+	d, err := newDSL(helpers)
+	if err != nil {
+		return nil, fmt.Errorf("parsing helpers.js: %w", err)
+	}
+	l := &linter{dsl: d, declared: map[string]bool{}, refs: map[string]int{}}
+	for _, v := range variables {
+		l.declared[v] = true
+	}
+	if err := l.load(filename, map[string]bool{}); err != nil {
+		return nil, err
+	}
+
+	for _, s := range l.sources {
+		l.collect(s)
+	}
+	for _, s := range l.sources {
+		l.checkNames(s)
+		l.checkTopLevel(s)
+	}
+	l.checkDuplicates()
+	l.checkUnused()
+
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		a, b := l.findings[i], l.findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return l.findings, nil
+}
+
+// load parses filename and the files that it requires, the same way
+// that pkg/js finds them.
+func (l *linter) load(filename string, seen map[string]bool) error {
+	clean := filepath.Clean(filename)
+	if seen[clean] {
+		return nil
+	}
+	seen[clean] = true
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	prog, err := parser.ParseFile(nil, filename, content, 0)
+	if err != nil {
+		return err
+	}
+	s := &source{name: filename, src: string(content), prog: prog}
+	l.sources = append(l.sources, s)
+
+	dir := filepath.Dir(filename)
+	var errs []string
+	walk(prog, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpression)
+		if !ok || call == nil || len(call.ArgumentList) == 0 {
+			return true
+		}
+		arg, ok := call.ArgumentList[0].(*ast.StringLiteral)
+		if !ok {
+			return true
+		}
+		switch calleeName(call) {
+		case "require":
+			// JSON files don't define anything to check.
+			if strings.ToLower(filepath.Ext(arg.Value)) != ".js" {
+				return true
+			}
+			file := arg.Value
+			if strings.HasPrefix(file, ".") {
+				file = filepath.Join(dir, file)
+			}
+			if err := l.load(file, seen); err != nil {
+				errs = append(errs, err.Error())
+			}
+		case "require_glob":
+			recursive := true
+			if len(call.ArgumentList) > 1 {
+				if b, ok := call.ArgumentList[1].(*ast.BooleanLiteral); ok {
+					recursive = b.Value
+				}
+			}
+			files, err := globJS(filepath.Join(dir, arg.Value), recursive)
+			if err != nil {
+				errs = append(errs, err.Error())
+			}
+			for _, file := range files {
+				if err := l.load(file, seen); err != nil {
+					errs = append(errs, err.Error())
+				}
+			}
+		}
+		return true
+	})
+	if len(errs) != 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// globJS lists the .js files in dir, like require_glob() does.
+func globJS(dir string, recursive bool) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			if !recursive && filepath.Clean(path) != filepath.Clean(dir) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.ToLower(filepath.Ext(path)) == ".js" {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// report adds a finding about node n of s.
+func (l *linter) report(s *source, n ast.Node, rule string, fix *Fix, format string, a ...interface{}) {
+	line, col := s.position(s.offset(n))
+	l.findings = append(l.findings, Finding{
+		File:   s.name,
+		Line:   line,
+		Column: col,
+		Rule:   rule,
+		Msg:    fmt.Sprintf(format, a...),
+		Fix:    fix,
+	})
+}
+
+// collect records the names that s declares and uses.
+func (l *linter) collect(s *source) {
+	walk(s.prog, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.VariableExpression:
+			if n != nil {
+				l.declared[n.Name] = true
+			}
+		case *ast.FunctionLiteral:
+			if n != nil {
+				if n.Name != nil {
+					l.declared[n.Name.Name] = true
+				}
+				for _, p := range n.ParameterList.List {
+					l.declared[p.Name] = true
+				}
+			}
+		case *ast.CatchStatement:
+			if n != nil && n.Parameter != nil {
+				l.declared[n.Parameter.Name] = true
+			}
+		case *ast.LabelledStatement:
+			if n != nil && n.Label != nil {
+				l.declared[n.Label.Name] = true
+			}
+		case *ast.CallExpression:
+			// CLI_DEFAULTS({name: value}) declares the variable "name".
+			if n != nil && calleeName(n) == "CLI_DEFAULTS" && len(n.ArgumentList) == 1 {
+				if obj, ok := n.ArgumentList[0].(*ast.ObjectLiteral); ok {
+					for _, p := range obj.Value {
+						l.declared[p.Key] = true
+					}
+				}
+			}
+		case *ast.Identifier:
+			if n != nil {
+				l.refs[n.Name]++
+			}
+		}
+		return true
+	})
+}
+
+// walk calls f for every node below n, depth first. If f returns
+// false, the children of the node are skipped. Property names (the
+// "b" in "a.b") are not visited, since they aren't variables.
+func walk(n ast.Node, f func(ast.Node) bool) {
+	ast.Walk(visitor(f), n)
+}
+
+type visitor func(ast.Node) bool
+
+func (v visitor) Enter(n ast.Node) ast.Visitor {
+	if dot, ok := n.(*ast.DotExpression); ok && dot != nil {
+		if v(n) {
+			ast.Walk(v, dot.Left)
+		}
+		return nil
+	}
+	if !v(n) {
+		return nil
+	}
+	return v
+}
+
+func (v visitor) Exit(ast.Node) {}
+
+// calleeName returns the name of the called function, if it is a
+// plain name.
+func calleeName(call *ast.CallExpression) string {
+	if id, ok := call.Callee.(*ast.Identifier); ok && id != nil {
+		return id.Name
+	}
+	return ""
+}
+
+// removeArg returns the Fix that removes argument i of call, along
+// with its comma.
+func removeArg(s *source, call *ast.CallExpression, i int) *Fix {
+	args := call.ArgumentList
+	if len(args) < 2 {
+		return nil
+	}
+	if i > 0 {
+		return &Fix{Start: int(args[i-1].Idx1()) - s.prog.File.Base(), End: int(args[i].Idx1()) - s.prog.File.Base()}
+	}
+	return &Fix{Start: s.offset(args[0]), End: s.offset(args[1])}
+}
+
+// ApplyFixes applies the fixes of the findings to the files. Fixes
+// that overlap an earlier one are skipped; running Lint again finds
+// them. It returns the number of fixes applied.
+func ApplyFixes(findings []Finding) (int, error) {
+	byFile := map[string][]*Fix{}
+	var files []string
+	for _, f := range findings {
+		if f.Fix == nil {
+			continue
+		}
+		if byFile[f.File] == nil {
+			files = append(files, f.File)
+		}
+		byFile[f.File] = append(byFile[f.File], f.Fix)
+	}
+
+	applied := 0
+	for _, file := range files {
+		fixes := byFile[file]
+		// Apply from the end of the file so the offsets stay valid.
+		sort.Slice(fixes, func(i, j int) bool { return fixes[i].Start > fixes[j].Start })
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return applied, err
+		}
+		src := string(content)
+		limit := len(src)
+		for _, fix := range fixes {
+			if fix.End > limit {
+				continue
+			}
+			src = src[:fix.Start] + fix.Text + src[fix.End:]
+			limit = fix.Start
+			applied++
+		}
+		fi, err := os.Stat(file)
+		if err != nil {
+			return applied, err
+		}
+		if err := os.WriteFile(file, []byte(src), fi.Mode()); err != nil {
+			return applied, err
+		}
+	}
+	return applied, nil
+}
@@ -0,0 +1,94 @@
+package jslint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v4/pkg/js"
+)
+
+func lint(t *testing.T, filename string) []string {
+	t.Helper()
+	findings, err := Lint(filename, js.GetHelpers(false), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range findings {
+		got = append(got, strings.TrimPrefix(f.String(), filepath.Dir(filename)+"/"))
+	}
+	return got
+}
+
+func TestLint(t *testing.T) {
+	want := []string{
+		`dnsconfig.js:1:37: NewRegistrar("none", "NONE") can be simplified to NewRegistrar("none") [deprecated] (fixable)`,
+		`dnsconfig.js:2:39: NewDnsProvider("bind", "BIND"): the provider type belongs in creds.json ("TYPE": "BIND") [deprecated]`,
+		`dnsconfig.js:3:5: DSP_OLD is never used [unused]`,
+		`dnsconfig.js:3:37: NewDnsProvider("old", "-"): "-" is the default and can be left out [deprecated] (fixable)`,
+		`dnsconfig.js:11:1: A() outside of D() does nothing [outside-d]`,
+		`dnsconfig.js:14:5: AUTOSPLIT is a no-op. Long TXT records are split automatically [deprecated] (fixable)`,
+		`dnsconfig.js:15:5: DefaultTLL is not defined. Did you mean DefaultTTL? [misspelled] (fixable)`,
+		`dnsconfig.js:19:5: CF_REDIRECT uses Cloudflare Page Rules, which are going away. Use CF_SINGLE_REDIRECT [deprecated]`,
+		`domains/extra.js:2:5: A() duplicates the record at testdata/dnsconfig.js:18 [duplicate] (fixable)`,
+		`domains/extra.js:7:5: CNAMe is not defined. Did you mean CNAME? [misspelled] (fixable)`,
+		`domains/extra.js:8:5: A() duplicates the record at testdata/dnsconfig.js:17 [duplicate] (fixable)`,
+	}
+	got := lint(t, "testdata/dnsconfig.js")
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestApplyFixes(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"dnsconfig.js", "domains/extra.js"} {
+		content, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	filename := filepath.Join(dir, "dnsconfig.js")
+	findings, err := Lint(filename, js.GetHelpers(false), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := ApplyFixes(findings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 7 {
+		t.Errorf("ApplyFixes() = %d, want 7", n)
+	}
+
+	// Only the problems without a fix remain.
+	for _, f := range lint(t, filename) {
+		if strings.HasSuffix(f, "(fixable)") {
+			t.Errorf("still fixable after ApplyFixes(): %s", f)
+		}
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "domains/extra.js"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `D_EXTEND("sub.example.com",
+    AAAA("www", "2001:db8::1"),
+END);
+
+D_EXTEND("example.com",
+    CNAME("ftp", "www"),
+END);
+`
+	if string(content) != want {
+		t.Errorf("extra.js after ApplyFixes():\n%s\nwant:\n%s", content, want)
+	}
+}
@@ -0,0 +1,23 @@
+var REG_NONE = NewRegistrar("none", "NONE");
+var DSP_BIND = NewDnsProvider("bind", "BIND");
+var DSP_OLD = NewDnsProvider("old", "-");
+
+CLI_DEFAULTS({ stage: "prod" });
+
+var MAIL = [
+    MX("@", 10, "mx.example.com."),
+];
+
+A("stray", "192.0.2.1");
+
+D("example.com", REG_NONE, DnsProvider(DSP_BIND),
+    AUTOSPLIT,
+    DefaultTLL(300),
+    MAIL,
+    A("www", "192.0.2.1"),
+    A("www.sub", "192.0.2.2"),
+    CF_REDIRECT("example.com/*", "https://www.example.com/$1"),
+    TXT("@", stage),
+END);
+
+require_glob("./domains/");
@@ -0,0 +1,9 @@
+D_EXTEND("sub.example.com",
+    A("www",  "192.0.2.2"),
+    AAAA("www", "2001:db8::1"),
+END);
+
+D_EXTEND("example.com",
+    CNAMe("ftp", "www"),
+    A("www", "192.0.2.1"),
+END);
@@ -0,0 +1,74 @@
+package lsp
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// declRe matches a documentation comment and the declaration that
+// follows it in dnscontrol.d.ts.
+var declRe = regexp.MustCompile(`(?s)/\*\*(.*?)\*/\s*(declare (?:function|const) ([A-Za-z_$][\w$]*)[^\n]*)`)
+
+// ParseDocs returns the documentation of each function and constant in
+// a TypeScript declaration file (the one "write-types" writes), as
+// Markdown. If a name is declared more than once, the first wins.
+func ParseDocs(dts string) map[string]string {
+	docs := map[string]string{}
+	for _, m := range declRe.FindAllStringSubmatch(dts, -1) {
+		name := m[3]
+		if _, ok := docs[name]; ok {
+			continue
+		}
+		var lines []string
+		for _, line := range strings.Split(m[1], "\n") {
+			line = strings.TrimSpace(line)
+			line = strings.TrimPrefix(line, "*")
+			line = strings.TrimPrefix(line, " ")
+			lines = append(lines, line)
+		}
+		doc := strings.TrimSpace(strings.Join(lines, "\n"))
+		docs[name] = "```typescript\n" + m[2] + "\n```\n\n" + doc
+	}
+	return docs
+}
+
+var (
+	// "file:line: msg" or "file:line:col: msg", as in validation errors.
+	prefixRe = regexp.MustCompile(`(?s)^(\S[^:\n]*(?::\\[^:\n]*)?):(\d+)(?::(\d+))?: (.*)$`)
+	// "    at file:line:col" or "    at func (file:line:col)", as in
+	// JavaScript stack traces.
+	frameRe = regexp.MustCompile(`(?m)^\s+at (?:\S+ \()?([^\s()]+):(\d+):(\d+)\)?$`)
+)
+
+// ParseError converts the text of an error to a Diagnostic. The place
+// is taken from a "file:line:" prefix or from the first frame of a
+// stack trace that isn't in one of the skip files (helpers.js, say). If
+// neither is found, the problem is reported at the top of filename.
+func ParseError(filename, text string, warning bool, skip ...string) Diagnostic {
+	d := Diagnostic{File: filename, Warning: warning, Msg: text}
+	if m := prefixRe.FindStringSubmatch(text); m != nil {
+		d.File, d.Msg = m[1], m[4]
+		d.Line, _ = strconv.Atoi(m[2])
+		d.Column, _ = strconv.Atoi(m[3])
+		return d
+	}
+
+frames:
+	for _, m := range frameRe.FindAllStringSubmatch(text, -1) {
+		for _, s := range skip {
+			if m[1] == s {
+				continue frames
+			}
+		}
+		d.File = m[1]
+		d.Line, _ = strconv.Atoi(m[2])
+		d.Column, _ = strconv.Atoi(m[3])
+		break
+	}
+	// The stack trace is shown by the position itself.
+	if i := strings.Index(text, "\n    at "); i >= 0 {
+		d.Msg = text[:i]
+	}
+	return d
+}
@@ -0,0 +1,383 @@
+// Package lsp is a small Language Server Protocol server for
+// dnsconfig.js. It reports the problems found by a Checker when a file
+// is opened or saved, and shows the documentation of the DSL when the
+// mouse hovers over a name.
+//
+// Only the parts of the protocol that editors need for that are
+// implemented. Messages are read from and written to a stream (usually
+// stdin and stdout) in the "Content-Length" framing of the protocol.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Diagnostic is one problem found by a Checker.
+type Diagnostic struct {
+	File    string
+	Line    int // Starts at 1. 0 if unknown.
+	Column  int // Starts at 1. 0 if unknown.
+	Warning bool
+	Msg     string
+}
+
+// Checker checks the configuration that starts at filename. It is
+// given the file that was saved, which is usually dnsconfig.js but may
+// be a file that it requires.
+type Checker func(filename string) []Diagnostic
+
+// Server is a language server.
+type Server struct {
+	in    *bufio.Reader
+	out   io.Writer
+	check Checker
+	docs  map[string]string // Markdown documentation, by name.
+
+	texts     map[string]string // The content of the open documents, by URI.
+	published map[string]bool   // The URIs that have diagnostics.
+	shutdown  bool
+}
+
+// NewServer returns a server that reads requests from in and writes
+// responses to out. docs is the hover documentation, by name.
+func NewServer(in io.Reader, out io.Writer, check Checker, docs map[string]string) *Server {
+	return &Server{
+		in:        bufio.NewReader(in),
+		out:       out,
+		check:     check,
+		docs:      docs,
+		texts:     map[string]string{},
+		published: map[string]bool{},
+	}
+}
+
+// message is a JSON-RPC 2.0 request, response or notification.
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  interface{}      `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// JSON-RPC error codes.
+const (
+	codeMethodNotFound = -32601
+	codeInvalidRequest = -32600
+)
+
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type rangeT struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+type textDocument struct {
+	URI  string `json:"uri"`
+	Text string `json:"text,omitempty"`
+}
+
+type docParams struct {
+	TextDocument   textDocument `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges,omitempty"`
+	Position position `json:"position"`
+}
+
+type diagnostic struct {
+	Range    rangeT `json:"range"`
+	Severity int    `json:"severity"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+}
+
+// Diagnostic severities.
+const (
+	severityError   = 1
+	severityWarning = 2
+)
+
+// Run serves requests until the client sends "exit" or closes the
+// connection.
+func (s *Server) Run() error {
+	for {
+		msg, err := s.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// read reads one message.
+func (s *Server) read() (*message, error) {
+	body, err := readBody(s.in)
+	if err != nil {
+		return nil, err
+	}
+	msg := &message{}
+	if err := json.Unmarshal(body, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// readBody reads the body of one message.
+func readBody(in *bufio.Reader) ([]byte, error) {
+	header, err := textproto.NewReader(in).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	n, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length: %w", err)
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(in, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// write writes one message.
+func (s *Server) write(msg *message) error {
+	msg.JSONRPC = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+func (s *Server) reply(id *json.RawMessage, result interface{}) error {
+	if result == nil {
+		// "null" must be sent explicitly; omitempty would drop it.
+		result = json.RawMessage("null")
+	}
+	return s.write(&message{ID: id, Result: result})
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return s.write(&message{Method: method, Params: raw})
+}
+
+func (s *Server) handle(msg *message) error {
+	var p docParams
+	if len(msg.Params) != 0 {
+		if err := json.Unmarshal(msg.Params, &p); err != nil {
+			return err
+		}
+	}
+
+	switch msg.Method {
+	case "initialize":
+		return s.reply(msg.ID, map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync": map[string]interface{}{
+					"openClose": true,
+					"change":    1, // Full
+					"save":      true,
+				},
+				"hoverProvider": true,
+			},
+			"serverInfo": map[string]string{"name": "dnscontrol"},
+		})
+	case "shutdown":
+		s.shutdown = true
+		return s.reply(msg.ID, nil)
+	case "initialized", "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration":
+		return nil
+
+	case "textDocument/didOpen":
+		s.texts[p.TextDocument.URI] = p.TextDocument.Text
+		return s.publish(p.TextDocument.URI)
+	case "textDocument/didChange":
+		// The problems are only looked for when the file is saved, since
+		// the checker reads the files from disk.
+		if n := len(p.ContentChanges); n != 0 {
+			s.texts[p.TextDocument.URI] = p.ContentChanges[n-1].Text
+		}
+		return nil
+	case "textDocument/didSave":
+		if p.TextDocument.Text != "" {
+			s.texts[p.TextDocument.URI] = p.TextDocument.Text
+		}
+		return s.publish(p.TextDocument.URI)
+	case "textDocument/didClose":
+		delete(s.texts, p.TextDocument.URI)
+		return nil
+	case "textDocument/hover":
+		return s.reply(msg.ID, s.hover(p))
+	}
+
+	if msg.ID == nil {
+		// Notifications that aren't understood are ignored.
+		return nil
+	}
+	code := codeMethodNotFound
+	if s.shutdown {
+		code = codeInvalidRequest
+	}
+	return s.write(&message{ID: msg.ID, Error: &responseError{Code: code, Message: "unsupported method " + msg.Method}})
+}
+
+// publish checks the configuration and sends the problems found, by
+// file. The problems of files that no longer have any are cleared.
+func (s *Server) publish(uri string) error {
+	filename := uriToPath(uri)
+	byURI := map[string][]diagnostic{uri: {}}
+	for _, d := range s.check(filename) {
+		u := uri
+		if d.File != "" && filepath.Clean(d.File) != filepath.Clean(filename) {
+			u = pathToURI(d.File)
+		}
+		byURI[u] = append(byURI[u], toDiagnostic(d))
+	}
+	for u := range s.published {
+		if _, ok := byURI[u]; !ok {
+			byURI[u] = []diagnostic{}
+		}
+	}
+
+	uris := make([]string, 0, len(byURI))
+	for u := range byURI {
+		uris = append(uris, u)
+	}
+	sort.Strings(uris)
+	for _, u := range uris {
+		if err := s.notify("textDocument/publishDiagnostics", map[string]interface{}{
+			"uri":         u,
+			"diagnostics": byURI[u],
+		}); err != nil {
+			return err
+		}
+		if len(byURI[u]) == 0 {
+			delete(s.published, u)
+		} else {
+			s.published[u] = true
+		}
+	}
+	return nil
+}
+
+// toDiagnostic converts d to the protocol's diagnostic, which marks the
+// rest of the line.
+func toDiagnostic(d Diagnostic) diagnostic {
+	start := position{Line: max(d.Line-1, 0), Character: max(d.Column-1, 0)}
+	severity := severityError
+	if d.Warning {
+		severity = severityWarning
+	}
+	return diagnostic{
+		Range:    rangeT{Start: start, End: position{Line: start.Line + 1}},
+		Severity: severity,
+		Source:   "dnscontrol",
+		Message:  d.Msg,
+	}
+}
+
+// hover returns the documentation of the name at the position, or nil.
+func (s *Server) hover(p docParams) interface{} {
+	text, ok := s.texts[p.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+	doc, ok := s.docs[wordAt(text, p.Position)]
+	if !ok {
+		return nil
+	}
+	return map[string]interface{}{
+		"contents": map[string]string{"kind": "markdown", "value": doc},
+	}
+}
+
+// wordAt returns the identifier at pos in text. The character offset of
+// pos counts UTF-16 code units, as the protocol requires.
+func wordAt(text string, pos position) string {
+	lines := strings.Split(text, "\n")
+	if pos.Line < 0 || pos.Line >= len(lines) {
+		return ""
+	}
+	line := []rune(strings.TrimSuffix(lines[pos.Line], "\r"))
+
+	i, units := 0, 0
+	for i < len(line) && units < pos.Character {
+		units += utf16.RuneLen(line[i])
+		i++
+	}
+	start, end := i, i
+	for start > 0 && isIdent(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isIdent(line[end]) {
+		end++
+	}
+	return string(line[start:end])
+}
+
+func isIdent(r rune) bool {
+	return r == '_' || r == '$' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+// uriToPath converts a "file:" URI to a filename.
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	p := u.Path
+	// file:///C:/dir/file.js
+	if len(p) > 2 && p[0] == '/' && p[2] == ':' {
+		p = p[1:]
+	}
+	return filepath.FromSlash(p)
+}
+
+// pathToURI converts a filename to a "file:" URI.
+func pathToURI(filename string) string {
+	if abs, err := filepath.Abs(filename); err == nil {
+		filename = abs
+	}
+	p := filepath.ToSlash(filename)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return (&url.URL{Scheme: "file", Path: p}).String()
+}
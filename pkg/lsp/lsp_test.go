@@ -0,0 +1,195 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func frame(body string) string {
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+// run sends the requests to a server and returns the messages that it
+// sent back.
+func run(t *testing.T, check Checker, docs map[string]string, requests ...string) []map[string]interface{} {
+	t.Helper()
+	var in, out bytes.Buffer
+	for _, r := range requests {
+		in.WriteString(frame(r))
+	}
+	if err := NewServer(&in, &out, check, docs).Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	var msgs []map[string]interface{}
+	r := bufio.NewReader(&out)
+	for {
+		body, err := readBody(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal(body, &m); err != nil {
+			t.Fatal(err)
+		}
+		msgs = append(msgs, m)
+	}
+	return msgs
+}
+
+func TestServer(t *testing.T) {
+	var checked []string
+	problems := []Diagnostic{
+		{File: "/cfg/dnsconfig.js", Line: 4, Column: 3, Msg: "bad record"},
+		{File: "/cfg/domains/b.js", Line: 2, Warning: true, Msg: "odd record"},
+	}
+	check := func(filename string) []Diagnostic {
+		checked = append(checked, filename)
+		p := problems
+		problems = nil // Fixed by the next save.
+		return p
+	}
+	docs := map[string]string{"CNAME": "CNAME docs"}
+
+	msgs := run(t, check, docs,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"initialized","params":{}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///cfg/dnsconfig.js","text":"D(\"a.com\",\n  CNAME(\"x\", \"y.\"),\n)"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"textDocument/hover","params":{"textDocument":{"uri":"file:///cfg/dnsconfig.js"},"position":{"line":1,"character":4}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"textDocument/hover","params":{"textDocument":{"uri":"file:///cfg/dnsconfig.js"},"position":{"line":0,"character":0}}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didSave","params":{"textDocument":{"uri":"file:///cfg/dnsconfig.js"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"textDocument/definition","params":{}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+		`{"jsonrpc":"2.0","id":6,"method":"initialize","params":{}}`,
+	)
+
+	var got []string
+	for _, m := range msgs {
+		s, _ := json.Marshal(m)
+		got = append(got, string(s))
+	}
+	want := []string{
+		`{"id":1,"jsonrpc":"2.0","result":{"capabilities":{"hoverProvider":true,"textDocumentSync":{"change":1,"openClose":true,"save":true}},"serverInfo":{"name":"dnscontrol"}}}`,
+		// didOpen
+		`{"jsonrpc":"2.0","method":"textDocument/publishDiagnostics","params":{"diagnostics":[{"message":"bad record","range":{"end":{"character":0,"line":4},"start":{"character":2,"line":3}},"severity":1,"source":"dnscontrol"}],"uri":"file:///cfg/dnsconfig.js"}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/publishDiagnostics","params":{"diagnostics":[{"message":"odd record","range":{"end":{"character":0,"line":2},"start":{"character":0,"line":1}},"severity":2,"source":"dnscontrol"}],"uri":"file:///cfg/domains/b.js"}}`,
+		// hover
+		`{"id":2,"jsonrpc":"2.0","result":{"contents":{"kind":"markdown","value":"CNAME docs"}}}`,
+		`{"id":3,"jsonrpc":"2.0","result":null}`,
+		// didSave clears both files.
+		`{"jsonrpc":"2.0","method":"textDocument/publishDiagnostics","params":{"diagnostics":[],"uri":"file:///cfg/dnsconfig.js"}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/publishDiagnostics","params":{"diagnostics":[],"uri":"file:///cfg/domains/b.js"}}`,
+		`{"error":{"code":-32601,"message":"unsupported method textDocument/definition"},"id":4,"jsonrpc":"2.0"}`,
+		`{"id":5,"jsonrpc":"2.0","result":null}`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d messages, want %d:\n%s", len(got), len(want), strings.Join(got, "\n"))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("message %d:\n got %s\nwant %s", i, got[i], want[i])
+		}
+	}
+	if want := []string{"/cfg/dnsconfig.js", "/cfg/dnsconfig.js"}; strings.Join(checked, " ") != strings.Join(want, " ") {
+		t.Errorf("checked %v, want %v", checked, want)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		text    string
+		warning bool
+		want    Diagnostic
+	}{
+		{
+			text: "domains/a.js:12: cannot have CNAME and A",
+			want: Diagnostic{File: "domains/a.js", Line: 12, Msg: "cannot have CNAME and A"},
+		},
+		{
+			text:    "records.yaml:3:5: unknown type",
+			warning: true,
+			want:    Diagnostic{File: "records.yaml", Line: 3, Column: 5, Warning: true, Msg: "unknown type"},
+		},
+		{
+			text: "ReferenceError: 'FOO' is not defined\n    at helpers.js:10:3\n    at /cfg/dnsconfig.js:4:3",
+			want: Diagnostic{File: "/cfg/dnsconfig.js", Line: 4, Column: 3, Msg: "ReferenceError: 'FOO' is not defined"},
+		},
+		{
+			text: "Error: bad\n    at A (/cfg/x.js:7:9)",
+			want: Diagnostic{File: "/cfg/x.js", Line: 7, Column: 9, Msg: "Error: bad"},
+		},
+		{
+			text: "executing dnsconfig.js: no domains",
+			want: Diagnostic{File: "/cfg/dnsconfig.js", Msg: "executing dnsconfig.js: no domains"},
+		},
+	}
+	for _, tst := range tests {
+		got := ParseError("/cfg/dnsconfig.js", tst.text, tst.warning, "helpers.js")
+		if got != tst.want {
+			t.Errorf("ParseError(%q):\n got %+v\nwant %+v", tst.text, got, tst.want)
+		}
+	}
+}
+
+func TestParseDocs(t *testing.T) {
+	dts := `
+/**
+ * A adds an A record.
+ *
+ * @see https://example.com/a
+ */
+declare function A(name: string, address: string): DomainModifier;
+
+/** The first one. */
+declare function require(name: ` + "`${string}.json`" + `): any;
+/** The second one. */
+declare function require(name: string): true;
+
+/** Makes the record critical. */
+declare const CAA_CRITICAL: RecordModifier;
+`
+	docs := ParseDocs(dts)
+	want := map[string]string{
+		"A":            "```typescript\ndeclare function A(name: string, address: string): DomainModifier;\n```\n\nA adds an A record.\n\n@see https://example.com/a",
+		"require":      "```typescript\ndeclare function require(name: `${string}.json`): any;\n```\n\nThe first one.",
+		"CAA_CRITICAL": "```typescript\ndeclare const CAA_CRITICAL: RecordModifier;\n```\n\nMakes the record critical.",
+	}
+	if len(docs) != len(want) {
+		t.Errorf("got %d docs, want %d", len(docs), len(want))
+	}
+	for name, w := range want {
+		if docs[name] != w {
+			t.Errorf("docs[%q]:\n got %q\nwant %q", name, docs[name], w)
+		}
+	}
+}
+
+func TestWordAt(t *testing.T) {
+	text := "D(\"é.com\",\r\n  MX(\"@\", 10, \"mx.\"),\n)"
+	tests := []struct {
+		line, char int
+		want       string
+	}{
+		{0, 0, "D"},
+		{0, 1, "D"},
+		{1, 2, "MX"},
+		{1, 4, "MX"},
+		{1, 5, ""},
+		{1, 11, "10"},
+		{5, 0, ""},
+	}
+	for _, tst := range tests {
+		if got := wordAt(text, position{Line: tst.line, Character: tst.char}); got != tst.want {
+			t.Errorf("wordAt(%d, %d) = %q, want %q", tst.line, tst.char, got, tst.want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package normalize
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/StackExchange/dnscontrol/v4/pkg/transform"
+)
+
+// AUTOPTR() keeps a reverse zone in sync with the forward zones: the A
+// and AAAA records of every forward zone generate the matching PTR
+// records. An address goes to the most specific reverse zone of the
+// configuration that covers it, and only if that zone has AUTOPTR().
+// A PTR record written in the reverse zone takes precedence.
+
+// isReverseZone returns true if name is an in-addr.arpa or ip6.arpa zone.
+func isReverseZone(name string) bool {
+	return strings.HasSuffix(name, ".in-addr.arpa") || strings.HasSuffix(name, ".ip6.arpa")
+}
+
+// checkAutoPTR verifies the AUTOPTR() policy of a domain.
+func checkAutoPTR(dc *models.DomainConfig) (errs []error) {
+	switch dc.AutoPTR {
+	case "", models.AutoPTRError, models.AutoPTRFirst, models.AutoPTRAll, models.AutoPTRSkip:
+	default:
+		errs = append(errs, fmt.Errorf("domain %s: AUTOPTR(%q): unknown policy (valid: %s, %s, %s, %s)", dc.Name, dc.AutoPTR,
+			models.AutoPTRError, models.AutoPTRFirst, models.AutoPTRAll, models.AutoPTRSkip))
+		return errs
+	}
+	if dc.AutoPTR != "" && !isReverseZone(dc.Name) {
+		errs = append(errs, fmt.Errorf("domain %s: AUTOPTR() can only be used in in-addr.arpa and ip6.arpa domains", dc.Name))
+	}
+	return errs
+}
+
+// reverseZoneFor returns the most specific of the reverse zones that
+// covers addr, and the label of addr in it. It returns "" if none does.
+func reverseZoneFor(addr netip.Addr, zones []string) (zone, label string) {
+	for _, z := range zones {
+		if addr.Is4() != strings.HasSuffix(z, ".in-addr.arpa") {
+			continue
+		}
+		l, err := transform.PtrNameMagic(addr.String(), z)
+		if err != nil {
+			continue // Not covered.
+		}
+		// A classless zone (64/26.2.0.192.in-addr.arpa) is more
+		// specific than its parent, though the labels are the same.
+		if zone == "" || strings.Count(z, ".") > strings.Count(zone, ".") {
+			zone, label = z, l
+		}
+	}
+	return zone, label
+}
+
+// generateAutoPTRs adds the PTR records of the domains that have
+// AUTOPTR(). Several names that claim the same address are a conflict,
+// which is resolved according to the domain's policy.
+func generateAutoPTRs(config *models.DNSConfig) (errs []error) {
+	var zones []string
+	enabled := false
+	seen := map[string]bool{}
+	for _, d := range config.Domains {
+		if !isReverseZone(d.Name) {
+			continue
+		}
+		enabled = enabled || d.AutoPTR != ""
+		if !seen[d.Name] {
+			seen[d.Name] = true
+			zones = append(zones, d.Name)
+		}
+	}
+	if !enabled {
+		return nil
+	}
+
+	// The names that claim each address, in the order of dnsconfig.js.
+	// The same name in several views of a split horizon domain is one
+	// claim.
+	type claim struct {
+		zone, label string
+		recs        []*models.RecordConfig
+	}
+	claims := map[netip.Addr]*claim{}
+	var addrs []netip.Addr
+	for _, d := range config.Domains {
+		if isReverseZone(d.Name) {
+			continue
+		}
+		for _, rec := range d.Records {
+			if rec.Type != "A" && rec.Type != "AAAA" || strings.HasPrefix(rec.NameFQDN, "*.") {
+				continue
+			}
+			addr, err := netip.ParseAddr(rec.GetTargetField())
+			if err != nil {
+				continue // Reported by checkTargets.
+			}
+			c := claims[addr]
+			if c == nil {
+				zone, label := reverseZoneFor(addr, zones)
+				if zone == "" {
+					continue
+				}
+				c = &claim{zone: zone, label: label}
+				claims[addr] = c
+				addrs = append(addrs, addr)
+			}
+			dup := false
+			for _, r := range c.recs {
+				dup = dup || r.NameFQDN == rec.NameFQDN
+			}
+			if !dup {
+				c.recs = append(c.recs, rec)
+			}
+		}
+	}
+
+	for _, d := range config.Domains {
+		if d.AutoPTR == "" {
+			continue
+		}
+		for _, addr := range addrs {
+			c := claims[addr]
+			if c.zone != d.Name || d.Records.HasRecordTypeName("PTR", c.label) {
+				continue
+			}
+			recs := c.recs
+			if len(recs) > 1 {
+				var names []string
+				for _, r := range recs {
+					names = append(names, r.NameFQDN)
+				}
+				err := fmt.Errorf("domain %s: AUTOPTR: address %s is claimed by %s", d.Name, addr, strings.Join(names, ", "))
+				switch d.AutoPTR {
+				case models.AutoPTRError:
+					errs = append(errs, atSource(recs[1], err))
+					continue
+				case models.AutoPTRFirst:
+					errs = append(errs, atSource(recs[1], Warning{fmt.Errorf("%w; using %s", err, names[0])}))
+					recs = recs[:1]
+				case models.AutoPTRAll:
+					errs = append(errs, atSource(recs[1], Warning{fmt.Errorf("%w; adding a PTR for each", err)}))
+				case models.AutoPTRSkip:
+					errs = append(errs, atSource(recs[1], Warning{fmt.Errorf("%w; adding no PTR", err)}))
+					continue
+				}
+			}
+			for _, rec := range recs {
+				ptr := &models.RecordConfig{Type: "PTR", TTL: recs[0].TTL, Metadata: map[string]string{}, Source: rec.Source}
+				ptr.SetLabel(c.label, d.Name)
+				if err := ptr.SetTarget(rec.NameFQDN + "."); err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				d.Records = append(d.Records, ptr)
+			}
+		}
+	}
+	return errs
+}
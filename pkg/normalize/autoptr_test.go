@@ -0,0 +1,103 @@
+package normalize
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+)
+
+func makeAutoPTRConfig(policy string) *models.DNSConfig {
+	mk := func(zone, label, typ, target string) *models.RecordConfig {
+		r := &models.RecordConfig{Type: typ, TTL: 300, Metadata: map[string]string{}}
+		r.SetLabel(label, zone)
+		r.MustSetTarget(target)
+		return r
+	}
+	return &models.DNSConfig{
+		Domains: []*models.DomainConfig{
+			{Name: "example.com", Records: models.Records{
+				mk("example.com", "@", "A", "192.0.2.1"),
+				mk("example.com", "www", "A", "192.0.2.1"),     // Conflict.
+				mk("example.com", "mail", "A", "192.0.2.25"),   // Manual PTR.
+				mk("example.com", "cust", "A", "192.0.2.65"),   // Classless.
+				mk("example.com", "*", "A", "192.0.2.99"),      // Wildcard.
+				mk("example.com", "v6", "AAAA", "2001:db8::1"), // IPv6.
+				mk("example.com", "far", "A", "198.51.100.1"),  // No reverse zone.
+				mk("example.com", "v6", "A", "192.0.2.2"),
+			}},
+			{Name: "example.net", Records: models.Records{
+				mk("example.net", "www", "A", "192.0.2.2"),
+			}},
+			{Name: "2.0.192.in-addr.arpa", AutoPTR: policy, Records: models.Records{
+				mk("2.0.192.in-addr.arpa", "25", "PTR", "smtp.example.com."),
+			}},
+			{Name: "64/26.2.0.192.in-addr.arpa", Records: models.Records{}},
+			{Name: "8.b.d.0.1.0.0.2.ip6.arpa", AutoPTR: policy, Records: models.Records{}},
+		},
+	}
+}
+
+func TestGenerateAutoPTRs(t *testing.T) {
+	ptrs := func(dc *models.DomainConfig) []string {
+		var s []string
+		for _, r := range dc.Records {
+			if r.Type == "PTR" {
+				s = append(s, r.Name+" "+r.GetTargetField())
+			}
+		}
+		sort.Strings(s)
+		return s
+	}
+
+	tests := []struct {
+		policy   string
+		fatal    int
+		warnings int
+		want     []string
+	}{
+		{models.AutoPTRError, 2, 0, []string{"25 smtp.example.com."}},
+		{models.AutoPTRFirst, 0, 2, []string{"1 example.com.", "2 v6.example.com.", "25 smtp.example.com."}},
+		{models.AutoPTRAll, 0, 2, []string{"1 example.com.", "1 www.example.com.", "2 v6.example.com.", "2 www.example.net.", "25 smtp.example.com."}},
+		{models.AutoPTRSkip, 0, 2, []string{"25 smtp.example.com."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			config := makeAutoPTRConfig(tt.policy)
+			var fatal, warnings int
+			for _, err := range generateAutoPTRs(config) {
+				if _, ok := err.(Warning); ok {
+					warnings++
+				} else {
+					fatal++
+				}
+			}
+			if fatal != tt.fatal || warnings != tt.warnings {
+				t.Errorf("%d errors and %d warnings, want %d and %d", fatal, warnings, tt.fatal, tt.warnings)
+			}
+			if got := ptrs(config.Domains[2]); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PTRs = %v, want %v", got, tt.want)
+			}
+			// The classless zone covers 192.0.2.65, but hasn't AUTOPTR().
+			if got := ptrs(config.Domains[3]); len(got) != 0 {
+				t.Errorf("PTRs of the classless zone = %v, want none", got)
+			}
+			if got, want := ptrs(config.Domains[4]), []string{"1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0 v6.example.com."}; !reflect.DeepEqual(got, want) {
+				t.Errorf("PTRs of the IPv6 zone = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestCheckAutoPTR(t *testing.T) {
+	if errs := checkAutoPTR(&models.DomainConfig{Name: "2.0.192.in-addr.arpa", AutoPTR: "newest"}); len(errs) != 1 {
+		t.Errorf("checkAutoPTR() of an unknown policy = %v, want an error", errs)
+	}
+	if errs := checkAutoPTR(&models.DomainConfig{Name: "example.com", AutoPTR: models.AutoPTRError}); len(errs) != 1 {
+		t.Errorf("checkAutoPTR() of a forward domain = %v, want an error", errs)
+	}
+	if errs := checkAutoPTR(&models.DomainConfig{Name: "2.0.192.in-addr.arpa", AutoPTR: models.AutoPTRFirst}); len(errs) != 0 {
+		t.Errorf("checkAutoPTR() = %v", errs)
+	}
+}
@@ -0,0 +1,215 @@
+package normalize
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+)
+
+// DOWNGRADE() lets a domain be dual-hosted on providers with different
+// feature sets. Records that a provider doesn't support are translated
+// (or dropped) for that provider only. The IR is not modified; each
+// provider gets its own copy of the domain.
+
+// lookupIP resolves the target of an ALIAS record. Replaced by tests.
+var lookupIP = net.LookupIP
+
+// mappable lists the rtypes that MAP may change each rtype to. MAP only
+// changes the type of a record, so the rdata of both types must be the
+// same.
+var mappable = map[string][]string{
+	"HTTPS": {"SVCB"},
+	"SVCB":  {"HTTPS"},
+}
+
+// canMap returns true if records of rType can be changed to mapTo.
+func canMap(rType, mapTo string) bool {
+	for _, t := range mappable[rType] {
+		if t == mapTo {
+			return true
+		}
+	}
+	return false
+}
+
+// findCapabilityCheck returns the capability check for rType, or nil if
+// every provider supports rType.
+func findCapabilityCheck(rType string) *pairTypeCapability {
+	for i := range providerCapabilityChecks {
+		if providerCapabilityChecks[i].rType == rType {
+			return &providerCapabilityChecks[i]
+		}
+	}
+	return nil
+}
+
+// findDowngrade returns the DOWNGRADE() rule for rType, or nil.
+func findDowngrade(dc *models.DomainConfig, rType string) *models.DowngradeConfig {
+	for _, dg := range dc.Downgrades {
+		if dg.RType == rType {
+			return dg
+		}
+	}
+	return nil
+}
+
+// providerLacks returns true if provider type pType can't handle rType.
+func providerLacks(pType, rType string) bool {
+	ty := findCapabilityCheck(rType)
+	if ty == nil {
+		return false
+	}
+	return !providerHasAtLeastOneCapability(pType, ty.caps...)
+}
+
+// checkDowngrades verifies the DOWNGRADE() rules of a domain.
+func checkDowngrades(dc *models.DomainConfig) (errs []error) {
+	seen := map[string]bool{}
+	for _, dg := range dc.Downgrades {
+		if seen[dg.RType] {
+			errs = append(errs, fmt.Errorf("domain %s: DOWNGRADE(%q) is specified more than once", dc.Name, dg.RType))
+			continue
+		}
+		seen[dg.RType] = true
+
+		if dg.RType == "AUTODNSSEC" || findCapabilityCheck(dg.RType) == nil {
+			errs = append(errs, fmt.Errorf("domain %s: DOWNGRADE(%q): %s is supported by all providers", dc.Name, dg.RType, dg.RType))
+			continue
+		}
+
+		switch dg.Action {
+		case models.DowngradeResolve:
+			if dg.RType != "ALIAS" {
+				errs = append(errs, fmt.Errorf("domain %s: DOWNGRADE(%q, %q): only ALIAS records can be resolved", dc.Name, dg.RType, dg.Action))
+			}
+		case models.DowngradeDrop:
+		case models.DowngradeMap:
+			if dg.MapTo == "" || dg.MapTo == dg.RType {
+				errs = append(errs, fmt.Errorf("domain %s: DOWNGRADE(%q, %q) needs a different rtype to map to", dc.Name, dg.RType, dg.Action))
+			} else if !canMap(dg.RType, dg.MapTo) {
+				errs = append(errs, fmt.Errorf("domain %s: DOWNGRADE(%q, %q, %q): %s records can't be changed to %s, as their data differ", dc.Name, dg.RType, dg.Action, dg.MapTo, dg.RType, dg.MapTo))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("domain %s: DOWNGRADE(%q, %q): unknown action (valid: %s, %s, %s)", dc.Name, dg.RType, dg.Action,
+				models.DowngradeResolve, models.DowngradeDrop, models.DowngradeMap))
+		}
+	}
+	return errs
+}
+
+// DowngradeRecords returns a copy of dc in which the records that
+// provider type pType does not support have been translated according
+// to the domain's DOWNGRADE() rules. The warnings describe the changes.
+// If nothing needs to be translated, dc is returned as-is.
+func DowngradeRecords(dc *models.DomainConfig, pType string) (*models.DomainConfig, []error, error) {
+	if len(dc.Downgrades) == 0 {
+		return dc, nil, nil
+	}
+	recs, warns, err := downgradeRecords(dc, pType, true)
+	if err != nil || warns == nil {
+		return dc, nil, err
+	}
+
+	ndc, err := dc.Copy()
+	if err != nil {
+		return nil, nil, err
+	}
+	ndc.Records = recs
+	return ndc, warns, nil
+}
+
+// downgradeRecords returns the records of dc as provider type pType
+// should receive them. If resolve is false, ALIAS records that would be
+// resolved are left out instead (for checks that must not touch the
+// network). warns is nil if no record was changed.
+func downgradeRecords(dc *models.DomainConfig, pType string, resolve bool) (models.Records, []error, error) {
+	if len(dc.Downgrades) == 0 {
+		return dc.Records, nil, nil
+	}
+
+	var warns []error
+	var recs models.Records
+	counts := map[*models.DowngradeConfig]int{}
+
+	for _, rec := range dc.Records {
+		dg := findDowngrade(dc, rec.Type)
+		if dg == nil || !providerLacks(pType, rec.Type) {
+			recs = append(recs, rec)
+			continue
+		}
+		counts[dg]++
+
+		switch dg.Action {
+		case models.DowngradeDrop:
+			// Nothing to add.
+
+		case models.DowngradeMap:
+			n, err := rec.Copy()
+			if err != nil {
+				return nil, nil, err
+			}
+			n.Type = dg.MapTo
+			recs = append(recs, n)
+
+		case models.DowngradeResolve:
+			if !resolve {
+				continue
+			}
+			rrs, err := resolveAlias(rec, dg.TTL)
+			if err != nil {
+				return nil, nil, fmt.Errorf("domain %s: DOWNGRADE(%q): %w", dc.Name, rec.Type, err)
+			}
+			recs = append(recs, rrs...)
+		}
+	}
+
+	for _, dg := range dc.Downgrades {
+		n := counts[dg]
+		if n == 0 {
+			continue
+		}
+		var what string
+		switch dg.Action {
+		case models.DowngradeDrop:
+			what = "dropped"
+		case models.DowngradeMap:
+			what = "changed to " + dg.MapTo
+		case models.DowngradeResolve:
+			what = "replaced by the A/AAAA records they resolve to now (they are not refreshed until the next push)"
+		}
+		warns = append(warns, Warning{fmt.Errorf("domain %s: %s does not support %s; %d record(s) %s", dc.Name, pType, dg.RType, n, what)})
+	}
+	return recs, warns, nil
+}
+
+// resolveAlias returns the A/AAAA records that an ALIAS record
+// currently resolves to. If ttl is 0, the ALIAS's TTL is used.
+func resolveAlias(rec *models.RecordConfig, ttl uint32) (models.Records, error) {
+	target := strings.TrimSuffix(rec.GetTargetField(), ".")
+	ips, err := lookupIP(target)
+	if err != nil {
+		return nil, fmt.Errorf("can't resolve ALIAS %s -> %s: %w", rec.GetLabelFQDN(), target, err)
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("ALIAS %s -> %s resolves to nothing", rec.GetLabelFQDN(), target)
+	}
+	// Sort so that repeated runs generate the same records.
+	sort.Slice(ips, func(i, j int) bool { return ips[i].String() < ips[j].String() })
+
+	var recs models.Records
+	for _, ip := range ips {
+		n := newRec(rec, ttl)
+		n.Type = "AAAA"
+		if ip.To4() != nil {
+			n.Type = "A"
+		}
+		if err := n.SetTargetIP(ip); err != nil {
+			return nil, err
+		}
+		recs = append(recs, n)
+	}
+	return recs, nil
+}
@@ -0,0 +1,147 @@
+package normalize
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/StackExchange/dnscontrol/v4/providers"
+)
+
+const ProviderFullFeatured = "ALIAS_HTTPS_SVCB_SUPPORT"
+
+func init() {
+	providers.RegisterDomainServiceProviderType(ProviderFullFeatured, providers.DspFuncs{}, providers.DocumentationNotes{
+		providers.CanUseAlias: providers.Can(),
+		providers.CanUseHTTPS: providers.Can(),
+		providers.CanUseSVCB:  providers.Can(),
+	})
+}
+
+func makeDowngradeDomain(dgs ...*models.DowngradeConfig) *models.DomainConfig {
+	mk := func(label, typ, target string) *models.RecordConfig {
+		r := &models.RecordConfig{Type: typ, TTL: 300}
+		r.SetLabel(label, "example.com")
+		r.SetTarget(target)
+		return r
+	}
+	return &models.DomainConfig{
+		Name: "example.com",
+		Records: models.Records{
+			mk("@", "ALIAS", "lb.example.net."),
+			mk("www", "HTTPS", "."),
+			mk("mail", "A", "10.0.0.1"),
+		},
+		Downgrades: dgs,
+		DNSProviderInstances: []*models.DNSProviderInstance{
+			{ProviderBase: models.ProviderBase{ProviderType: ProviderFullFeatured}},
+			{ProviderBase: models.ProviderBase{ProviderType: ProviderNoDS}},
+		},
+	}
+}
+
+func TestDowngradeCapabilities(t *testing.T) {
+	// Without DOWNGRADE(), ProviderNoDS can't host this domain.
+	if err := CheckProviderCapabilities(makeDowngradeDomain()); err == nil {
+		t.Errorf("expected an error without DOWNGRADE()")
+	}
+
+	dc := makeDowngradeDomain(
+		&models.DowngradeConfig{RType: "ALIAS", Action: models.DowngradeResolve},
+		&models.DowngradeConfig{RType: "HTTPS", Action: models.DowngradeDrop},
+	)
+	if errs := checkDowngrades(dc); len(errs) != 0 {
+		t.Errorf("checkDowngrades() = %v", errs)
+	}
+	if err := CheckProviderCapabilities(dc); err != nil {
+		t.Errorf("CheckProviderCapabilities() = %v", err)
+	}
+
+	// Mapping to a type the provider lacks doesn't help.
+	dc = makeDowngradeDomain(
+		&models.DowngradeConfig{RType: "ALIAS", Action: models.DowngradeDrop},
+		&models.DowngradeConfig{RType: "HTTPS", Action: models.DowngradeMap, MapTo: "SVCB"},
+	)
+	if err := CheckProviderCapabilities(dc); err == nil {
+		t.Errorf("expected an error when mapping to an unsupported rtype")
+	}
+}
+
+func TestCheckDowngrades(t *testing.T) {
+	tests := []struct {
+		dg    models.DowngradeConfig
+		isErr bool
+	}{
+		{models.DowngradeConfig{RType: "ALIAS", Action: models.DowngradeResolve}, false},
+		{models.DowngradeConfig{RType: "HTTPS", Action: models.DowngradeMap, MapTo: "SVCB"}, false},
+		{models.DowngradeConfig{RType: "CAA", Action: models.DowngradeDrop}, false},
+		{models.DowngradeConfig{RType: "HTTPS", Action: models.DowngradeResolve}, true},
+		{models.DowngradeConfig{RType: "HTTPS", Action: models.DowngradeMap}, true},
+		{models.DowngradeConfig{RType: "CAA", Action: models.DowngradeMap, MapTo: "TXT"}, true},
+		{models.DowngradeConfig{RType: "ALIAS", Action: models.DowngradeMap, MapTo: "CNAME"}, true},
+		{models.DowngradeConfig{RType: "A", Action: models.DowngradeDrop}, true},
+		{models.DowngradeConfig{RType: "AUTODNSSEC", Action: models.DowngradeDrop}, true},
+		{models.DowngradeConfig{RType: "CAA", Action: "BOGUS"}, true},
+	}
+	for _, tst := range tests {
+		t.Run(tst.dg.RType+"_"+tst.dg.Action, func(t *testing.T) {
+			dg := tst.dg
+			errs := checkDowngrades(&models.DomainConfig{Name: "example.com", Downgrades: []*models.DowngradeConfig{&dg}})
+			if (len(errs) != 0) != tst.isErr {
+				t.Errorf("checkDowngrades(%+v) = %v; want error=%v", dg, errs, tst.isErr)
+			}
+		})
+	}
+}
+
+func TestDowngradeRecords(t *testing.T) {
+	saved := lookupIP
+	defer func() { lookupIP = saved }()
+	lookupIP = func(host string) ([]net.IP, error) {
+		if host != "lb.example.net" {
+			t.Fatalf("unexpected lookup of %q", host)
+		}
+		return []net.IP{net.ParseIP("2001:db8::1"), net.ParseIP("192.0.2.2"), net.ParseIP("192.0.2.1")}, nil
+	}
+
+	dc := makeDowngradeDomain(
+		&models.DowngradeConfig{RType: "ALIAS", Action: models.DowngradeResolve, TTL: 60},
+		&models.DowngradeConfig{RType: "HTTPS", Action: models.DowngradeMap, MapTo: "SVCB"},
+	)
+
+	// A provider with every capability gets the records unchanged.
+	got, warns, err := DowngradeRecords(dc, ProviderFullFeatured)
+	if err != nil || len(warns) != 0 || got != dc {
+		t.Errorf("DowngradeRecords(full) = %v, %v, %v; want dc unchanged", got, warns, err)
+	}
+
+	got, warns, err = DowngradeRecords(dc, ProviderNoDS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warns) != 2 {
+		t.Errorf("got %d warnings, want 2: %v", len(warns), warns)
+	}
+	want := []string{
+		"@ A 192.0.2.1 60",
+		"@ A 192.0.2.2 60",
+		"@ AAAA 2001:db8::1 60",
+		"www SVCB . 300",
+		"mail A 10.0.0.1 300",
+	}
+	if len(got.Records) != len(want) {
+		t.Fatalf("got %d records, want %d: %v", len(got.Records), len(want), got.Records)
+	}
+	for i, r := range got.Records {
+		s := fmt.Sprintf("%s %s %s %d", r.GetLabel(), r.Type, r.GetTargetField(), r.TTL)
+		if s != want[i] {
+			t.Errorf("record %d = %q, want %q", i, s, want[i])
+		}
+	}
+
+	// The original must not be modified.
+	if dc.Records[0].Type != "ALIAS" || dc.Records[1].Type != "HTTPS" {
+		t.Errorf("DowngradeRecords() modified its input: %v", dc.Records)
+	}
+}
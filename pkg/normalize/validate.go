@@ -337,6 +337,18 @@ type Warning struct {
 	error
 }
 
+// atSource prefixes an error about rec with the place (file:line) where
+// rec was defined, if that is known.
+func atSource(rec *models.RecordConfig, err error) error {
+	if rec.Source == "" {
+		return err
+	}
+	if w, ok := err.(Warning); ok {
+		return Warning{fmt.Errorf("%s: %w", rec.Source, w.error)}
+	}
+	return fmt.Errorf("%s: %w", rec.Source, err)
+}
+
 // ValidateAndNormalizeConfig performs and normalization and/or validation of the IR.
 func ValidateAndNormalizeConfig(config *models.DNSConfig) (errs []error) {
 	err := processSplitHorizonDomains(config)
@@ -378,6 +390,7 @@ func ValidateAndNormalizeConfig(config *models.DNSConfig) (errs []error) {
 		// Normalize Records.
 		models.PostProcessRecords(domain.Records)
 		for _, rec := range domain.Records {
+			firstErr := len(errs) // The errors about rec start here.
 			if rec.TTL == 0 {
 				rec.TTL = models.DefaultTTL
 			}
@@ -392,7 +405,7 @@ func ValidateAndNormalizeConfig(config *models.DNSConfig) (errs []error) {
 			}
 			// If label ends with dot, add to the list of errors.
 			if strings.HasSuffix(rec.GetLabel(), ".") {
-				errs = append(errs, fmt.Errorf("label %q does not match D(%q)", rec.GetLabel(), domain.Name))
+				errs = append(errs, atSource(rec, fmt.Errorf("label %q does not match D(%q)", rec.GetLabel(), domain.Name)))
 				return errs // Exit early.
 			}
 
@@ -465,6 +478,10 @@ func ValidateAndNormalizeConfig(config *models.DNSConfig) (errs []error) {
 			if _, ok := rec.Metadata["ignore_name_disable_safety_check"]; ok {
 				errs = append(errs, errors.New("IGNORE_NAME_DISABLE_SAFETY_CHECK no longer supported. Please use DISABLE_IGNORE_SAFETY_CHECK for the entire domain"))
 			}
+
+			for i := firstErr; i < len(errs); i++ {
+				errs[i] = atSource(rec, errs[i])
+			}
 		}
 	}
 
@@ -505,12 +522,18 @@ func ValidateAndNormalizeConfig(config *models.DNSConfig) (errs []error) {
 			errs = append(errs, err)
 		}
 	}
+	// Generate the PTR records of AUTOPTR()
+	errs = append(errs, generateAutoPTRs(config)...)
 
 	for _, d := range config.Domains {
 		// Check that CNAMES don't have to co-exist with any other records
 		errs = append(errs, checkCNAMEs(d)...)
+		// Check that the DOWNGRADE() rules make sense
+		errs = append(errs, checkDowngrades(d)...)
+		// Check that the AUTOPTR() policy makes sense
+		errs = append(errs, checkAutoPTR(d)...)
 		// Check that if any advanced record types are used in a domain, every provider for that domain supports them
-		err := checkProviderCapabilities(d)
+		err := CheckProviderCapabilities(d)
 		if err != nil {
 			errs = append(errs, err)
 		}
@@ -526,6 +549,8 @@ func ValidateAndNormalizeConfig(config *models.DNSConfig) (errs []error) {
 		}
 		// Verify AutoDNSSEC is valid.
 		errs = append(errs, checkAutoDNSSEC(d)...)
+		// Check that COMMENT()s can be stored
+		errs = append(errs, checkComments(d)...)
 	}
 
 	// At this point we've munged anything that needs to be munged, and
@@ -542,7 +567,13 @@ func ValidateAndNormalizeConfig(config *models.DNSConfig) (errs []error) {
 				// be performed.
 				continue
 			}
-			if es := providers.AuditRecords(provider.ProviderBase.ProviderType, domain.Records); len(es) != 0 {
+			// Audit the records as this provider will receive them.
+			recs, _, err := downgradeRecords(domain, provider.ProviderBase.ProviderType, false)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if es := providers.AuditRecords(provider.ProviderBase.ProviderType, recs); len(es) != 0 {
 				for _, e := range es {
 					errs = append(errs, fmt.Errorf("%s rejects domain %s: %w", provider.ProviderBase.ProviderType, domain.Name, e))
 				}
@@ -587,19 +618,45 @@ func checkAutoDNSSEC(dc *models.DomainConfig) (errs []error) {
 	return
 }
 
+// checkComments rejects COMMENT()s that aren't a single line, and
+// warns if a DNS provider of the domain can't store them.
+func checkComments(dc *models.DomainConfig) (errs []error) {
+	found := false
+	for _, rec := range dc.Records {
+		comment, ok := rec.Metadata[models.RecordComment]
+		if !ok {
+			continue
+		}
+		found = true
+		if strings.ContainsAny(comment, "\r\n") {
+			errs = append(errs, atSource(rec, fmt.Errorf("COMMENT() of %s record %s must be a single line", rec.Type, rec.GetLabelFQDN())))
+		}
+	}
+	if !found {
+		return
+	}
+	for _, provider := range dc.DNSProviderInstances {
+		if provider.ProviderType == "-" || providers.ProviderHasCapability(provider.ProviderType, providers.CanUseComments) {
+			continue
+		}
+		errs = append(errs, Warning{fmt.Errorf("domain %s uses COMMENT(), but DNS provider type %s can't store comments. They will be ignored", dc.Name, provider.ProviderType)})
+	}
+	return
+}
+
 func checkCNAMEs(dc *models.DomainConfig) (errs []error) {
 	cnames := map[string]bool{}
 	for _, r := range dc.Records {
 		if r.Type == "CNAME" {
 			if cnames[r.GetLabel()] {
-				errs = append(errs, fmt.Errorf("cannot have multiple CNAMEs with same name: %s", r.GetLabelFQDN()))
+				errs = append(errs, atSource(r, fmt.Errorf("cannot have multiple CNAMEs with same name: %s", r.GetLabelFQDN())))
 			}
 			cnames[r.GetLabel()] = true
 		}
 	}
 	for _, r := range dc.Records {
 		if cnames[r.GetLabel()] && r.Type != "CNAME" {
-			errs = append(errs, fmt.Errorf("cannot have CNAME and %s record with same name: %s", r.Type, r.GetLabelFQDN()))
+			errs = append(errs, atSource(r, fmt.Errorf("cannot have CNAME and %s record with same name: %s", r.Type, r.GetLabelFQDN())))
 		}
 	}
 	return
@@ -609,8 +666,12 @@ func checkDuplicates(records []*models.RecordConfig) (errs []error) {
 	seen := map[string]*models.RecordConfig{}
 	for _, r := range records {
 		diffable := fmt.Sprintf("%s %s %s", r.GetLabelFQDN(), r.Type, r.ToComparableNoTTL())
-		if seen[diffable] != nil {
-			errs = append(errs, fmt.Errorf("exact duplicate record found: %s", diffable))
+		if prev := seen[diffable]; prev != nil {
+			err := fmt.Errorf("exact duplicate record found: %s", diffable)
+			if prev.Source != "" {
+				err = fmt.Errorf("%w (first defined at %s)", err, prev.Source)
+			}
+			errs = append(errs, atSource(r, err))
 		}
 		seen[diffable] = r
 	}
@@ -704,7 +765,7 @@ func commaSepInts(list []int) string {
 	return strings.Join(slist, ",")
 }
 
-// We pull this out of checkProviderCapabilities() so that it's visible within
+// We pull this out of CheckProviderCapabilities() so that it's visible within
 // the package elsewhere, so that our test suite can look at the list of
 // capabilities we're checking and make sure that it's up-to-date.
 var providerCapabilityChecks = []pairTypeCapability{
@@ -789,9 +850,9 @@ func checkProviderDS(pType string, records models.Records) error {
 	return nil
 }
 
-func checkProviderCapabilities(dc *models.DomainConfig) error {
-	// Check if the zone uses a capability that the provider doesn't
-	// support.
+// CheckProviderCapabilities returns an error if the domain uses a
+// capability that one of its DNS providers doesn't support.
+func CheckProviderCapabilities(dc *models.DomainConfig) error {
 	for _, ty := range providerCapabilityChecks {
 		hasAny := false
 		switch ty.rType {
@@ -822,6 +883,13 @@ func checkProviderCapabilities(dc *models.DomainConfig) error {
 			}
 			// fmt.Printf("  (checking if %q can %q for domain %q)\n", provider.ProviderType, ty.rType, dc.Name)
 			if !providerHasAtLeastOneCapability(provider.ProviderType, ty.caps...) {
+				// DOWNGRADE() may say what to do instead.
+				if dg := findDowngrade(dc, ty.rType); dg != nil {
+					if dg.Action == models.DowngradeMap && providerLacks(provider.ProviderType, dg.MapTo) {
+						return fmt.Errorf("domain %s uses DOWNGRADE(%q) to map to %s records, but DNS provider type %s does not support them either", dc.Name, ty.rType, dg.MapTo, provider.ProviderType)
+					}
+					continue
+				}
 				return fmt.Errorf("domain %s uses %s records, but DNS provider type %s does not support them", dc.Name, ty.rType, provider.ProviderType)
 			}
 
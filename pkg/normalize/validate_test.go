@@ -2,6 +2,7 @@ package normalize
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/StackExchange/dnscontrol/v4/models"
@@ -406,6 +407,18 @@ func TestCheckDuplicates_dup_ns(t *testing.T) {
 	}
 }
 
+func TestCheckDuplicates_source(t *testing.T) {
+	records := []*models.RecordConfig{
+		makeRC("@", "example.com", "1.1.1.1", models.RecordConfig{Type: "A", Source: "a.js:1"}),
+		makeRC("@", "example.com", "1.1.1.1", models.RecordConfig{Type: "A", Source: "b.js:2"}),
+	}
+	errs := checkDuplicates(records)
+	want := "b.js:2: exact duplicate record found: example.com A 1.1.1.1 (first defined at a.js:1)"
+	if len(errs) != 1 || errs[0].Error() != want {
+		t.Errorf("checkDuplicates() = %q, want %q", errs, want)
+	}
+}
+
 func TestCheckRecordSetHasMultipleTTLs_err_1type_2ttl(t *testing.T) {
 	records := []*models.RecordConfig{
 		// different ttl per record
@@ -607,3 +620,26 @@ func Test_errorRepeat(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckComments(t *testing.T) {
+	rec := makeRC("www", "example.com", "1.1.1.1", models.RecordConfig{Type: "A", Source: "a.js:1"})
+	rec.Metadata = map[string]string{models.RecordComment: "line 1\nline 2"}
+	dc := &models.DomainConfig{
+		Name:    "example.com",
+		Records: models.Records{rec},
+		DNSProviderInstances: []*models.DNSProviderInstance{
+			{ProviderBase: models.ProviderBase{ProviderType: "-"}},
+			{ProviderBase: models.ProviderBase{ProviderType: "NOCOMMENTS"}},
+		},
+	}
+	errs := checkComments(dc)
+	if len(errs) != 2 {
+		t.Fatalf("checkComments() = %q, want 2 problems", errs)
+	}
+	if want := "a.js:1: COMMENT() of A record www.example.com must be a single line"; errs[0].Error() != want {
+		t.Errorf("got %q, want %q", errs[0], want)
+	}
+	if _, ok := errs[1].(Warning); !ok || !strings.Contains(errs[1].Error(), "NOCOMMENTS can't store comments") {
+		t.Errorf("got %q, want a warning about NOCOMMENTS", errs[1])
+	}
+}
@@ -15,6 +15,10 @@ import (
 	"github.com/miekg/dns"
 )
 
+// CommentMarker starts the zone file comment that holds the COMMENT()
+// of a record. Other comments are not a record's COMMENT().
+const CommentMarker = "COMMENT:"
+
 // MostCommonTTL returns the most common TTL in a set of records. If there is
 // a tie, the highest TTL is selected. This makes the results consistent.
 // NS records are not included in the analysis because Tom said so.
@@ -140,6 +144,10 @@ func (z *ZoneGenData) generateZoneFileHelper(w io.Writer) error {
 				comment = " ; CF_PROXY_ON"
 			}
 		}
+		if c := rr.Metadata[models.RecordComment]; c != "" {
+			// A zone file comment ends at the end of the line.
+			comment += " ; " + CommentMarker + " " + strings.Join(strings.Fields(c), " ")
+		}
 
 		fmt.Fprintf(w, "%s%s%s\n",
 			prefix, FormatLine([]int{10, 5, 2, 5, 0}, []string{name, ttl, "IN", typeStr, target}), comment)
@@ -0,0 +1,160 @@
+// Package rfc2317 generates the records of a classless in-addr.arpa
+// delegation (RFC 2317): the delegation of the reverse zone of a block
+// smaller than a /24, such as a customer's /26, to another zone.
+//
+// The parent zone (the /24) delegates the child zone (named by REV())
+// with NS records, and has a CNAME for each address of the block that
+// points to the address's name in the child zone. The child zone has
+// the PTR records.
+package rfc2317
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v4/pkg/transform"
+)
+
+// Delegation is the delegation of the reverse zone of a block.
+type Delegation struct {
+	Block       string            `json:"block"`       // For example "192.0.2.64/26".
+	Nameservers []string          `json:"nameservers"` // The nameservers of the child zone.
+	PTRs        map[string]string `json:"ptrs"`        // Address to hostname; optional.
+}
+
+// Record is a record of the parent or child zone. It is passed to the
+// record builders of the JavaScript DSL.
+type Record struct {
+	Type   string `json:"type"` // NS, CNAME or PTR
+	Name   string `json:"name"` // Relative to the zone.
+	Target string `json:"target"`
+}
+
+// zones returns the block of the delegation and the names of its parent
+// and child zones. The child zone is named like REV() names it, so it
+// follows REVCOMPAT().
+func (d Delegation) zones() (block netip.Prefix, parent, child string, err error) {
+	block, err = netip.ParsePrefix(d.Block)
+	if err != nil {
+		return block, "", "", fmt.Errorf("not a CIDR block: %w", err)
+	}
+	if !block.Addr().Is4() || block.Bits() < 25 || block.Bits() > 31 {
+		return block, "", "", fmt.Errorf("block %s: must be an IPv4 block from /25 to /31", d.Block)
+	}
+	if block.Masked() != block {
+		return block, "", "", fmt.Errorf("block %s has 1 bits beyond the mask", d.Block)
+	}
+	child, err = transform.ReverseDomainName(d.Block)
+	if err != nil {
+		return block, "", "", err
+	}
+	b := block.Addr().As4()
+	parent = fmt.Sprintf("%d.%d.%d.in-addr.arpa", b[2], b[1], b[0])
+	return block, parent, child, nil
+}
+
+// Check returns the problems of the delegation: the block must be an
+// IPv4 block from /25 to /31, there must be nameservers, and each PTR
+// address must be in the block and be listed only once, so that every
+// address maps to exactly one name.
+func (d Delegation) Check() []error {
+	block, _, _, err := d.zones()
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	if len(d.Nameservers) == 0 {
+		errs = append(errs, fmt.Errorf("block %s: no nameservers", d.Block))
+	}
+	for _, ns := range d.Nameservers {
+		if ns == "" || !strings.Contains(strings.TrimSuffix(ns, "."), ".") {
+			errs = append(errs, fmt.Errorf("block %s: nameserver %q must be fully qualified", d.Block, ns))
+		}
+	}
+
+	// "192.0.2.65" and "::ffff:192.0.2.65" are the same address.
+	seen := map[netip.Addr]string{}
+	for _, key := range sortedKeys(d.PTRs) {
+		addr, err := netip.ParseAddr(key)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("block %s: %q is not an IP address", d.Block, key))
+			continue
+		}
+		addr = addr.Unmap()
+		if !block.Contains(addr) {
+			errs = append(errs, fmt.Errorf("block %s: address %s is outside of the block", d.Block, key))
+			continue
+		}
+		if other, ok := seen[addr]; ok {
+			errs = append(errs, fmt.Errorf("block %s: address %s is listed twice (as %s and %s)", d.Block, addr, other, key))
+			continue
+		}
+		seen[addr] = key
+		if d.PTRs[key] == "" {
+			errs = append(errs, fmt.Errorf("block %s: address %s has no hostname", d.Block, key))
+		}
+	}
+	return errs
+}
+
+// Records returns the records that the delegation needs in zone, which
+// must be either its parent or its child zone:
+//
+//   - In the parent zone, the NS records of the child zone, and a CNAME
+//     for each address of the block (including the network and broadcast
+//     addresses) to its name in the child zone.
+//   - In the child zone, the PTR records.
+//
+// The delegation must have been checked with Check.
+func Records(d Delegation, zone string) ([]Record, error) {
+	block, parent, child, err := d.zones()
+	if err != nil {
+		return nil, err
+	}
+	zone = strings.ToLower(strings.TrimSuffix(zone, "."))
+
+	var recs []Record
+	switch zone {
+	case parent:
+		label := strings.TrimSuffix(child, "."+parent)
+		for _, ns := range d.Nameservers {
+			recs = append(recs, Record{Type: "NS", Name: label, Target: fqdn(ns)})
+		}
+		for addr := block.Addr(); block.Contains(addr); addr = addr.Next() {
+			n := fmt.Sprint(addr.As4()[3])
+			recs = append(recs, Record{Type: "CNAME", Name: n, Target: n + "." + child + "."})
+		}
+	case child:
+		for _, key := range sortedKeys(d.PTRs) {
+			addr, err := netip.ParseAddr(key)
+			if err != nil {
+				return nil, err
+			}
+			label, err := transform.PtrNameMagic(addr.Unmap().String(), child)
+			if err != nil {
+				return nil, err
+			}
+			recs = append(recs, Record{Type: "PTR", Name: label, Target: fqdn(d.PTRs[key])})
+		}
+	default:
+		return nil, fmt.Errorf("block %s: the domain must be %s or %s, not %s", d.Block, parent, child, zone)
+	}
+	return recs, nil
+}
+
+// fqdn adds the trailing dot to a fully qualified name.
+func fqdn(name string) string {
+	return strings.TrimSuffix(name, ".") + "."
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
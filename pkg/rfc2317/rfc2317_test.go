@@ -0,0 +1,97 @@
+package rfc2317
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRecords(t *testing.T) {
+	d := Delegation{
+		Block:       "192.0.2.64/30",
+		Nameservers: []string{"ns1.customer.example", "ns2.customer.example."},
+		PTRs: map[string]string{
+			"192.0.2.65": "router.customer.example",
+			"192.0.2.66": "www.customer.example.",
+		},
+	}
+	if errs := d.Check(); len(errs) != 0 {
+		t.Fatalf("Check() = %v", errs)
+	}
+
+	got, err := Records(d, "2.0.192.in-addr.arpa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Record{
+		{"NS", "64/30", "ns1.customer.example."},
+		{"NS", "64/30", "ns2.customer.example."},
+		{"CNAME", "64", "64.64/30.2.0.192.in-addr.arpa."},
+		{"CNAME", "65", "65.64/30.2.0.192.in-addr.arpa."},
+		{"CNAME", "66", "66.64/30.2.0.192.in-addr.arpa."},
+		{"CNAME", "67", "67.64/30.2.0.192.in-addr.arpa."},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Records(parent) = %v, want %v", got, want)
+	}
+
+	got, err = Records(d, "64/30.2.0.192.in-addr.arpa.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []Record{
+		{"PTR", "65", "router.customer.example."},
+		{"PTR", "66", "www.customer.example."},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Records(child) = %v, want %v", got, want)
+	}
+
+	if _, err := Records(d, "3.0.192.in-addr.arpa"); err == nil {
+		t.Error("Records() of an unrelated zone succeeded")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		d    Delegation
+		want []string
+	}{
+		{Delegation{Block: "192.0.2.0/24", Nameservers: []string{"ns.example.com"}}, []string{"/25 to /31"}},
+		{Delegation{Block: "192.0.2.0/32", Nameservers: []string{"ns.example.com"}}, []string{"/25 to /31"}},
+		{Delegation{Block: "2001:db8::/120", Nameservers: []string{"ns.example.com"}}, []string{"/25 to /31"}},
+		{Delegation{Block: "192.0.2.65/26", Nameservers: []string{"ns.example.com"}}, []string{"beyond the mask"}},
+		{Delegation{Block: "192.0.2.64/26"}, []string{"no nameservers"}},
+		{Delegation{Block: "192.0.2.64/26", Nameservers: []string{"ns"}}, []string{"fully qualified"}},
+		{
+			Delegation{
+				Block:       "192.0.2.64/26",
+				Nameservers: []string{"ns.example.com"},
+				PTRs: map[string]string{
+					"192.0.2.1":         "a.example.com",
+					"192.0.2.65":        "b.example.com",
+					"::ffff:192.0.2.65": "c.example.com",
+					"192.0.2.66":        "",
+					"host":              "d.example.com",
+				},
+			},
+			[]string{"outside of the block", "listed twice", "no hostname", "not an IP address"},
+		},
+	}
+	for _, tt := range tests {
+		errs := tt.d.Check()
+		if len(errs) != len(tt.want) {
+			t.Errorf("Check(%v) = %v, want %d errors", tt.d, errs, len(tt.want))
+			continue
+		}
+		for _, want := range tt.want {
+			found := false
+			for _, err := range errs {
+				found = found || strings.Contains(err.Error(), want)
+			}
+			if !found {
+				t.Errorf("Check(%v) = %v, want an error about %q", tt.d, errs, want)
+			}
+		}
+	}
+}
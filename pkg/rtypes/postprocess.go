@@ -17,6 +17,7 @@ func PostProcess(domains []*models.DomainConfig) error {
 				TTL:      rawRec.TTL,
 				Name:     rawRec.Args[0].(string),
 				Metadata: map[string]string{},
+				Source:   rawRec.Source,
 			}
 
 			// Copy the metadata (convert everything to string)
@@ -0,0 +1,112 @@
+// Package soaserial implements the serial number arithmetic of RFC 1982
+// and the policies that choose the serial number of a zone's next SOA.
+//
+// It is shared by the providers that generate the SOA themselves, such
+// as BIND and AXFRDDNS.
+package soaserial
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+)
+
+// Policy chooses the serial number of a zone's next SOA. The names are
+// the same as Knot's `serial-policy`, plus contenthash.
+type Policy string
+
+// The policies.
+const (
+	DateSerial  Policy = "dateserial"  // YYYYMMDDnn.
+	UnixTime    Policy = "unixtime"    // The Unix time of the change.
+	Increment   Policy = "increment"   // The old serial number plus one.
+	ContentHash Policy = "contenthash" // The old serial number plus a step derived from the records.
+)
+
+// maxStep is the largest increment of a serial number that RFC 1982
+// allows: 2^31-1.
+const maxStep = 1<<31 - 1
+
+// ParsePolicy returns the policy named s, or def if s is empty.
+func ParsePolicy(s string, def Policy) (Policy, error) {
+	switch p := Policy(s); p {
+	case "":
+		return def, nil
+	case DateSerial, UnixTime, Increment, ContentHash:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown serial-policy %q: must be dateserial, unixtime, increment or contenthash", s)
+	}
+}
+
+// Greater reports whether the serial number s1 is greater than s2,
+// according to the serial number arithmetic of RFC 1982. Two serial
+// numbers that are exactly 2^31 apart are not comparable, so neither
+// is greater.
+func Greater(s1, s2 uint32) bool {
+	return s1 != s2 && s1-s2 < 1<<31
+}
+
+// Add returns s plus n, which RFC 1982 limits to 2^31-1.
+func Add(s, n uint32) uint32 {
+	if n > maxStep {
+		panic(fmt.Sprintf("soaserial: increment %d is larger than 2^31-1", n))
+	}
+	return s + n
+}
+
+// Next returns the serial number that follows old, at the time now.
+// content is only used by ContentHash (see Content).
+//
+// Secondaries ignore a serial number that isn't greater than theirs, so
+// when the policy's draft would go backwards (for example after a
+// switch from unixtime to dateserial, or after more than 99 changes in
+// a day), old is incremented instead. Zero is never returned, as some
+// servers treat it as "no serial number".
+func Next(policy Policy, old uint32, now time.Time, content []byte) uint32 {
+	var draft uint32
+	switch policy {
+	case DateSerial:
+		y, m, d := now.UTC().Date()
+		draft = uint32(y*1000000 + int(m)*10000 + d*100)
+	case UnixTime:
+		draft = uint32(now.Unix())
+	case ContentHash:
+		// The same change to the same zone gives the same serial
+		// number, wherever it is made.
+		h := fnv.New32a()
+		h.Write(content)
+		draft = Add(old, 1+h.Sum32()%(maxStep-1))
+	default:
+		draft = Add(old, 1)
+	}
+	if !Greater(draft, old) {
+		draft = Add(old, 1)
+	}
+	if draft == 0 {
+		draft = 1
+	}
+	return draft
+}
+
+// Content returns the records in a form that doesn't depend on their
+// order, for ContentHash. The SOA is left out, since its serial number
+// is what is being chosen.
+func Content(records models.Records) []byte {
+	lines := make([]string, 0, len(records))
+	for _, r := range records {
+		if r.Type != "SOA" {
+			lines = append(lines, fmt.Sprintf("%s %d %s %s", r.NameFQDN, r.TTL, r.Type, r.ToComparableNoTTL()))
+		}
+	}
+	sort.Strings(lines)
+	var b []byte
+	for _, line := range lines {
+		b = append(b, line...)
+		b = append(b, '\n')
+	}
+	return b
+}
@@ -0,0 +1,96 @@
+package soaserial
+
+import (
+	"testing"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+)
+
+func TestGreater(t *testing.T) {
+	tests := []struct {
+		s1, s2 uint32
+		want   bool
+	}{
+		{2, 1, true},
+		{1, 2, false},
+		{1, 1, false},
+		{0, 0xFFFFFFFF, true}, // Wraparound.
+		{0xFFFFFFFF, 0, false},
+		{1 << 31, 0, false}, // Not comparable.
+		{0, 1 << 31, false},
+		{1<<31 - 1, 0, true},
+		{1<<31 + 1, 0, false},
+	}
+	for _, tt := range tests {
+		if got := Greater(tt.s1, tt.s2); got != tt.want {
+			t.Errorf("Greater(%d, %d) = %v, want %v", tt.s1, tt.s2, got, tt.want)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	now := time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		policy Policy
+		old    uint32
+		want   uint32
+	}{
+		{Increment, 41, 42},
+		{Increment, 0xFFFFFFFF, 1}, // Zero is skipped.
+		{UnixTime, 1, uint32(now.Unix())},
+		{UnixTime, uint32(now.Unix()), uint32(now.Unix()) + 1},
+		{DateSerial, 1, 2024030500},
+		{DateSerial, 2024030500, 2024030501},
+		{DateSerial, 2024030599, 2024030600},
+		// More than 2^31 ahead is behind, according to RFC 1982.
+		{DateSerial, 4000000000, 4000000001},
+		// A switch from dateserial to unixtime would go backwards.
+		{UnixTime, 2024030500, 2024030501},
+	}
+	for _, tt := range tests {
+		if got := Next(tt.policy, tt.old, now, nil); got != tt.want {
+			t.Errorf("Next(%s, %d) = %d, want %d", tt.policy, tt.old, got, tt.want)
+		}
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	a := &models.RecordConfig{Type: "A", TTL: 300}
+	a.SetLabel("www", "example.com")
+	a.SetTarget("192.0.2.1")
+	b := &models.RecordConfig{Type: "A", TTL: 300}
+	b.SetLabel("www", "example.com")
+	b.SetTarget("192.0.2.2")
+
+	now := time.Now()
+	content := Content(models.Records{a, b})
+	if got := Content(models.Records{b, a}); string(got) != string(content) {
+		t.Errorf("Content() depends on the order of the records:\n%s\n%s", got, content)
+	}
+
+	for _, old := range []uint32{0, 1 << 30, 0xFFFFFFF0} {
+		next := Next(ContentHash, old, now, content)
+		if !Greater(next, old) {
+			t.Errorf("Next(contenthash, %d) = %d, which isn't greater", old, next)
+		}
+		if again := Next(ContentHash, old, now.Add(time.Hour), content); again != next {
+			t.Errorf("Next(contenthash, %d) = %d, then %d", old, next, again)
+		}
+	}
+	if Next(ContentHash, 1, now, content) == Next(ContentHash, 1, now, Content(models.Records{a})) {
+		t.Error("Next(contenthash) is the same for different records")
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	if p, err := ParsePolicy("", DateSerial); p != DateSerial || err != nil {
+		t.Errorf("ParsePolicy(\"\") = %q, %v; want the default", p, err)
+	}
+	if p, err := ParsePolicy("unixtime", DateSerial); p != UnixTime || err != nil {
+		t.Errorf("ParsePolicy(unixtime) = %q, %v", p, err)
+	}
+	if _, err := ParsePolicy("yyyymmdd", DateSerial); err == nil {
+		t.Error("ParsePolicy() of an unknown policy succeeded")
+	}
+}
@@ -0,0 +1,520 @@
+// Package yamlconfig reads a DNS configuration written in YAML and
+// returns the same models.DNSConfig that executing dnsconfig.js
+// would. It is an alternative to the JavaScript DSL for people that
+// would rather review data than code.
+//
+// The file is decoded strictly: unknown keys, values of the wrong
+// type, and references to undeclared registrars or DNS providers are
+// errors. Every error includes the file name and line number.
+package yamlconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Error is a problem found at a specific line of the file.
+type Error struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+}
+
+// The schema of the file.
+
+type file struct {
+	Registrars   []providerSpec `yaml:"registrars"`
+	DNSProviders []providerSpec `yaml:"dns_providers"`
+	Domains      []domainSpec   `yaml:"domains"`
+}
+
+// providerSpec is like NewRegistrar() and NewDnsProvider().
+type providerSpec struct {
+	Name string         `yaml:"name"`
+	Type string         `yaml:"type"`
+	Meta map[string]any `yaml:"meta"`
+}
+
+// domainSpec is like D().
+type domainSpec struct {
+	Name                     string           `yaml:"name"`
+	Registrar                string           `yaml:"registrar"`
+	DNSProviders             providerBindings `yaml:"dns_providers"`
+	DefaultTTL               ttl              `yaml:"default_ttl"`    // DefaultTTL()
+	NameserverTTL            ttl              `yaml:"nameserver_ttl"` // NAMESERVER_TTL()
+	Nameservers              []string         `yaml:"nameservers"`    // NAMESERVER()
+	Meta                     stringMap        `yaml:"meta"`
+	NoPurge                  bool             `yaml:"no_purge"`                    // NO_PURGE
+	AutoDNSSEC               string           `yaml:"autodnssec"`                  // AUTODNSSEC_ON/AUTODNSSEC_OFF
+	DisableIgnoreSafetyCheck bool             `yaml:"disable_ignore_safety_check"` // DISABLE_IGNORE_SAFETY_CHECK
+	Ignore                   []ignoreSpec     `yaml:"ignore"`                      // IGNORE()
+	Downgrade                []downgradeSpec  `yaml:"downgrade"`                   // DOWNGRADE()
+	AutoPTR                  string           `yaml:"autoptr"`                     // AUTOPTR()
+	Records                  []recordSpec     `yaml:"records"`
+	EnsureAbsent             []recordSpec     `yaml:"ensure_absent"` // ENSURE_ABSENT()
+}
+
+type recordSpec struct {
+	Type       string            `yaml:"type"`
+	Name       string            `yaml:"name"`
+	Target     string            `yaml:"target"`
+	TTL        ttl               `yaml:"ttl"`
+	Meta       stringMap         `yaml:"meta"`
+	Args       []any             `yaml:"args"`        // Records built from raw arguments (CF_SINGLE_REDIRECT)
+	R53Alias   map[string]string `yaml:"r53_alias"`   // R53_ALIAS
+	AzureAlias map[string]string `yaml:"azure_alias"` // AZURE_ALIAS
+}
+
+type ignoreSpec struct {
+	Label  string `yaml:"label"`
+	Type   string `yaml:"type"`
+	Target string `yaml:"target"`
+}
+
+type downgradeSpec struct {
+	Type   string `yaml:"type"`
+	Action string `yaml:"action"`
+	MapTo  string `yaml:"map_to"`
+	TTL    ttl    `yaml:"ttl"`
+}
+
+// ttl is a number of seconds, or a string like "5m" (as with DefaultTTL()).
+type ttl uint32
+
+func (t *ttl) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind != yaml.ScalarNode {
+		return &Error{Line: n.Line, Msg: "a TTL must be a number or a duration like \"5m\""}
+	}
+	v, err := parseDuration(n.Value)
+	if err != nil {
+		return &Error{Line: n.Line, Msg: err.Error()}
+	}
+	*t = ttl(v)
+	return nil
+}
+
+// parseDuration is like stringToDuration() in helpers.js.
+func parseDuration(s string) (uint32, error) {
+	mult := uint64(1)
+	if s != "" {
+		switch s[len(s)-1] {
+		case 's', 'S':
+			s = s[:len(s)-1]
+		case 'm', 'M':
+			mult, s = 60, s[:len(s)-1]
+		case 'h', 'H':
+			mult, s = 60*60, s[:len(s)-1]
+		case 'd', 'D':
+			mult, s = 24*60*60, s[:len(s)-1]
+		case 'w', 'W':
+			mult, s = 7*24*60*60, s[:len(s)-1]
+		}
+	}
+	v, err := strconv.ParseUint(s, 10, 32)
+	if err != nil || v*mult > 1<<32-1 {
+		return 0, fmt.Errorf("invalid TTL %q", s)
+	}
+	return uint32(v * mult), nil
+}
+
+// stringMap is a map of metadata. Values may be any scalar (like
+// "true" or 42) and are stored as strings, as in dnsconfig.js.
+type stringMap map[string]string
+
+func (m *stringMap) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind != yaml.MappingNode {
+		return &Error{Line: n.Line, Msg: "meta must be a mapping"}
+	}
+	*m = stringMap{}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		if v.Kind != yaml.ScalarNode {
+			return &Error{Line: v.Line, Msg: fmt.Sprintf("meta %q must be a scalar", k.Value)}
+		}
+		(*m)[k.Value] = v.Value
+	}
+	return nil
+}
+
+// providerBindings is like DnsProvider(). It is either a list of names
+// or a mapping from name to the number of nameservers to use (-1 for
+// all, 0 for none).
+type providerBindings map[string]int
+
+func (p *providerBindings) UnmarshalYAML(n *yaml.Node) error {
+	*p = providerBindings{}
+	switch n.Kind {
+	case yaml.SequenceNode:
+		for _, item := range n.Content {
+			if item.Kind != yaml.ScalarNode {
+				return &Error{Line: item.Line, Msg: "dns_providers items must be names"}
+			}
+			(*p)[item.Value] = -1
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			count, err := strconv.Atoi(v.Value)
+			if v.Kind != yaml.ScalarNode || err != nil || count < -1 {
+				return &Error{Line: v.Line, Msg: fmt.Sprintf("dns_providers %q: the nameserver count must be -1 (all), 0, or more", k.Value)}
+			}
+			(*p)[k.Value] = count
+		}
+	default:
+		return &Error{Line: n.Line, Msg: "dns_providers must be a list or a mapping"}
+	}
+	return nil
+}
+
+// Load reads a YAML configuration file.
+func Load(filename string) (*models.DNSConfig, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(content, filename)
+}
+
+// Parse parses a YAML configuration. filename is used in error
+// messages and to record where each record was defined.
+func Parse(content []byte, filename string) (*models.DNSConfig, error) {
+	var f file
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil {
+		return nil, decodeError(filename, err)
+	}
+
+	// Decode again to learn where each item is, for error messages.
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, decodeError(filename, err)
+	}
+
+	c := &converter{filename: filename}
+	cfg := c.convert(&f, &root)
+	if len(c.errs) != 0 {
+		return nil, errors.Join(c.errs...)
+	}
+	return cfg, nil
+}
+
+// decodeError adds the filename to the errors of the YAML decoder.
+func decodeError(filename string, err error) error {
+	var e *Error
+	if errors.As(err, &e) {
+		e.File = filename
+		return e
+	}
+	var te *yaml.TypeError
+	if errors.As(err, &te) {
+		// The messages are already of the form "line N: ...".
+		return fmt.Errorf("%s: %s", filename, strings.Join(te.Errors, "; "))
+	}
+	return fmt.Errorf("%s: %w", filename, err)
+}
+
+// converter turns the schema into models and collects the errors.
+type converter struct {
+	filename string
+	errs     []error
+}
+
+func (c *converter) errorf(line int, format string, args ...any) {
+	c.errs = append(c.errs, &Error{File: c.filename, Line: line, Msg: fmt.Sprintf(format, args...)})
+}
+
+func (c *converter) convert(f *file, root *yaml.Node) *models.DNSConfig {
+	var top *yaml.Node
+	if len(root.Content) != 0 {
+		top = root.Content[0]
+	}
+	cfg := &models.DNSConfig{
+		Registrars:   []*models.RegistrarConfig{},
+		DNSProviders: []*models.DNSProviderConfig{},
+		Domains:      []*models.DomainConfig{},
+	}
+
+	registrars := map[string]bool{}
+	for i, p := range f.Registrars {
+		line := itemLine(top, i, "registrars")
+		name, typ, meta := c.provider(p, line, registrars)
+		cfg.Registrars = append(cfg.Registrars, &models.RegistrarConfig{Name: name, Type: typ, Metadata: meta})
+	}
+	dsps := map[string]bool{}
+	for i, p := range f.DNSProviders {
+		line := itemLine(top, i, "dns_providers")
+		name, typ, meta := c.provider(p, line, dsps)
+		cfg.DNSProviders = append(cfg.DNSProviders, &models.DNSProviderConfig{Name: name, Type: typ, Metadata: meta})
+	}
+
+	seen := map[string]bool{}
+	domainNodes := items(top, "domains")
+	for i, d := range f.Domains {
+		dn := domainNodes[i]
+		if d.Name == "" {
+			c.errorf(dn.Line, "domain has no name")
+			continue
+		}
+		if seen[d.Name] {
+			c.errorf(dn.Line, "%s is declared more than once", d.Name)
+			continue
+		}
+		seen[d.Name] = true
+		if !registrars[d.Registrar] {
+			c.errorf(dn.Line, "%s: registrar %q is not declared in registrars", d.Name, d.Registrar)
+		}
+		for name := range d.DNSProviders {
+			if !dsps[name] {
+				c.errorf(dn.Line, "%s: DNS provider %q is not declared in dns_providers", d.Name, name)
+			}
+		}
+		cfg.Domains = append(cfg.Domains, c.domain(d, dn))
+	}
+	return cfg
+}
+
+func (c *converter) provider(p providerSpec, line int, seen map[string]bool) (string, string, json.RawMessage) {
+	if p.Name == "" {
+		c.errorf(line, "name is required")
+	} else if seen[p.Name] {
+		c.errorf(line, "%q is declared more than once", p.Name)
+	}
+	seen[p.Name] = true
+	typ := p.Type
+	if typ == "" {
+		typ = "-" // Taken from creds.json.
+	}
+	var meta json.RawMessage
+	if p.Meta != nil {
+		var err error
+		if meta, err = json.Marshal(p.Meta); err != nil {
+			c.errorf(line, "meta: %s", err)
+		}
+	}
+	return p.Name, typ, meta
+}
+
+func (c *converter) domain(d domainSpec, n *yaml.Node) *models.DomainConfig {
+	// The origin used to parse targets ("example.com!tag" -> "example.com").
+	origin, _, _ := strings.Cut(d.Name, "!")
+
+	dc := &models.DomainConfig{
+		Name:             d.Name,
+		RegistrarName:    d.Registrar,
+		DNSProviderNames: map[string]int(d.DNSProviders),
+		Metadata:         map[string]string(d.Meta),
+		Records:          models.Records{},
+		KeepUnknown:      d.NoPurge,
+		UnmanagedUnsafe:  d.DisableIgnoreSafetyCheck,
+	}
+	if dc.DNSProviderNames == nil {
+		dc.DNSProviderNames = map[string]int{}
+	}
+	if dc.Metadata == nil {
+		dc.Metadata = map[string]string{}
+	}
+	if d.NameserverTTL != 0 {
+		dc.Metadata["ns_ttl"] = strconv.FormatUint(uint64(d.NameserverTTL), 10)
+	}
+	for _, ns := range d.Nameservers {
+		dc.Nameservers = append(dc.Nameservers, &models.Nameserver{Name: ns})
+	}
+
+	switch d.AutoDNSSEC {
+	case "", "on", "off":
+		dc.AutoDNSSEC = d.AutoDNSSEC
+	default:
+		c.errorf(n.Line, "%s: autodnssec must be \"on\" or \"off\"", d.Name)
+	}
+
+	for i, ig := range d.Ignore {
+		if ig.Label == "" && ig.Type == "" && ig.Target == "" {
+			c.errorf(itemLine(n, i, "ignore"), "an ignore rule needs at least one of label, type, or target")
+			continue
+		}
+		dc.Unmanaged = append(dc.Unmanaged, &models.UnmanagedConfig{
+			LabelPattern:  orStar(ig.Label),
+			RTypePattern:  orStar(ig.Type),
+			TargetPattern: orStar(ig.Target),
+		})
+	}
+
+	// The policy is checked by normalize.
+	dc.AutoPTR = strings.ToLower(d.AutoPTR)
+
+	for i, dg := range d.Downgrade {
+		line := itemLine(n, i, "downgrade")
+		action := strings.ToUpper(dg.Action)
+		if dg.Type == "" || action == "" {
+			c.errorf(line, "downgrade needs a type and an action")
+			continue
+		}
+		if (action == models.DowngradeMap) != (dg.MapTo != "") {
+			c.errorf(line, "map_to must be used with (and only with) action MAP")
+			continue
+		}
+		dc.Downgrades = append(dc.Downgrades, &models.DowngradeConfig{
+			RType:  strings.ToUpper(dg.Type),
+			Action: action,
+			MapTo:  strings.ToUpper(dg.MapTo),
+			TTL:    uint32(dg.TTL),
+		})
+	}
+
+	for _, list := range []struct {
+		key   string
+		specs []recordSpec
+		dest  *models.Records
+	}{
+		{"records", d.Records, &dc.Records},
+		{"ensure_absent", d.EnsureAbsent, &dc.EnsureAbsent},
+	} {
+		for i, r := range list.specs {
+			line := itemLine(n, i, list.key)
+			rec, raw, err := r.toRecord(origin, uint32(d.DefaultTTL))
+			source := fmt.Sprintf("%s:%d", c.filename, line)
+			switch {
+			case err != nil:
+				c.errorf(line, "%s: %s", d.Name, err)
+			case raw != nil && list.key == "ensure_absent":
+				c.errorf(line, "%s: %s records can't be used in ensure_absent", d.Name, r.Type)
+			case raw != nil:
+				raw.Source = source
+				dc.RawRecords = append(dc.RawRecords, *raw)
+			default:
+				rec.Source = source
+				*list.dest = append(*list.dest, rec)
+			}
+		}
+	}
+	return dc
+}
+
+func orStar(s string) string {
+	if s == "" {
+		return "*"
+	}
+	return s
+}
+
+// rawTypes are the rtypes that are built from their arguments (like
+// the dnsconfig.js functions of the same name). The value is the
+// rtype stored in the IR.
+var rawTypes = map[string]string{
+	"CF_SINGLE_REDIRECT": "CLOUDFLAREAPI_SINGLE_REDIRECT",
+}
+
+// parsedTypes are the rtypes whose target is parsed from the usual
+// zonefile representation (for example "10 mx.example.com." for MX).
+// Targets of other rtypes are stored verbatim.
+var parsedTypes = map[string]bool{
+	"A": true, "AAAA": true, "CAA": true, "DHCID": true, "DNSKEY": true,
+	"DS": true, "HTTPS": true, "LOC": true, "MX": true, "NAPTR": true,
+	"SOA": true, "SRV": true, "SSHFP": true, "SVCB": true, "TLSA": true,
+}
+
+// toRecord converts a record. Records of the rawTypes are returned as
+// a RawRecordConfig instead.
+func (r recordSpec) toRecord(origin string, defaultTTL uint32) (*models.RecordConfig, *models.RawRecordConfig, error) {
+	rtype := strings.ToUpper(r.Type)
+	if rtype == "" {
+		return nil, nil, errors.New("record has no type")
+	}
+	recTTL := uint32(r.TTL)
+	if recTTL == 0 {
+		recTTL = defaultTTL
+	}
+
+	if irType, ok := rawTypes[rtype]; ok {
+		if len(r.Args) == 0 || r.Name != "" || r.Target != "" {
+			return nil, nil, fmt.Errorf("%s records take args (and no name or target)", rtype)
+		}
+		raw := &models.RawRecordConfig{Type: irType, Args: r.Args, TTL: recTTL}
+		if len(r.Meta) != 0 {
+			meta := map[string]any{}
+			for k, v := range r.Meta {
+				meta[k] = v
+			}
+			raw.Metas = []map[string]any{meta}
+		}
+		return nil, raw, nil
+	}
+	if r.Args != nil {
+		return nil, nil, fmt.Errorf("%s records don't take args", rtype)
+	}
+	if r.Name == "" {
+		return nil, nil, fmt.Errorf("%s record has no name (use \"@\" for the apex)", rtype)
+	}
+	if r.Target == "" {
+		return nil, nil, fmt.Errorf("%s %q has no target", rtype, r.Name)
+	}
+	if (r.R53Alias != nil) != (rtype == "R53_ALIAS") || (r.AzureAlias != nil) != (rtype == "AZURE_ALIAS") {
+		return nil, nil, fmt.Errorf("%s %q: r53_alias and azure_alias are required by (and only valid for) R53_ALIAS and AZURE_ALIAS", rtype, r.Name)
+	}
+
+	rec := &models.RecordConfig{
+		Name:       r.Name,
+		TTL:        recTTL,
+		Metadata:   map[string]string(r.Meta),
+		R53Alias:   r.R53Alias,
+		AzureAlias: r.AzureAlias,
+	}
+	if rec.Metadata == nil {
+		rec.Metadata = map[string]string{}
+	}
+	if rtype == "R53_ALIAS" && rec.R53Alias["evaluate_target_health"] == "" {
+		rec.R53Alias["evaluate_target_health"] = "false" // As R53_ALIAS() does.
+	}
+
+	var err error
+	switch {
+	case rtype == "TXT" || rtype == "SPF":
+		// The target is the text itself, without quoting.
+		rec.Type = rtype
+		err = rec.SetTargetTXT(r.Target)
+	case parsedTypes[rtype]:
+		err = rec.PopulateFromString(rtype, r.Target, origin)
+	default:
+		rec.Type = rtype
+		err = rec.SetTarget(r.Target)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s %q: %w", rtype, r.Name, err)
+	}
+	return rec, nil, nil
+}
+
+// items returns the items of the sequence at key in the mapping n.
+func items(n *yaml.Node, key string) []*yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1].Content
+		}
+	}
+	return nil
+}
+
+// itemLine returns the line of item i of the sequence at key in the
+// mapping n.
+func itemLine(n *yaml.Node, i int, key string) int {
+	if l := items(n, key); i < len(l) {
+		return l[i].Line
+	}
+	return 0
+}
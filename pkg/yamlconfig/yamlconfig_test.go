@@ -0,0 +1,169 @@
+package yamlconfig
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/StackExchange/dnscontrol/v4/pkg/js"
+	"github.com/StackExchange/dnscontrol/v4/pkg/normalize"
+	"github.com/StackExchange/dnscontrol/v4/pkg/rtypes"
+)
+
+const parityYAML = `
+registrars:
+  - name: none
+    type: NONE
+dns_providers:
+  - name: bind
+    type: BIND
+    meta:
+      default_soa:
+        master: ns1.example.com.
+  - name: other
+domains:
+  - name: example.com
+    registrar: none
+    dns_providers:
+      bind: -1
+      other: 0
+    default_ttl: 1h
+    nameserver_ttl: 2d
+    autodnssec: "on"
+    no_purge: true
+    ignore:
+      - label: "legacy-*"
+        type: "A,CNAME"
+    downgrade:
+      - {type: https, action: map, map_to: svcb}
+    records:
+      - {type: A, name: "@", target: 1.2.3.4}
+      - {type: MX, name: "@", target: 10 mx.example.com., ttl: 300}
+      - {type: TXT, name: "@", target: "v=spf1 -all"}
+      - {type: CNAME, name: www, target: "@", meta: {cloudflare_proxy: on}}
+      - {type: CAA, name: "@", target: 0 issue "letsencrypt.org"}
+      - {type: SRV, name: _sip._tcp, target: 10 60 5060 sip.example.com.}
+    ensure_absent:
+      - {type: A, name: old, target: 10.0.0.1}
+  - name: example.com!inside
+    registrar: none
+    dns_providers: [bind]
+    nameservers: [ns1.example.com.]
+    records:
+      - {type: A, name: "@", target: 10.1.2.3}
+  - name: 3.2.1.in-addr.arpa
+    registrar: none
+    dns_providers: [bind]
+    autoptr: first
+`
+
+const parityJS = `
+var REG = NewRegistrar("none", "NONE");
+var BIND = NewDnsProvider("bind", "BIND", {default_soa: {master: "ns1.example.com."}});
+var OTHER = NewDnsProvider("other");
+D("example.com", REG, DnsProvider(BIND), DnsProvider(OTHER, 0),
+  DefaultTTL("1h"),
+  NAMESERVER_TTL("2d"),
+  AUTODNSSEC_ON,
+  NO_PURGE,
+  IGNORE("legacy-*", "A,CNAME"),
+  DOWNGRADE("HTTPS", "MAP", "SVCB"),
+  A("@", "1.2.3.4"),
+  MX("@", 10, "mx.example.com.", TTL(300)),
+  TXT("@", "v=spf1 -all"),
+  CNAME("www", "@", {cloudflare_proxy: "on"}),
+  CAA("@", "issue", "letsencrypt.org"),
+  SRV("_sip._tcp", 10, 60, 5060, "sip.example.com."),
+  A("old", "10.0.0.1", ENSURE_ABSENT_REC()),
+END);
+D("example.com!inside", REG, DnsProvider(BIND),
+  NAMESERVER("ns1.example.com."),
+  A("@", "10.1.2.3"),
+END);
+D(REV("1.2.3.0/24"), REG, DnsProvider(BIND),
+  AUTOPTR("first"),
+END);
+`
+
+// normalized returns the config as it is after validation, as JSON.
+func normalized(t *testing.T, cfg *models.DNSConfig) string {
+	t.Helper()
+	if err := rtypes.PostProcess(cfg.Domains); err != nil {
+		t.Fatal(err)
+	}
+	if errs := normalize.ValidateAndNormalizeConfig(cfg); len(errs) != 0 {
+		t.Fatalf("validation errors: %v", errs)
+	}
+	// The records were defined in different places.
+	for _, dc := range cfg.Domains {
+		for _, rec := range append(dc.Records, dc.EnsureAbsent...) {
+			rec.Source = ""
+		}
+	}
+	b, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestParityWithJavaScript(t *testing.T) {
+	fromYAML, err := Parse([]byte(parityYAML), "dnsconfig.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromJS, err := js.ExecuteJavascriptString([]byte(parityJS), false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src := fromYAML.Domains[0].Records[1].Source; src != "dnsconfig.yaml:29" {
+		t.Errorf("Source = %q, want dnsconfig.yaml:29", src)
+	}
+	got, want := normalized(t, fromYAML), normalized(t, fromJS)
+	if got != want {
+		t.Errorf("YAML and JavaScript differ.\nYAML:\n%s\nJavaScript:\n%s", got, want)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{
+			name: "unknown key",
+			yaml: "registrars:\n  - name: none\n    typo: NONE\n",
+			want: "x.yaml: line 3: field typo not found",
+		},
+		{
+			name: "bad ttl",
+			yaml: "domains:\n  - name: example.com\n    default_ttl: soon\n",
+			want: "x.yaml:3: invalid TTL",
+		},
+		{
+			name: "undeclared registrar",
+			yaml: "domains:\n  - name: example.com\n    registrar: none\n",
+			want: "x.yaml:2: example.com: registrar \"none\" is not declared",
+		},
+		{
+			name: "bad record",
+			yaml: "registrars: [{name: none}]\ndomains:\n  - name: example.com\n    registrar: none\n    records:\n      - {type: A, name: www, target: 1.2.3.4}\n      - {type: A, name: www, target: not-an-ip}\n",
+			want: "x.yaml:7: example.com: A \"www\": invalid IP",
+		},
+		{
+			name: "duplicate domain",
+			yaml: "registrars: [{name: none}]\ndomains:\n  - {name: example.com, registrar: none}\n  - {name: example.com, registrar: none}\n",
+			want: "x.yaml:4: example.com is declared more than once",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.yaml), "x.yaml")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
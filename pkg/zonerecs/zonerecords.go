@@ -2,15 +2,29 @@ package zonerecs
 
 import (
 	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/StackExchange/dnscontrol/v4/pkg/diff2"
 )
 
 // CorrectZoneRecords calls both GetZoneRecords, does any
 // post-processing, and then calls GetZoneRecordsCorrections.  The
 // name sucks because all the good names were taken.
 func CorrectZoneRecords(driver models.DNSProvider, dc *models.DomainConfig) ([]*models.Correction, []*models.Correction, int, error) {
+	reports, corrections, actualChangeCount, _, err := correctZoneRecords(driver, dc, false)
+	return reports, corrections, actualChangeCount, err
+}
+
+// CorrectZoneRecordsWithChanges is like CorrectZoneRecords but also
+// returns the RRsets that the corrections will change, and what they
+// should contain afterwards. RRsets that will be deleted map to an
+// empty list. This is used to verify a push.
+func CorrectZoneRecordsWithChanges(driver models.DNSProvider, dc *models.DomainConfig) ([]*models.Correction, []*models.Correction, int, map[models.RecordKey]models.Records, error) {
+	return correctZoneRecords(driver, dc, true)
+}
+
+func correctZoneRecords(driver models.DNSProvider, dc *models.DomainConfig, wantChanges bool) ([]*models.Correction, []*models.Correction, int, map[models.RecordKey]models.Records, error) {
 	existingRecords, err := driver.GetZoneRecords(dc.Name, dc.Metadata)
 	if err != nil {
-		return nil, nil, 0, err
+		return nil, nil, 0, nil, err
 	}
 
 	// downcase
@@ -25,19 +39,55 @@ func CorrectZoneRecords(driver models.DNSProvider, dc *models.DomainConfig) ([]*
 	// dc.Records.
 	dc, err = dc.Copy()
 	if err != nil {
-		return nil, nil, 0, err
+		return nil, nil, 0, nil, err
 	}
 
 	// punycode
 	if err := dc.Punycode(); err != nil {
-		return nil, nil, 0, err
+		return nil, nil, 0, nil, err
 	}
 	// FIXME(tlim) It is a waste to PunyCode every iteration.
 	// This should be moved to where the JavaScript is processed.
 
+	// Determine the changed RRsets before the provider has a chance
+	// to modify existingRecords or dc.Records.
+	var changes map[models.RecordKey]models.Records
+	if wantChanges {
+		changes, err = changedRRsets(existingRecords, dc)
+		if err != nil {
+			return nil, nil, 0, nil, err
+		}
+	}
+
 	everything, actualChangeCount, err := driver.GetZoneRecordsCorrections(dc, existingRecords)
 	reports, corrections := splitReportsAndCorrections(everything)
-	return reports, corrections, actualChangeCount, err
+	return reports, corrections, actualChangeCount, changes, err
+}
+
+// changedRRsets returns the desired contents of each RRset that differs
+// between existing and dc.
+func changedRRsets(existing models.Records, dc *models.DomainConfig) (map[models.RecordKey]models.Records, error) {
+	// Diff copies so that handsoff() doesn't affect the real thing.
+	ex, err := existing.Copy()
+	if err != nil {
+		return nil, err
+	}
+	ndc, err := dc.Copy()
+	if err != nil {
+		return nil, err
+	}
+	instructions, _, err := diff2.ByRecordSet(ex, ndc, nil)
+	if err != nil {
+		return nil, err
+	}
+	changes := map[models.RecordKey]models.Records{}
+	for _, inst := range instructions {
+		if inst.Type == diff2.REPORT {
+			continue
+		}
+		changes[inst.Key] = inst.New
+	}
+	return changes, nil
 }
 
 func splitReportsAndCorrections(everything []*models.Correction) (reports, corrections []*models.Correction) {
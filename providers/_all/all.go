@@ -33,9 +33,11 @@ import (
 	_ "github.com/StackExchange/dnscontrol/v4/providers/huaweicloud"
 	_ "github.com/StackExchange/dnscontrol/v4/providers/internetbs"
 	_ "github.com/StackExchange/dnscontrol/v4/providers/inwx"
+	_ "github.com/StackExchange/dnscontrol/v4/providers/knot"
 	_ "github.com/StackExchange/dnscontrol/v4/providers/linode"
 	_ "github.com/StackExchange/dnscontrol/v4/providers/loopia"
 	_ "github.com/StackExchange/dnscontrol/v4/providers/luadns"
+	_ "github.com/StackExchange/dnscontrol/v4/providers/memory"
 	_ "github.com/StackExchange/dnscontrol/v4/providers/msdns"
 	_ "github.com/StackExchange/dnscontrol/v4/providers/mythicbeasts"
 	_ "github.com/StackExchange/dnscontrol/v4/providers/namecheap"
@@ -43,6 +45,7 @@ import (
 	_ "github.com/StackExchange/dnscontrol/v4/providers/netcup"
 	_ "github.com/StackExchange/dnscontrol/v4/providers/netlify"
 	_ "github.com/StackExchange/dnscontrol/v4/providers/ns1"
+	_ "github.com/StackExchange/dnscontrol/v4/providers/nsd"
 	_ "github.com/StackExchange/dnscontrol/v4/providers/opensrs"
 	_ "github.com/StackExchange/dnscontrol/v4/providers/oracle"
 	_ "github.com/StackExchange/dnscontrol/v4/providers/ovh"
@@ -54,6 +57,7 @@ import (
 	_ "github.com/StackExchange/dnscontrol/v4/providers/rwth"
 	_ "github.com/StackExchange/dnscontrol/v4/providers/sakuracloud"
 	_ "github.com/StackExchange/dnscontrol/v4/providers/softlayer"
+	_ "github.com/StackExchange/dnscontrol/v4/providers/tinydns"
 	_ "github.com/StackExchange/dnscontrol/v4/providers/transip"
 	_ "github.com/StackExchange/dnscontrol/v4/providers/vultr"
 )
@@ -18,12 +18,14 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/StackExchange/dnscontrol/v4/models"
 	"github.com/StackExchange/dnscontrol/v4/pkg/diff2"
 	"github.com/StackExchange/dnscontrol/v4/pkg/printer"
+	"github.com/StackExchange/dnscontrol/v4/pkg/soaserial"
 	"github.com/StackExchange/dnscontrol/v4/providers"
 	"github.com/miekg/dns"
 )
@@ -42,29 +44,25 @@ var features = providers.DocumentationNotes{
 	providers.CanUseCAA:              providers.Can(),
 	providers.CanUseDHCID:            providers.Can(),
 	providers.CanUseDNAME:            providers.Can(),
+	providers.CanUseDNSKEY:           providers.Can("Only managed if the zone has `DNSKEY()` records. Not with `AUTODNSSEC_ON`"),
 	providers.CanUseDS:               providers.Can(),
 	providers.CanUseHTTPS:            providers.Can(),
 	providers.CanUseLOC:              providers.Can(),
 	providers.CanUseNAPTR:            providers.Can(),
 	providers.CanUsePTR:              providers.Can(),
+	providers.CanUseSOA:              providers.Can("Only managed if the zone has a `SOA()` record. The serial follows the `serial-policy`"),
 	providers.CanUseSRV:              providers.Can(),
 	providers.CanUseSSHFP:            providers.Can(),
 	providers.CanUseSVCB:             providers.Can(),
 	providers.CanUseTLSA:             providers.Can(),
 	providers.DocDualHost:            providers.Cannot(),
 	providers.DocOfficiallySupported: providers.Cannot(),
-	// Possible to support via catalog zones (RFC 9432), but those are not
-	// directly supported by DNSControl right now (although nothing is stopping
-	// you from manually updating a catalog zone using DNSControl if you wish).
-	providers.CanGetZones:      providers.Cannot(),
-	providers.DocCreateDomains: providers.Cannot(),
+	// Listing and creating zones is done through a catalog zone (RFC 9432).
+	providers.CanGetZones:      providers.Can("Listing all zones requires a `catalog`"),
+	providers.DocCreateDomains: providers.Can("Requires a `catalog`. Adds the zone to it"),
 	// Not a valid RR type, so impossible to encode in an RFC-compliant DNS
 	// packet.
 	providers.CanUseAlias: providers.Cannot(),
-	// These are both supported by RFC 2136 (DDNS), but neither work with
-	// DNSControl right now.
-	providers.CanUseSOA:    providers.Cannot(),
-	providers.CanUseDNSKEY: providers.Cannot(),
 }
 
 // axfrddnsProvider stores the client info for the provider.
@@ -76,9 +74,21 @@ type axfrddnsProvider struct {
 	nameservers      []*models.Nameserver
 	transferKey      *Key
 	updateKey        *Key
+	gssTSIG          *gssTSIG
+	serialPolicy     soaserial.Policy
+	cacheDir         string
+	tlsConfig        *tls.Config
 	hasDnssecRecords bool
 }
 
+// catalogProvider is an axfrddnsProvider that also maintains a catalog
+// zone (RFC 9432): it lists, creates and deletes zones by updating the
+// catalog. It is used when `catalog` is set in creds.json.
+type catalogProvider struct {
+	*axfrddnsProvider
+	catalog string
+}
+
 func initAxfrDdns(config map[string]string, providermeta json.RawMessage) (providers.DNSServiceProvider, error) {
 	// config -- the key/values from creds.json
 	// providermeta -- the json blob from NewReq('name', 'TYPE', providermeta)
@@ -108,6 +118,8 @@ func initAxfrDdns(config map[string]string, providermeta json.RawMessage) (provi
 			api.updateMode = config["update-mode"]
 		case "udp":
 			api.updateMode = ""
+		case "quic":
+			return nil, errors.New("AXFRDDNS: DNS over QUIC isn't supported, use the tcp-tls update-mode")
 		default:
 			printer.Printf("[Warning] AXFRDDNS: Unknown update-mode in `creds.json` (%s)\n", config["update-mode"])
 		}
@@ -118,6 +130,8 @@ func initAxfrDdns(config map[string]string, providermeta json.RawMessage) (provi
 		switch config["transfer-mode"] {
 		case "tcp", "tcp-tls":
 			api.transferMode = config["transfer-mode"]
+		case "quic":
+			return nil, errors.New("AXFRDDNS: DNS over QUIC isn't supported, use the tcp-tls transfer-mode")
 		default:
 			printer.Printf("[Warning] AXFRDDNS: Unknown transfer-mode in `creds.json` (%s)\n", config["transfer-mode"])
 		}
@@ -142,6 +156,10 @@ func initAxfrDdns(config map[string]string, providermeta json.RawMessage) (provi
 	} else {
 		api.transferServer = api.master
 	}
+	api.tlsConfig, err = readTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
 	api.updateKey, err = readKey(config["update-key"], "update-key")
 	if err != nil {
 		return nil, err
@@ -150,6 +168,15 @@ func initAxfrDdns(config map[string]string, providermeta json.RawMessage) (provi
 	if err != nil {
 		return nil, err
 	}
+	api.gssTSIG, err = readGSSTSIG(config)
+	if err != nil {
+		return nil, err
+	}
+	api.cacheDir = config["cache-dir"]
+	api.serialPolicy, err = soaserial.ParsePolicy(config["serial-policy"], soaserial.Increment)
+	if err != nil {
+		return nil, fmt.Errorf("AXFRDDNS: %w", err)
+	}
 	switch strings.ToLower(strings.TrimSpace(config["buggy-cname"])) {
 	case "yes", "true":
 		printer.Warnf("'buggy-cname' is deprecated as it is no longer necessary.\n")
@@ -164,6 +191,16 @@ func initAxfrDdns(config map[string]string, providermeta json.RawMessage) (provi
 			"update-mode",
 			"transfer-mode",
 			"buggy-cname",
+			"serial-policy",
+			"cache-dir",
+			"tls-ca",
+			"tls-cert",
+			"tls-key",
+			"tls-server-name",
+			"tls-pin",
+			"gss-tsig-principal",
+			"gss-tsig-keytab",
+			"catalog",
 			"domain",
 			"TYPE":
 			continue
@@ -171,6 +208,9 @@ func initAxfrDdns(config map[string]string, providermeta json.RawMessage) (provi
 			printer.Printf("[Warning] AXFRDDNS: unknown key in `creds.json` (%s)\n", key)
 		}
 	}
+	if config["catalog"] != "" {
+		return &catalogProvider{axfrddnsProvider: api, catalog: strings.TrimSuffix(config["catalog"], ".")}, nil
+	}
 	return api, err
 }
 
@@ -239,7 +279,7 @@ func (c *axfrddnsProvider) getAxfrConnection() (*dns.Transfer, error) {
 	var con net.Conn
 	var err error
 	if c.transferMode == "tcp-tls" {
-		con, err = tls.Dial("tcp", c.transferServer, &tls.Config{})
+		con, err = tls.Dial("tcp", c.transferServer, transferTLSConfig(c.tlsConfig))
 	} else {
 		con, err = net.Dial("tcp", c.transferServer)
 	}
@@ -252,7 +292,20 @@ func (c *axfrddnsProvider) getAxfrConnection() (*dns.Transfer, error) {
 }
 
 // FetchZoneRecords gets the records of a zone and returns them in dns.RR format.
+// If `cache-dir` is set, the zone is fetched with an IXFR request, and
+// the changes are applied to its cached copy.
 func (c *axfrddnsProvider) FetchZoneRecords(domain string) ([]dns.RR, error) {
+	if c.cacheDir != "" {
+		return c.fetchIncremental(domain)
+	}
+	request := new(dns.Msg)
+	request.SetAxfr(domain + ".")
+	return c.transfer(domain, request)
+}
+
+// transfer sends a zone transfer request (AXFR or IXFR) and returns the
+// records of the answer.
+func (c *axfrddnsProvider) transfer(domain string, request *dns.Msg) ([]dns.RR, error) {
 	transfer, err := c.getAxfrConnection()
 	if err != nil {
 		return nil, err
@@ -260,9 +313,6 @@ func (c *axfrddnsProvider) FetchZoneRecords(domain string) ([]dns.RR, error) {
 	transfer.DialTimeout = dnsTimeout
 	transfer.ReadTimeout = dnsTimeout
 
-	request := new(dns.Msg)
-	request.SetAxfr(domain + ".")
-
 	if c.transferKey != nil {
 		transfer.TsigSecret = map[string]string{c.transferKey.id: c.transferKey.secret}
 		request.SetTsig(c.transferKey.id, c.transferKey.algo, 300, time.Now().Unix())
@@ -301,7 +351,8 @@ func (c *axfrddnsProvider) GetZoneRecords(domain string, meta map[string]string)
 	var foundDNSSecRecords *models.RecordConfig
 	foundRecords := models.Records{}
 	for _, rr := range rawRecords {
-		switch rr.Header().Rrtype {
+		rrtype := rr.Header().Rrtype
+		switch rrtype {
 		case dns.TypeRRSIG,
 			dns.TypeDNSKEY,
 			dns.TypeCDNSKEY,
@@ -323,14 +374,17 @@ func (c *axfrddnsProvider) GetZoneRecords(domain string, meta map[string]string)
 					return nil, err
 				}
 			}
-			continue
-		default:
-			rec, err := models.RRtoRC(rr, domain)
-			if err != nil {
-				return nil, err
+			if rrtype != dns.TypeDNSKEY {
+				continue
 			}
-			foundRecords = append(foundRecords, &rec)
+			// The DNSKEY records are also returned: they are
+			// managed if the zone has DNSKEY() records.
+		}
+		rec, err := models.RRtoRC(rr, domain)
+		if err != nil {
+			return nil, err
 		}
+		foundRecords = append(foundRecords, &rec)
 	}
 
 	if len(foundRecords) >= 1 && foundRecords[len(foundRecords)-1].Type == "SOA" {
@@ -368,30 +422,41 @@ func (c *axfrddnsProvider) BuildCorrection(dc *models.DomainConfig, msgs []strin
 	return &models.Correction{
 		Msg: fmt.Sprintf("DDNS UPDATES to '%s' (primary master: '%s'). Changes:\n%s", dc.Name, c.master, strings.Join(msgs, "\n")),
 		F: func() error {
-			client := new(dns.Client)
-			client.Net = c.updateMode
-			client.Timeout = dnsTimeout
-			if c.updateKey != nil {
-				client.TsigSecret = map[string]string{c.updateKey.id: c.updateKey.secret}
-				update.SetTsig(c.updateKey.id, c.updateKey.algo, 300, time.Now().Unix())
-				if c.updateKey.algo == dns.HmacMD5 {
-					client.TsigProvider = md5Provider(c.updateKey.secret)
-				}
-			}
+			return c.sendUpdate(update)
+		},
+	}
+}
 
-			msg, _, err := client.Exchange(update, c.master)
-			if err != nil {
-				return err
-			}
-			if msg.MsgHdr.Rcode != 0 {
-				return fmt.Errorf("[Error] AXFRDDNS: nameserver refused to update the zone: %s (%d)",
-					dns.RcodeToString[msg.MsgHdr.Rcode],
-					msg.MsgHdr.Rcode)
-			}
+// sendUpdate sends a DDNS update to the primary master.
+func (c *axfrddnsProvider) sendUpdate(update *dns.Msg) error {
+	if c.gssTSIG != nil {
+		return c.sendGSSUpdate(update)
+	}
+	client := new(dns.Client)
+	client.Net = c.updateMode
+	if c.updateMode == "tcp-tls" {
+		client.TLSConfig = c.tlsConfig
+	}
+	client.Timeout = dnsTimeout
+	if c.updateKey != nil {
+		client.TsigSecret = map[string]string{c.updateKey.id: c.updateKey.secret}
+		update.SetTsig(c.updateKey.id, c.updateKey.algo, 300, time.Now().Unix())
+		if c.updateKey.algo == dns.HmacMD5 {
+			client.TsigProvider = md5Provider(c.updateKey.secret)
+		}
+	}
 
-			return nil
-		},
+	msg, _, err := client.Exchange(update, c.master)
+	if err != nil {
+		return err
+	}
+	if msg.MsgHdr.Rcode != 0 {
+		return fmt.Errorf("[Error] AXFRDDNS: nameserver refused to update the zone: %s (%d)",
+			dns.RcodeToString[msg.MsgHdr.Rcode],
+			msg.MsgHdr.Rcode)
 	}
+
+	return nil
 }
 
 // hasNSDeletion returns true if there exist a correction that deletes or changes an NS record
@@ -415,9 +480,31 @@ func hasNSDeletion(changes diff2.ChangeList) bool {
 
 // GetZoneRecordsCorrections returns a list of corrections that will turn existing records into dc.Records.
 func (c *axfrddnsProvider) GetZoneRecordsCorrections(dc *models.DomainConfig, foundRecords models.Records) ([]*models.Correction, int, error) {
-	// Ignoring the SOA, others providers don't manage it either.
+	var foundSoa *models.RecordConfig
 	if len(foundRecords) >= 1 && foundRecords[0].Type == "SOA" {
-		foundRecords = foundRecords[1:]
+		foundSoa = foundRecords[0]
+	}
+	// The SOA is only managed if the zone has an SOA() record.
+	// Otherwise it is ignored, like others providers do.
+	desiredSoa := findApexRecord(dc.Records, "SOA")
+	if desiredSoa == nil {
+		if foundSoa != nil {
+			foundRecords = foundRecords[1:]
+		}
+	} else {
+		if foundSoa == nil {
+			return nil, 0, fmt.Errorf("AXFRDDNS: no SOA record found in the AXFR answer for %s", dc.Name)
+		}
+		// The serial number is managed below.
+		desiredSoa.SoaSerial = foundSoa.SoaSerial
+	}
+
+	// Likewise, the DNSKEY records are only managed if the zone has
+	// DNSKEY() records. The server manages them for signed zones.
+	if !slices.ContainsFunc(dc.Records, isDNSKEY) {
+		foundRecords = slices.DeleteFunc(slices.Clone(foundRecords), isDNSKEY)
+	} else if dc.AutoDNSSEC == "on" {
+		return nil, 0, fmt.Errorf("AXFRDDNS: %s: DNSKEY records can't be managed along with AUTODNSSEC_ON", dc.Name)
 	}
 
 	// TODO(tlim): This check should be done on all providers. Move to the global validation code.
@@ -481,7 +568,23 @@ func (c *axfrddnsProvider) GetZoneRecordsCorrections(dc *models.DomainConfig, fo
 		update.Insert([]dns.RR{dummyNs1})
 	}
 
+	dnskeyNames := map[string]bool{}
 	for _, change := range changes {
+		var rec *models.RecordConfig
+		if len(change.New) != 0 {
+			rec = change.New[0]
+		} else if len(change.Old) != 0 {
+			rec = change.Old[0]
+		}
+		if rec != nil && rec.Type == "SOA" {
+			// Replaced below, along with the serial number. An SOA
+			// can't be created or deleted.
+			msgs = append(msgs, change.Msgs...)
+			continue
+		}
+		if rec != nil && rec.Type == "DNSKEY" {
+			dnskeyNames[rec.NameFQDN] = true
+		}
 		switch change.Type {
 		case diff2.DELETE:
 			msgs = append(msgs, change.Msgs[0])
@@ -519,6 +622,11 @@ func (c *axfrddnsProvider) GetZoneRecordsCorrections(dc *models.DomainConfig, fo
 		update.Remove([]dns.RR{dummyNs2})
 	}
 
+	dnskeyPrerequisites(update, dnskeyNames, foundRecords)
+	if desiredSoa != nil && len(msgs) > 0 {
+		msgs = append(msgs, c.soaUpdate(update, foundSoa, desiredSoa, soaserial.Content(dc.Records)))
+	}
+
 	returnValue := []*models.Correction{}
 
 	if len(msgs) > 0 {
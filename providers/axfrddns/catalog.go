@@ -0,0 +1,83 @@
+package axfrddns
+
+import (
+	"fmt"
+
+	"github.com/StackExchange/dnscontrol/v4/pkg/catalogzone"
+	"github.com/miekg/dns"
+)
+
+// catalogMembers fetches the catalog and returns its members (see
+// catalogzone.Members) and records.
+func (c *catalogProvider) catalogMembers() (map[string]string, []dns.RR, error) {
+	rrs, err := c.FetchZoneRecords(c.catalog)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching catalog zone %s: %w", c.catalog, err)
+	}
+	return catalogzone.Members(c.catalog, rrs), rrs, nil
+}
+
+// ListZones returns the member zones of the catalog.
+func (c *catalogProvider) ListZones() ([]string, error) {
+	members, _, err := c.catalogMembers()
+	if err != nil {
+		return nil, err
+	}
+	return catalogzone.SortedZones(members), nil
+}
+
+// EnsureZoneExists adds the zone to the catalog, so that the secondaries
+// provision it. The primary master must already serve the zone.
+func (c *catalogProvider) EnsureZoneExists(domain string) error {
+	members, _, err := c.catalogMembers()
+	if err != nil {
+		return err
+	}
+	if _, ok := members[domain]; ok {
+		return nil
+	}
+
+	ptr := catalogzone.NewPTR(c.catalog, domain)
+	update := new(dns.Msg)
+	update.SetUpdate(c.catalog + ".")
+	// Fail if someone else added a member with the same id meanwhile.
+	update.NameNotUsed([]dns.RR{ptr})
+	update.Insert([]dns.RR{ptr})
+	if err := c.sendUpdate(update); err != nil {
+		return fmt.Errorf("adding %s to catalog zone %s: %w", domain, c.catalog, err)
+	}
+	return nil
+}
+
+// DeleteZone removes the zone, and its properties, from the catalog,
+// so that the secondaries remove it. The zone is not changed on the
+// primary master.
+func (c *catalogProvider) DeleteZone(domain string) error {
+	members, rrs, err := c.catalogMembers()
+	if err != nil {
+		return err
+	}
+	owner, ok := members[domain]
+	if !ok {
+		return fmt.Errorf("zone %s is not in catalog zone %s", domain, c.catalog)
+	}
+
+	update := new(dns.Msg)
+	update.SetUpdate(c.catalog + ".")
+	// Fail if the member was changed meanwhile.
+	ptr := catalogzone.NewPTR(c.catalog, domain)
+	ptr.Hdr.Name = owner
+	update.Used([]dns.RR{ptr})
+	removed := map[string]bool{}
+	for _, rr := range rrs {
+		name := dns.CanonicalName(rr.Header().Name)
+		if catalogzone.IsMemberData(owner, name) && !removed[name] {
+			removed[name] = true
+			update.RemoveName([]dns.RR{&dns.ANY{Hdr: dns.RR_Header{Name: name}}})
+		}
+	}
+	if err := c.sendUpdate(update); err != nil {
+		return fmt.Errorf("removing %s from catalog zone %s: %w", domain, c.catalog, err)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package axfrddns
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// gssTSIG stores the Kerberos credentials of GSS-TSIG (RFC 3645), the
+// authentication of the secure dynamic updates of Active Directory.
+//
+// GSS-TSIG is delegated to the Kerberos and BIND tools: `kinit` gets a
+// ticket with the keytab, and `nsupdate -g` sends the update with it.
+type gssTSIG struct {
+	principal string
+	keytab    string
+}
+
+func readGSSTSIG(config map[string]string) (*gssTSIG, error) {
+	principal, keytab := config["gss-tsig-principal"], config["gss-tsig-keytab"]
+	if principal == "" && keytab == "" {
+		return nil, nil
+	}
+	if principal == "" || keytab == "" {
+		return nil, errors.New("AXFRDDNS: gss-tsig-principal and gss-tsig-keytab must be set together")
+	}
+	if config["update-key"] != "" {
+		return nil, errors.New("AXFRDDNS: update-key and gss-tsig-principal can't be used together")
+	}
+	if config["update-mode"] == "tcp-tls" {
+		return nil, errors.New("AXFRDDNS: GSS-TSIG can't be used with the tcp-tls update-mode")
+	}
+	// Fail now rather than on the first update.
+	for _, tool := range []string{"kinit", "nsupdate"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			return nil, fmt.Errorf("AXFRDDNS: GSS-TSIG needs %s to be installed: %w", tool, err)
+		}
+	}
+	return &gssTSIG{principal: principal, keytab: keytab}, nil
+}
+
+// sendGSSUpdate sends a DDNS update to the primary master, signed with
+// GSS-TSIG.
+func (c *axfrddnsProvider) sendGSSUpdate(update *dns.Msg) error {
+	script, err := nsupdateScript(c.master, update)
+	if err != nil {
+		return err
+	}
+
+	// A credentials cache of our own, so that the user's isn't touched.
+	dir, err := os.MkdirTemp("", "dnscontrol-krb5")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	env := append(os.Environ(), "KRB5CCNAME=FILE:"+filepath.Join(dir, "ccache"))
+
+	kinit := exec.Command("kinit", "-k", "-t", c.gssTSIG.keytab, c.gssTSIG.principal)
+	kinit.Env = env
+	if out, err := kinit.CombinedOutput(); err != nil {
+		return fmt.Errorf("[Error] AXFRDDNS: kinit failed for %s: %w: %s", c.gssTSIG.principal, err, strings.TrimSpace(string(out)))
+	}
+
+	args := []string{"-g"}
+	if c.updateMode == "tcp" {
+		args = append(args, "-v")
+	}
+	nsupdate := exec.Command("nsupdate", args...)
+	nsupdate.Env = env
+	nsupdate.Stdin = strings.NewReader(script)
+	if out, err := nsupdate.CombinedOutput(); err != nil {
+		return fmt.Errorf("[Error] AXFRDDNS: nameserver refused to update the zone: nsupdate: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// nsupdateScript returns the nsupdate(1) commands that send update to
+// server (host:port).
+func nsupdateScript(server string, update *dns.Msg) (string, error) {
+	host, port, err := net.SplitHostPort(server)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "server %s %s\n", host, port)
+	fmt.Fprintf(&b, "zone %s\n", update.Question[0].Name)
+
+	// The prerequisites (RFC 2136, section 2.4).
+	for _, rr := range update.Answer {
+		h := rr.Header()
+		name, rtype := h.Name, dns.TypeToString[h.Rrtype]
+		switch {
+		case h.Class == dns.ClassANY && h.Rrtype == dns.TypeANY:
+			fmt.Fprintf(&b, "prereq yxdomain %s\n", name)
+		case h.Class == dns.ClassNONE && h.Rrtype == dns.TypeANY:
+			fmt.Fprintf(&b, "prereq nxdomain %s\n", name)
+		case h.Class == dns.ClassANY:
+			fmt.Fprintf(&b, "prereq yxrrset %s %s\n", name, rtype)
+		case h.Class == dns.ClassNONE:
+			fmt.Fprintf(&b, "prereq nxrrset %s %s\n", name, rtype)
+		default:
+			fmt.Fprintf(&b, "prereq yxrrset %s %s %s %s\n", name, dns.ClassToString[h.Class], rtype, rdata(rr))
+		}
+	}
+
+	// The updates (RFC 2136, section 2.5).
+	for _, rr := range update.Ns {
+		h := rr.Header()
+		name, rtype := h.Name, dns.TypeToString[h.Rrtype]
+		switch {
+		case h.Class == dns.ClassANY && h.Rrtype == dns.TypeANY:
+			fmt.Fprintf(&b, "update delete %s\n", name)
+		case h.Class == dns.ClassANY:
+			fmt.Fprintf(&b, "update delete %s %s\n", name, rtype)
+		case h.Class == dns.ClassNONE:
+			fmt.Fprintf(&b, "update delete %s %s %s\n", name, rtype, rdata(rr))
+		default:
+			fmt.Fprintf(&b, "update add %s %s %s %s %s\n", name, strconv.FormatUint(uint64(h.Ttl), 10), dns.ClassToString[h.Class], rtype, rdata(rr))
+		}
+	}
+	b.WriteString("send\n")
+	return b.String(), nil
+}
+
+// rdata returns the RDATA of rr in presentation format.
+func rdata(rr dns.RR) string {
+	return strings.TrimPrefix(rr.String(), rr.Header().String())
+}
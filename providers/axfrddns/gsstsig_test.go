@@ -0,0 +1,71 @@
+package axfrddns
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestNsupdateScript(t *testing.T) {
+	update := new(dns.Msg)
+	update.SetUpdate("example.com.")
+	update.Used(mustRRs(t, "example.com. 3600 IN SOA ns.example.com. hostmaster.example.com. 41 3600 600 604800 300"))
+	update.NameNotUsed(mustRRs(t, "new.example.com. 300 IN A 192.0.2.1"))
+	update.RRsetNotUsed(mustRRs(t, "example.com. 300 IN DNSKEY 257 3 13 AAAA"))
+	update.RemoveName(mustRRs(t, "old.example.com. 300 IN CNAME www.example.com."))
+	update.Remove(mustRRs(t, `www.example.com. 300 IN TXT "a b"`))
+	update.RemoveRRset(mustRRs(t, "www.example.com. 300 IN MX 10 mx.example.com."))
+	update.Insert(mustRRs(t, "new.example.com. 300 IN A 192.0.2.1"))
+
+	got, err := nsupdateScript("dc1.ad.example.com:53", update)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `server dc1.ad.example.com 53
+zone example.com.
+prereq yxrrset example.com. IN SOA ns.example.com. hostmaster.example.com. 41 3600 600 604800 300
+prereq nxdomain new.example.com.
+prereq nxrrset example.com. DNSKEY
+update delete old.example.com.
+update delete www.example.com. TXT "a b"
+update delete www.example.com. MX
+update add new.example.com. 300 IN A 192.0.2.1
+send
+`
+	if got != want {
+		t.Errorf("nsupdateScript() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestReadGSSTSIG(t *testing.T) {
+	if g, err := readGSSTSIG(map[string]string{}); g != nil || err != nil {
+		t.Errorf("readGSSTSIG() = %v, %v, want nothing", g, err)
+	}
+	for _, config := range []map[string]string{
+		{"gss-tsig-principal": "dnscontrol@AD.EXAMPLE.COM"},
+		{"gss-tsig-principal": "dnscontrol@AD.EXAMPLE.COM", "gss-tsig-keytab": "dnscontrol.keytab", "update-key": "hmac-sha256:k:c2VjcmV0"},
+		{"gss-tsig-principal": "dnscontrol@AD.EXAMPLE.COM", "gss-tsig-keytab": "dnscontrol.keytab", "update-mode": "tcp-tls"},
+	} {
+		if _, err := readGSSTSIG(config); err == nil {
+			t.Errorf("readGSSTSIG(%v) succeeded", config)
+		}
+	}
+	// The tools must be installed.
+	config := map[string]string{"gss-tsig-principal": "dnscontrol@AD.EXAMPLE.COM", "gss-tsig-keytab": "dnscontrol.keytab"}
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+	if _, err := readGSSTSIG(config); err == nil || !strings.Contains(err.Error(), "kinit") {
+		t.Errorf("readGSSTSIG() without kinit = %v, want an error", err)
+	}
+	for _, tool := range []string{"kinit", "nsupdate"} {
+		if err := os.WriteFile(filepath.Join(dir, tool), []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if g, err := readGSSTSIG(config); g == nil || err != nil {
+		t.Errorf("readGSSTSIG() = %v, %v", g, err)
+	}
+}
@@ -0,0 +1,211 @@
+package axfrddns
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v4/pkg/printer"
+	"github.com/miekg/dns"
+)
+
+// fetchIncremental fetches a zone with an IXFR request (RFC 1995): the
+// server only sends the changes since the serial number of the cached
+// copy of the zone, which are applied to it. It falls back to an AXFR
+// request if there is no cached copy, or if the IXFR request fails.
+// The cached copy is then updated.
+func (c *axfrddnsProvider) fetchIncremental(domain string) ([]dns.RR, error) {
+	zone, err := c.readCache(domain)
+	if err != nil {
+		printer.Warnf("AXFRDDNS: ignoring the cached copy of %s: %s\n", domain, err)
+	}
+	if zone != nil {
+		soa := zone[0].(*dns.SOA)
+		request := new(dns.Msg)
+		request.SetIxfr(domain+".", soa.Serial, soa.Ns, soa.Mbox)
+		answer, err := c.transfer(domain, request)
+		if err == nil {
+			zone, err = applyIxfr(zone, answer)
+		}
+		if err != nil {
+			printer.Warnf("AXFRDDNS: IXFR of %s failed, falling back to AXFR: %s\n", domain, err)
+			zone = nil
+		}
+	}
+
+	if zone == nil {
+		request := new(dns.Msg)
+		request.SetAxfr(domain + ".")
+		answer, err := c.transfer(domain, request)
+		if err != nil {
+			return nil, err
+		}
+		if zone, err = axfrZone(answer); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.writeCache(domain, zone); err != nil {
+		printer.Warnf("AXFRDDNS: can't cache %s: %s\n", domain, err)
+	}
+	// Like the answer to an AXFR request, the SOA is first and last.
+	return append(zone[:len(zone):len(zone)], zone[0]), nil
+}
+
+// axfrZone returns the zone that is sent in an AXFR answer (or an IXFR
+// answer that is a full transfer): its SOA, then the other records.
+func axfrZone(answer []dns.RR) ([]dns.RR, error) {
+	if len(answer) < 2 {
+		return nil, errors.New("truncated zone transfer")
+	}
+	if _, ok := answer[0].(*dns.SOA); !ok {
+		return nil, errors.New("the zone transfer doesn't start with an SOA")
+	}
+	// The SOA is sent two times: as the first and the last record.
+	return answer[:len(answer)-1], nil
+}
+
+// applyIxfr applies the answer to an IXFR request to zone (its SOA,
+// then the other records) and returns the new zone.
+//
+// The answer is either the SOA alone, if the zone is up to date, or the
+// whole zone, like for an AXFR request, or the differences: the new
+// SOA, then for each change of the serial number, the old SOA, the
+// deleted records, the new SOA, the added records, and finally the new
+// SOA again.
+func applyIxfr(zone []dns.RR, answer []dns.RR) ([]dns.RR, error) {
+	if len(answer) == 0 {
+		return nil, errors.New("empty IXFR answer")
+	}
+	newSoa, ok := answer[0].(*dns.SOA)
+	if !ok {
+		return nil, errors.New("the IXFR answer doesn't start with an SOA")
+	}
+	serial := zone[0].(*dns.SOA).Serial
+	if len(answer) == 1 {
+		if newSoa.Serial != serial {
+			// The zone was probably recreated with a lower serial.
+			return nil, fmt.Errorf("the serial number of the zone (%d) is behind the cached copy (%d)", newSoa.Serial, serial)
+		}
+		return zone, nil
+	}
+	if _, ok := answer[1].(*dns.SOA); !ok {
+		return axfrZone(answer)
+	}
+	last, ok := answer[len(answer)-1].(*dns.SOA)
+	if !ok || last.Serial != newSoa.Serial {
+		return nil, errors.New("truncated IXFR answer")
+	}
+
+	records := zone[1:]
+	index := make(map[string]int, len(records))
+	for i, rr := range records {
+		index[rrKey(rr)] = i
+	}
+	deleted := make([]bool, len(records))
+	deleting := false
+	for _, rr := range answer[1 : len(answer)-1] {
+		if soa, ok := rr.(*dns.SOA); ok {
+			deleting = !deleting
+			if deleting && soa.Serial != serial {
+				return nil, fmt.Errorf("IXFR answer for serial %d, but the cached copy has serial %d", soa.Serial, serial)
+			}
+			serial = soa.Serial
+			continue
+		}
+		if !deleting {
+			records = append(records, rr)
+			deleted = append(deleted, false)
+			index[rrKey(rr)] = len(records) - 1
+			continue
+		}
+		i, ok := index[rrKey(rr)]
+		if !ok || deleted[i] {
+			return nil, fmt.Errorf("IXFR answer deletes a record that isn't in the cached copy: %s", rr)
+		}
+		deleted[i] = true
+		delete(index, rrKey(rr))
+	}
+	if deleting || serial != newSoa.Serial {
+		return nil, errors.New("malformed IXFR answer")
+	}
+
+	result := []dns.RR{newSoa}
+	for i, rr := range records {
+		if !deleted[i] {
+			result = append(result, rr)
+		}
+	}
+	return result, nil
+}
+
+// rrKey returns a string that identifies a record, regardless of its
+// TTL and of the case of its name.
+func rrKey(rr dns.RR) string {
+	rr = dns.Copy(rr)
+	rr.Header().Name = dns.CanonicalName(rr.Header().Name)
+	rr.Header().Ttl = 0
+	return rr.String()
+}
+
+func (c *axfrddnsProvider) cacheFilename(domain string) string {
+	return filepath.Join(c.cacheDir, strings.ToLower(domain)+".zone")
+}
+
+// readCache returns the cached copy of a zone (its SOA, then the other
+// records), or nil if there is none.
+func (c *axfrddnsProvider) readCache(domain string) ([]dns.RR, error) {
+	f, err := os.Open(c.cacheFilename(domain))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var zone []dns.RR
+	zp := dns.NewZoneParser(f, domain+".", f.Name())
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		zone = append(zone, rr)
+	}
+	if err := zp.Err(); err != nil {
+		return nil, err
+	}
+	if len(zone) == 0 {
+		return nil, nil
+	}
+	if _, ok := zone[0].(*dns.SOA); !ok {
+		return nil, fmt.Errorf("%s doesn't start with an SOA", f.Name())
+	}
+	return zone, nil
+}
+
+// writeCache replaces the cached copy of a zone. The file is replaced
+// atomically, so that an interrupted write doesn't corrupt it.
+func (c *axfrddnsProvider) writeCache(domain string, zone []dns.RR) error {
+	if err := os.MkdirAll(c.cacheDir, 0o750); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(c.cacheDir, strings.ToLower(domain)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	var b strings.Builder
+	for _, rr := range zone {
+		b.WriteString(rr.String())
+		b.WriteByte('\n')
+	}
+	if _, err := f.WriteString(b.String()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), c.cacheFilename(domain))
+}
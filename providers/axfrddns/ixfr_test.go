@@ -0,0 +1,136 @@
+package axfrddns
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func mustRRs(t *testing.T, ss ...string) []dns.RR {
+	t.Helper()
+	var rrs []dns.RR
+	for _, s := range ss {
+		rr, err := dns.NewRR(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rrs = append(rrs, rr)
+	}
+	return rrs
+}
+
+func rrStrings(rrs []dns.RR) []string {
+	var ss []string
+	for _, rr := range rrs {
+		ss = append(ss, rr.String())
+	}
+	return ss
+}
+
+func TestApplyIxfr(t *testing.T) {
+	const (
+		soa1 = "example.com. 3600 IN SOA ns.example.com. hostmaster.example.com. 1 3600 600 604800 300"
+		soa2 = "example.com. 3600 IN SOA ns.example.com. hostmaster.example.com. 2 3600 600 604800 300"
+		soa3 = "example.com. 3600 IN SOA ns.example.com. hostmaster.example.com. 3 3600 600 604800 300"
+	)
+	zone := mustRRs(t, soa1,
+		"example.com. 300 IN A 192.0.2.1",
+		"www.example.com. 300 IN A 192.0.2.2",
+	)
+	tests := []struct {
+		name    string
+		answer  []dns.RR
+		want    []dns.RR
+		wantErr bool
+	}{
+		{
+			name:   "up to date",
+			answer: mustRRs(t, soa1),
+			want:   zone,
+		},
+		{
+			name:    "behind",
+			answer:  mustRRs(t, soa3),
+			wantErr: true,
+		},
+		{
+			name: "full transfer",
+			answer: mustRRs(t, soa2,
+				"example.com. 300 IN A 192.0.2.1",
+				soa2,
+			),
+			want: mustRRs(t, soa2, "example.com. 300 IN A 192.0.2.1"),
+		},
+		{
+			name: "incremental",
+			answer: mustRRs(t, soa3,
+				soa1,
+				"WWW.example.com. 600 IN A 192.0.2.2",
+				soa2,
+				"www.example.com. 300 IN A 192.0.2.3",
+				soa2,
+				soa3,
+				"mail.example.com. 300 IN A 192.0.2.4",
+				soa3,
+			),
+			want: mustRRs(t, soa3,
+				"example.com. 300 IN A 192.0.2.1",
+				"www.example.com. 300 IN A 192.0.2.3",
+				"mail.example.com. 300 IN A 192.0.2.4",
+			),
+		},
+		{
+			name: "other serial",
+			answer: mustRRs(t, soa3,
+				soa2,
+				soa3,
+				"mail.example.com. 300 IN A 192.0.2.4",
+				soa3,
+			),
+			wantErr: true,
+		},
+		{
+			name: "unknown deletion",
+			answer: mustRRs(t, soa2,
+				soa1,
+				"mail.example.com. 300 IN A 192.0.2.4",
+				soa2,
+				soa2,
+			),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyIxfr(zone, tt.answer)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyIxfr() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(rrStrings(got), rrStrings(tt.want)) {
+				t.Errorf("applyIxfr() = %q, want %q", rrStrings(got), rrStrings(tt.want))
+			}
+		})
+	}
+}
+
+func TestCache(t *testing.T) {
+	c := &axfrddnsProvider{cacheDir: t.TempDir()}
+	if zone, err := c.readCache("example.com"); zone != nil || err != nil {
+		t.Fatalf("readCache() = %v, %v, want nothing", zone, err)
+	}
+	zone := mustRRs(t,
+		"example.com. 3600 IN SOA ns.example.com. hostmaster.example.com. 1 3600 600 604800 300",
+		`example.com. 300 IN TXT "v=spf1 -all"`,
+	)
+	if err := c.writeCache("example.com", zone); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.readCache("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rrStrings(got), rrStrings(zone)) {
+		t.Errorf("readCache() = %q, want %q", rrStrings(got), rrStrings(zone))
+	}
+}
@@ -0,0 +1,68 @@
+package axfrddns
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/StackExchange/dnscontrol/v4/pkg/soaserial"
+	"github.com/miekg/dns"
+)
+
+// SerialPolicy returns the policy of the SOA serial numbers.
+func (c *axfrddnsProvider) SerialPolicy() soaserial.Policy {
+	return c.serialPolicy
+}
+
+// NextSerial returns the serial number that follows oldSerial.
+func (c *axfrddnsProvider) NextSerial(oldSerial uint32, content []byte) uint32 {
+	return soaserial.Next(c.serialPolicy, oldSerial, time.Now(), content)
+}
+
+// findApexRecord returns the first record of rtype at the apex, or nil.
+func findApexRecord(records models.Records, rtype string) *models.RecordConfig {
+	for _, r := range records {
+		if r.Type == rtype && r.Name == "@" {
+			return r
+		}
+	}
+	return nil
+}
+
+// dnskeyPrerequisites adds to update the prerequisites that the DNSKEY
+// RRsets at names are still the ones in foundRecords: RFC 2136
+// servers then reject the update if the keys changed in the meantime
+// (for example during a key rollover), instead of mixing the changes.
+func dnskeyPrerequisites(update *dns.Msg, names map[string]bool, foundRecords models.Records) {
+	for name := range names {
+		var rrset []dns.RR
+		for _, r := range foundRecords {
+			if r.Type == "DNSKEY" && r.NameFQDN == name {
+				rrset = append(rrset, r.ToRR())
+			}
+		}
+		if len(rrset) == 0 {
+			update.RRsetNotUsed([]dns.RR{&dns.DNSKEY{Hdr: dns.RR_Header{Name: dns.Fqdn(name), Rrtype: dns.TypeDNSKEY}}})
+		} else {
+			update.Used(rrset)
+		}
+	}
+}
+
+// soaUpdate adds to update the SOA desired, with the serial number
+// that follows the one of found, and the prerequisite that the SOA is
+// still found: as the server changes the serial number on each update,
+// the update is rejected if the zone was changed since it was fetched.
+// content is the zone's desired records, for the contenthash policy.
+// It returns a message that describes the new serial number.
+func (c *axfrddnsProvider) soaUpdate(update *dns.Msg, found, desired *models.RecordConfig, content []byte) string {
+	soa := *desired
+	soa.SoaSerial = c.NextSerial(found.SoaSerial, content)
+	update.Used([]dns.RR{found.ToRR()})
+	update.Insert([]dns.RR{soa.ToRR()})
+	return fmt.Sprintf("± MODIFY %s SOA serial %d -> %d", desired.NameFQDN, found.SoaSerial, soa.SoaSerial)
+}
+
+func isDNSKEY(r *models.RecordConfig) bool {
+	return r.Type == "DNSKEY"
+}
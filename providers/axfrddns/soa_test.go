@@ -0,0 +1,65 @@
+package axfrddns
+
+import (
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/StackExchange/dnscontrol/v4/pkg/soaserial"
+	"github.com/miekg/dns"
+)
+
+func mustRC(t *testing.T, s string) *models.RecordConfig {
+	t.Helper()
+	rr, err := dns.NewRR(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := models.RRtoRC(rr, "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &rc
+}
+
+func TestSoaUpdate(t *testing.T) {
+	c := &axfrddnsProvider{serialPolicy: soaserial.Increment}
+	found := mustRC(t, "example.com. 3600 IN SOA ns.example.com. hostmaster.example.com. 41 3600 600 604800 300")
+	desired := mustRC(t, "example.com. 3600 IN SOA ns.example.com. hostmaster.example.com. 0 7200 600 604800 300")
+
+	update := new(dns.Msg)
+	update.SetUpdate("example.com.")
+	msg := c.soaUpdate(update, found, desired, nil)
+
+	if want := "± MODIFY example.com SOA serial 41 -> 42"; msg != want {
+		t.Errorf("soaUpdate() = %q, want %q", msg, want)
+	}
+	if len(update.Answer) != 1 || update.Answer[0].String() != "example.com.\t0\tIN\tSOA\tns.example.com. hostmaster.example.com. 41 3600 600 604800 300" {
+		t.Errorf("soaUpdate() prerequisites = %v", update.Answer)
+	}
+	if len(update.Ns) != 1 || update.Ns[0].String() != "example.com.\t3600\tIN\tSOA\tns.example.com. hostmaster.example.com. 42 7200 600 604800 300" {
+		t.Errorf("soaUpdate() updates = %v", update.Ns)
+	}
+}
+
+func TestDnskeyPrerequisites(t *testing.T) {
+	found := models.Records{
+		mustRC(t, "example.com. 3600 IN DNSKEY 257 3 13 mdsswUyr3DPW132mOi8V9xESWE8jTo0dxCjjnopKl+GqJxpVXckHAeF+KkxLbxILfDLUT0rAK9iUzy1L53eKGQ=="),
+		mustRC(t, "example.com. 3600 IN A 192.0.2.1"),
+	}
+	update := new(dns.Msg)
+	update.SetUpdate("example.com.")
+	dnskeyPrerequisites(update, map[string]bool{"example.com": true, "sub.example.com": true}, found)
+
+	got := map[string]bool{}
+	for _, rr := range update.Answer {
+		got[rr.String()] = true
+	}
+	for _, want := range []string{
+		"example.com.\t0\tIN\tDNSKEY\t257 3 13 mdsswUyr3DPW132mOi8V9xESWE8jTo0dxCjjnopKl+GqJxpVXckHAeF+KkxLbxILfDLUT0rAK9iUzy1L53eKGQ==",
+		"sub.example.com.\t0\tNONE\tDNSKEY\t",
+	} {
+		if !got[want] {
+			t.Errorf("dnskeyPrerequisites() = %v, missing %q", update.Answer, want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package axfrddns
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// readTLSConfig returns the TLS configuration of the `tcp-tls` modes,
+// from the `tls-*` keys of creds.json:
+//
+//   - tls-ca: a PEM file of the CA certificates that the server's
+//     certificate is checked against, instead of the system's ones.
+//   - tls-cert and tls-key: the PEM files of the client certificate and
+//     its key, for mutual TLS.
+//   - tls-server-name: the name that the server's certificate is checked
+//     against, instead of the host of `master` or `transfer-server`.
+//   - tls-pin: a comma-separated list of the base64-encoded SHA-256
+//     hashes of public keys (SPKI), as in RFC 7858. One of the server's
+//     certificates must have one of them. Without `tls-ca`, the server
+//     is only authenticated by its key.
+func readTLSConfig(config map[string]string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: config["tls-server-name"],
+	}
+
+	if file := config["tls-ca"]; file != "" {
+		pem, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("tls-ca in AXFRDDNS: %w", err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls-ca in AXFRDDNS: no certificate found in %s", file)
+		}
+	}
+
+	switch cert, key := config["tls-cert"], config["tls-key"]; {
+	case cert != "" && key != "":
+		pair, err := tls.LoadX509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("tls-cert/tls-key in AXFRDDNS: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{pair}
+	case cert != "" || key != "":
+		return nil, errors.New("AXFRDDNS: tls-cert and tls-key must be set together")
+	}
+
+	if config["tls-pin"] != "" {
+		var pins [][]byte
+		for _, pin := range strings.Split(config["tls-pin"], ",") {
+			hash, err := base64.StdEncoding.DecodeString(strings.TrimSpace(pin))
+			if err != nil || len(hash) != sha256.Size {
+				return nil, fmt.Errorf("tls-pin in AXFRDDNS: %q is not a base64-encoded SHA-256 hash", pin)
+			}
+			pins = append(pins, hash)
+		}
+		// Without a CA, the pins replace the usual verification of the
+		// certificate chain.
+		cfg.InsecureSkipVerify = cfg.RootCAs == nil
+		cfg.VerifyPeerCertificate = verifyPins(pins)
+	}
+	return cfg, nil
+}
+
+// verifyPins returns a tls.Config.VerifyPeerCertificate function that
+// checks that one of the certificates has the public key of a pin.
+func verifyPins(pins [][]byte) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		for _, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return err
+			}
+			hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+			for _, pin := range pins {
+				if bytes.Equal(hash[:], pin) {
+					return nil
+				}
+			}
+		}
+		return errors.New("AXFRDDNS: the server's certificate doesn't match tls-pin")
+	}
+}
+
+// transferTLSConfig returns the TLS configuration of zone transfers
+// over TLS (XoT), which RFC 9103 restricts to TLS 1.3 and the "dot"
+// ALPN protocol.
+func transferTLSConfig(cfg *tls.Config) *tls.Config {
+	cfg = cfg.Clone()
+	cfg.MinVersion = tls.VersionTLS13
+	cfg.NextProtos = []string{"dot"}
+	return cfg
+}
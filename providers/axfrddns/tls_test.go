@@ -0,0 +1,70 @@
+package axfrddns
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestVerifyPins(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ns.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	pin := base64.StdEncoding.EncodeToString(hash[:])
+	other := base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	cfg, err := readTLSConfig(map[string]string{"tls-pin": other + ", " + pin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.InsecureSkipVerify {
+		t.Error("readTLSConfig() verifies the chain without tls-ca")
+	}
+	if err := cfg.VerifyPeerCertificate([][]byte{raw}, nil); err != nil {
+		t.Errorf("VerifyPeerCertificate() = %v, want nil", err)
+	}
+
+	cfg, err = readTLSConfig(map[string]string{"tls-pin": other})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.VerifyPeerCertificate([][]byte{raw}, nil); err == nil {
+		t.Error("VerifyPeerCertificate() accepts a certificate that doesn't match the pin")
+	}
+}
+
+func TestReadTLSConfigErrors(t *testing.T) {
+	for _, config := range []map[string]string{
+		{"tls-pin": "not base64"},
+		{"tls-pin": base64.StdEncoding.EncodeToString([]byte("too short"))},
+		{"tls-cert": "client.pem"},
+		{"tls-ca": "/nonexistent/ca.pem"},
+	} {
+		if _, err := readTLSConfig(config); err == nil {
+			t.Errorf("readTLSConfig(%v) succeeded", config)
+		}
+	}
+}
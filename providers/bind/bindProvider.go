@@ -18,14 +18,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/StackExchange/dnscontrol/v4/models"
-	"github.com/StackExchange/dnscontrol/v4/pkg/bindserial"
 	"github.com/StackExchange/dnscontrol/v4/pkg/diff2"
 	"github.com/StackExchange/dnscontrol/v4/pkg/prettyzone"
 	"github.com/StackExchange/dnscontrol/v4/pkg/printer"
+	"github.com/StackExchange/dnscontrol/v4/pkg/soaserial"
 	"github.com/StackExchange/dnscontrol/v4/providers"
 	"github.com/miekg/dns"
 )
@@ -37,6 +38,7 @@ var features = providers.DocumentationNotes{
 	providers.CanGetZones:            providers.Can(),
 	providers.CanConcur:              providers.Can(),
 	providers.CanUseCAA:              providers.Can(),
+	providers.CanUseComments:         providers.Can("Stored as a comment after the record"),
 	providers.CanUseDHCID:            providers.Can(),
 	providers.CanUseDNAME:            providers.Can(),
 	providers.CanUseDS:               providers.Can(),
@@ -55,12 +57,61 @@ var features = providers.DocumentationNotes{
 	providers.DocOfficiallySupported: providers.Can(),
 }
 
+// Features returns the features of the providers that are built on
+// this one, such as NSD and KNOT. Only BIND is officially supported.
+func Features() providers.DocumentationNotes {
+	notes := providers.DocumentationNotes{}
+	for feature, note := range features {
+		if feature != providers.DocOfficiallySupported {
+			notes[feature] = note
+		}
+	}
+	return notes
+}
+
+// NewInitializer returns the initializer of a provider that writes
+// zone files for server.
+func NewInitializer(server Server) providers.DspInitializer {
+	return func(config map[string]string, providermeta json.RawMessage) (providers.DNSServiceProvider, error) {
+		return initServer(server, config, providermeta)
+	}
+}
+
 func initBind(config map[string]string, providermeta json.RawMessage) (providers.DNSServiceProvider, error) {
+	return initServer(ServerBIND, config, providermeta)
+}
+
+func initServer(server Server, config map[string]string, providermeta json.RawMessage) (providers.DNSServiceProvider, error) {
 	// config -- the key/values from creds.json
 	// meta -- the json blob from NewReq('name', 'TYPE', meta)
+	confKey := server.confKey()
 	api := &bindProvider{
 		directory:      config["directory"],
 		filenameformat: config["filenameformat"],
+		catalog:        strings.TrimSuffix(config["catalog"], "."),
+
+		server:          server,
+		namedconf:       config[confKey],
+		serverdirectory: config["serverdirectory"],
+		viewOptions:     map[string]string{},
+		zoneOptions:     config[confKey+"-zone-options"],
+	}
+	if server == ServerBIND {
+		for _, view := range strings.Split(config["namedconf-views"], ",") {
+			if view = strings.TrimSpace(view); view != "" {
+				api.views = append(api.views, view)
+			}
+		}
+		for key, value := range config {
+			if view, ok := strings.CutPrefix(key, "namedconf-view-"); ok {
+				api.viewOptions[view] = value
+			}
+		}
+	}
+	var err error
+	api.serialPolicy, err = soaserial.ParsePolicy(config["serial-policy"], soaserial.DateSerial)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", server, err)
 	}
 	if api.directory == "" {
 		api.directory = "zones"
@@ -87,7 +138,6 @@ func initBind(config map[string]string, providermeta json.RawMessage) (providers
 		// name without the trailing dot to indicate a FQDN.
 		nss = append(nss, strings.TrimSuffix(ns, "."))
 	}
-	var err error
 	api.nameservers, err = models.ToNameservers(nss)
 	return api, err
 }
@@ -126,6 +176,17 @@ type bindProvider struct {
 	nameservers    []*models.Nameserver
 	directory      string
 	filenameformat string
+	catalog        string // The catalog zone (RFC 9432) to maintain, if any.
+	serialPolicy   soaserial.Policy
+
+	// The server configuration file (named.conf, nsd.conf or knot.conf)
+	// to maintain, if any, and its settings.
+	server          Server
+	namedconf       string
+	serverdirectory string            // The directory, as the server sees it.
+	views           []string          // The order of the views.
+	viewOptions     map[string]string // The statements of each view.
+	zoneOptions     string            // The statements of each zone.
 }
 
 // GetNameservers returns the nameservers for a domain.
@@ -137,8 +198,12 @@ func (c *bindProvider) GetNameservers(string) ([]*models.Nameserver, error) {
 	return models.ToNameservers(r)
 }
 
-// ListZones returns all the zones in an account
+// ListZones returns all the zones in an account: the members of the
+// catalog zone, or else the zones that have a zone file.
 func (c *bindProvider) ListZones() ([]string, error) {
+	if c.catalog != "" {
+		return c.listCatalog()
+	}
 	if _, err := os.Stat(c.directory); os.IsNotExist(err) {
 		return nil, fmt.Errorf("directory %q does not exist", c.directory)
 	}
@@ -155,6 +220,8 @@ func (c *bindProvider) ListZones() ([]string, error) {
 			c.directory, err)
 	}
 
+	// The included zone files are not zones.
+	filenames = slices.DeleteFunc(filenames, isIncludeFilename)
 	return extractZonesFromFilenames(c.filenameformat, filenames), nil
 }
 
@@ -179,7 +246,7 @@ func (c *bindProvider) GetZoneRecords(domain string, meta map[string]string) (mo
 				meta[models.DomainUniqueName], domain, meta[models.DomainTag]),
 		)
 	}
-	content, err := os.ReadFile(zonefile)
+	records, err := c.readZoneFile(zonefile, domain)
 	if os.IsNotExist(err) {
 		// If the file doesn't exist, that's not an error. Just informational.
 		fmt.Fprintf(os.Stderr, "File does not yet exist: %q (will create)\n", zonefile)
@@ -188,8 +255,7 @@ func (c *bindProvider) GetZoneRecords(domain string, meta map[string]string) (mo
 	if err != nil {
 		return nil, fmt.Errorf("can't open %s: %w", zonefile, err)
 	}
-
-	return ParseZoneContents(string(content), domain, zonefile)
+	return records, nil
 }
 
 // ParseZoneContents parses a string as a BIND zone and returns the records.
@@ -202,6 +268,14 @@ func ParseZoneContents(content string, zoneName string, zonefileName string) (mo
 		if err != nil {
 			return nil, err
 		}
+		// The SOA is generated, so the comments on it (often one per
+		// field) are not a COMMENT().
+		if comment := recordComment(zp.Comment()); comment != "" && rec.Type != "SOA" {
+			if rec.Metadata == nil {
+				rec.Metadata = map[string]string{}
+			}
+			rec.Metadata[models.RecordComment] = comment
+		}
 		foundRecords = append(foundRecords, &rec)
 	}
 
@@ -211,8 +285,27 @@ func ParseZoneContents(content string, zoneName string, zonefileName string) (mo
 	return foundRecords, nil
 }
 
-func (c *bindProvider) EnsureZoneExists(_ string) error {
-	return nil
+// recordComment returns the COMMENT() of a record, given the comment
+// on its line in the zone file. Only the comments that dnscontrol wrote
+// (see prettyzone.CommentMarker) are; those that people write in zone
+// files are not.
+func recordComment(comment string) string {
+	comment = strings.TrimSpace(strings.TrimPrefix(comment, ";"))
+	if rest, ok := strings.CutPrefix(comment, "CF_PROXY_ON"); ok {
+		comment = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest), ";"))
+	}
+	rest, ok := strings.CutPrefix(comment, prettyzone.CommentMarker)
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(rest)
+}
+
+func (c *bindProvider) EnsureZoneExists(domain string) error {
+	if c.catalog == "" {
+		return nil
+	}
+	return c.addToCatalog(domain)
 }
 
 // GetZoneRecordsCorrections returns a list of corrections that will turn existing records into dc.Records.
@@ -238,7 +331,8 @@ func (c *bindProvider) GetZoneRecordsCorrections(dc *models.DomainConfig, foundR
 			break
 		}
 	}
-	soaRec, nextSerial := makeSoa(dc.Name, &c.DefaultSoa, foundSoa, desiredSoa)
+	soaRec := makeSoa(dc.Name, &c.DefaultSoa, foundSoa, desiredSoa)
+	nextSerial := generateSerial(c.serialPolicy, soaRec.SoaSerial, soaserial.Content(dc.Records))
 	if desiredSoa == nil {
 		dc.Records = append(dc.Records, soaRec)
 		desiredSoa = dc.Records[len(dc.Records)-1]
@@ -246,15 +340,33 @@ func (c *bindProvider) GetZoneRecordsCorrections(dc *models.DomainConfig, foundR
 		*desiredSoa = *soaRec
 	}
 
+	if err := checkIncludes(dc.Records); err != nil {
+		return nil, 0, err
+	}
+
+	zonefile = filepath.Join(c.directory,
+		makeFileName(c.filenameformat,
+			dc.Metadata[models.DomainUniqueName], dc.Name, dc.Metadata[models.DomainTag]),
+	)
+	if c.namedconf != "" {
+		correction, err := c.namedConfCorrection(dc, zonefile)
+		if err != nil {
+			return nil, 0, err
+		}
+		if correction != nil {
+			corrections = append(corrections, correction)
+		}
+	}
+
 	var msgs []string
 	var actualChangeCount int
-	result, err := diff2.ByZone(foundRecords, dc, nil)
+	result, err := diff2.ByZone(foundRecords, dc, diff2.WithComments(includeComparable))
 	if err != nil {
 		return nil, 0, err
 	}
 	msgs, changes, actualChangeCount = result.Msgs, result.HasChanges, result.ActualChangeCount
 	if !changes {
-		return nil, 0, nil
+		return corrections, 0, nil
 	}
 	msg = strings.Join(msgs, "\n")
 
@@ -270,44 +382,15 @@ func (c *bindProvider) GetZoneRecordsCorrections(dc *models.DomainConfig, foundR
 		comments = append(comments, "Automatic DNSSEC signing requested")
 	}
 
-	zonefile = filepath.Join(c.directory,
-		makeFileName(c.filenameformat,
-			dc.Metadata[models.DomainUniqueName], dc.Name, dc.Metadata[models.DomainTag]),
-	)
-
 	// We only change the serial number if there is a change.
 	desiredSoa.SoaSerial = nextSerial
 
-	// If the --bindserial flag is used, force the serial to that value
-	if bindserial.ForcedValue != 0 {
-		desiredSoa.SoaSerial = uint32(bindserial.ForcedValue & 0xFFFF)
-	}
-
 	corrections = append(corrections,
 		&models.Correction{
 			Msg: msg,
 			F: func() error {
 				printer.Printf("WRITING ZONEFILE: %v\n", zonefile)
-				fname, err := preprocessFilename(zonefile)
-				if err != nil {
-					return fmt.Errorf("could not create zonefile: %w", err)
-				}
-				zf, err := os.Create(fname)
-				if err != nil {
-					return fmt.Errorf("could not create zonefile: %w", err)
-				}
-				// Beware that if there are any fake types, then they will
-				// be commented out on write, but we don't reverse that when
-				// reading, so there will be a diff on every invocation.
-				err = prettyzone.WriteZoneFileRC(zf, result.DesiredPlus, dc.Name, 0, comments)
-				if err != nil {
-					return fmt.Errorf("failed WriteZoneFile: %w", err)
-				}
-				err = zf.Close()
-				if err != nil {
-					return fmt.Errorf("closing: %w", err)
-				}
-				return nil
+				return c.writeZoneFiles(zonefile, result.DesiredPlus, foundRecords, dc.Name, comments)
 			},
 		})
 
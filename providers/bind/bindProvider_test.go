@@ -0,0 +1,42 @@
+package bind
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/StackExchange/dnscontrol/v4/pkg/prettyzone"
+)
+
+func TestComments(t *testing.T) {
+	www := &models.RecordConfig{Type: "A", TTL: 300, Metadata: map[string]string{models.RecordComment: "load balancer"}}
+	www.SetLabel("www", "example.com")
+	www.MustSetTarget("192.0.2.1")
+	proxied := &models.RecordConfig{Type: "A", TTL: 300, Metadata: map[string]string{"cloudflare_proxy": "true"}}
+	proxied.SetLabel("cdn", "example.com")
+	proxied.MustSetTarget("192.0.2.2")
+	both := &models.RecordConfig{Type: "A", TTL: 300, Metadata: map[string]string{"cloudflare_proxy": "true", models.RecordComment: "via CDN"}}
+	both.SetLabel("img", "example.com")
+	both.MustSetTarget("192.0.2.3")
+
+	buf := &bytes.Buffer{}
+	if err := prettyzone.WriteZoneFileRC(buf, models.Records{www, proxied, both}, "example.com", 0, nil); err != nil {
+		t.Fatal(err)
+	}
+	// Comments that people write aren't a COMMENT().
+	buf.WriteString("mail IN A 192.0.2.4 ; mail server\n")
+	recs, err := ParseZoneContents(buf.String(), "example.com", "example.com.zone")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"www": "load balancer", "cdn": "", "img": "via CDN", "mail": ""}
+	if len(recs) != len(want) {
+		t.Fatalf("%d records, want %d\n%s", len(recs), len(want), buf)
+	}
+	for _, rec := range recs {
+		if got := rec.Metadata[models.RecordComment]; got != want[rec.GetLabel()] {
+			t.Errorf("comment of %s = %q, want %q\n%s", rec.GetLabel(), got, want[rec.GetLabel()], buf)
+		}
+	}
+}
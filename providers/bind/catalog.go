@@ -0,0 +1,161 @@
+package bind
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/StackExchange/dnscontrol/v4/pkg/catalogzone"
+	"github.com/StackExchange/dnscontrol/v4/pkg/prettyzone"
+	"github.com/StackExchange/dnscontrol/v4/pkg/printer"
+	"github.com/StackExchange/dnscontrol/v4/pkg/soaserial"
+	"github.com/miekg/dns"
+)
+
+// catalogMu serializes the updates of the catalog zone file, which is
+// shared by all zones.
+var catalogMu sync.Mutex
+
+func (c *bindProvider) catalogFilename() string {
+	return filepath.Join(c.directory, makeFileName(c.filenameformat, c.catalog, c.catalog, ""))
+}
+
+// readCatalog returns the records of the catalog zone file, or nil if
+// it doesn't exist yet.
+func (c *bindProvider) readCatalog() ([]dns.RR, error) {
+	filename := c.catalogFilename()
+	content, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't open catalog zone: %w", err)
+	}
+
+	var rrs []dns.RR
+	zp := dns.NewZoneParser(strings.NewReader(string(content)), c.catalog, filename)
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		rrs = append(rrs, rr)
+	}
+	if err := zp.Err(); err != nil {
+		return nil, err
+	}
+	return rrs, nil
+}
+
+// writeCatalog writes the catalog zone file, with a new serial number.
+// If rrs has no SOA, one is made from the default_soa.
+func (c *bindProvider) writeCatalog(rrs []dns.RR) error {
+	var recs models.Records
+	var foundSoa *models.RecordConfig
+	for _, rr := range rrs {
+		rec, err := models.RRtoRC(rr, c.catalog)
+		if err != nil {
+			return err
+		}
+		if rec.Type == "SOA" {
+			foundSoa = &rec
+			continue
+		}
+		recs = append(recs, &rec)
+	}
+	soa := makeSoa(c.catalog, &c.DefaultSoa, foundSoa, foundSoa)
+	soa.SoaSerial = generateSerial(c.serialPolicy, soa.SoaSerial, soaserial.Content(recs))
+	recs = append(models.Records{soa}, recs...)
+
+	filename := c.catalogFilename()
+	printer.Printf("WRITING CATALOG ZONEFILE: %v\n", filename)
+	fname, err := preprocessFilename(filename)
+	if err != nil {
+		return fmt.Errorf("could not create catalog zonefile: %w", err)
+	}
+	f, err := os.Create(fname)
+	if err != nil {
+		return fmt.Errorf("could not create catalog zonefile: %w", err)
+	}
+	comments := []string{"catalog zone generated with dnscontrol " + time.Now().Format(time.RFC3339)}
+	if err := prettyzone.WriteZoneFileRC(f, recs, c.catalog, 0, comments); err != nil {
+		f.Close()
+		return fmt.Errorf("failed WriteZoneFile: %w", err)
+	}
+	return f.Close()
+}
+
+// listCatalog returns the member zones of the catalog.
+func (c *bindProvider) listCatalog() ([]string, error) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	rrs, err := c.readCatalog()
+	if err != nil {
+		return nil, err
+	}
+	return catalogzone.SortedZones(catalogzone.Members(c.catalog, rrs)), nil
+}
+
+// addToCatalog adds the zone to the catalog zone file, creating it if
+// needed.
+func (c *bindProvider) addToCatalog(domain string) error {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	rrs, err := c.readCatalog()
+	if err != nil {
+		return err
+	}
+	if _, ok := catalogzone.Members(c.catalog, rrs)[domain]; ok {
+		return nil
+	}
+	if rrs == nil {
+		rrs = catalogzone.Skeleton(c.catalog)
+	}
+	return c.writeCatalog(append(rrs, catalogzone.NewPTR(c.catalog, domain)))
+}
+
+// DeleteZone removes the zone from the named.conf file and from the
+// catalog zone, if they are maintained. The zone file is left alone.
+func (c *bindProvider) DeleteZone(domain string) error {
+	if c.catalog == "" && c.namedconf == "" {
+		return fmt.Errorf("%s can only delete zones from a catalog zone or a %s file, and neither is set", c.server, c.server.confKey())
+	}
+	if c.namedconf != "" {
+		found, err := c.removeFromNamedConf(domain)
+		if err != nil {
+			return err
+		}
+		if !found && c.catalog == "" {
+			return fmt.Errorf("zone %s is not in %s", domain, c.namedconf)
+		}
+	}
+	if c.catalog != "" {
+		return c.removeFromCatalog(domain)
+	}
+	return nil
+}
+
+// removeFromCatalog removes the zone, and its properties, from the
+// catalog zone.
+func (c *bindProvider) removeFromCatalog(domain string) error {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	rrs, err := c.readCatalog()
+	if err != nil {
+		return err
+	}
+	owner, ok := catalogzone.Members(c.catalog, rrs)[domain]
+	if !ok {
+		return fmt.Errorf("zone %s is not in catalog zone %s", domain, c.catalog)
+	}
+	var kept []dns.RR
+	for _, rr := range rrs {
+		if !catalogzone.IsMemberData(owner, rr.Header().Name) {
+			kept = append(kept, rr)
+		}
+	}
+	return c.writeCatalog(kept)
+}
@@ -0,0 +1,50 @@
+package bind
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestCatalog(t *testing.T) {
+	c := &bindProvider{directory: t.TempDir(), filenameformat: "%U.zone", catalog: "catalog.example"}
+
+	zones, err := c.ListZones()
+	if err != nil || len(zones) != 0 {
+		t.Fatalf("ListZones() = %v, %v; want nothing", zones, err)
+	}
+	for _, zone := range []string{"b.example", "a.example", "b.example"} {
+		if err := c.EnsureZoneExists(zone); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if zones, err = c.ListZones(); err != nil || !reflect.DeepEqual(zones, []string{"a.example", "b.example"}) {
+		t.Fatalf("ListZones() = %v, %v; want a.example and b.example", zones, err)
+	}
+
+	if err := c.DeleteZone("b.example"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteZone("b.example"); err == nil {
+		t.Error("DeleteZone() of a deleted zone succeeded")
+	}
+	if zones, err = c.ListZones(); err != nil || !reflect.DeepEqual(zones, []string{"a.example"}) {
+		t.Fatalf("ListZones() = %v, %v; want a.example", zones, err)
+	}
+
+	// The catalog has the version of the schema.
+	rrs, err := c.readCatalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var version []string
+	for _, rr := range rrs {
+		if txt, ok := rr.(*dns.TXT); ok && txt.Hdr.Name == "version.catalog.example." {
+			version = txt.Txt
+		}
+	}
+	if !reflect.DeepEqual(version, []string{"2"}) {
+		t.Errorf("version = %q, want 2", version)
+	}
+}
@@ -0,0 +1,209 @@
+package bind
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/StackExchange/dnscontrol/v4/pkg/prettyzone"
+)
+
+// metaInclude is the record metadata that puts a record in an included
+// zone file: A("web1", "10.0.0.1", {bind_include: "hosts"}) is written
+// to the file example.com.zone.hosts.inc, which the zone file
+// example.com.zone includes with $INCLUDE.
+const metaInclude = "bind_include"
+
+var (
+	includeName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	includeLine = regexp.MustCompile(`^\$INCLUDE\s+"?([^"\s]+)"?`)
+)
+
+// includeFilename returns the file of the records of the zone file
+// whose metaInclude is name.
+func includeFilename(zonefile, name string) string {
+	return zonefile + "." + name + ".inc"
+}
+
+// isIncludeFilename reports whether filename is an included zone file,
+// rather than a zone file.
+func isIncludeFilename(filename string) bool {
+	return strings.HasSuffix(filename, ".inc")
+}
+
+// includeComparable is a diff2.ComparableFunc: moving a record to
+// another included file is a change.
+func includeComparable(rc *models.RecordConfig) string {
+	if name := rc.Metadata[metaInclude]; name != "" {
+		return metaInclude + "=" + name
+	}
+	return ""
+}
+
+// checkIncludes checks the metaInclude of the records.
+func checkIncludes(records models.Records) error {
+	for _, rc := range records {
+		name, ok := rc.Metadata[metaInclude]
+		if !ok {
+			continue
+		}
+		if !includeName.MatchString(name) {
+			return fmt.Errorf("%s %s: %s %q must only have letters, digits, - and _", rc.GetLabelFQDN(), rc.Type, metaInclude, name)
+		}
+		if rc.Type == "SOA" {
+			return fmt.Errorf("%s: the SOA must be in the zone file, not in %s %q", rc.GetLabelFQDN(), metaInclude, name)
+		}
+	}
+	return nil
+}
+
+// readZoneFile reads a zone file, and the files it includes with the
+// $INCLUDE lines that writeZoneFiles writes. The records of an
+// included file have its name as metaInclude. Any other $INCLUDE is an
+// error.
+func (c *bindProvider) readZoneFile(zonefile, domain string) (models.Records, error) {
+	f, err := os.Open(zonefile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var main strings.Builder
+	var includes []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		if m := includeLine.FindStringSubmatch(scanner.Text()); m != nil {
+			includes = append(includes, m[1])
+			continue
+		}
+		main.WriteString(scanner.Text() + "\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("can't read %s: %w", zonefile, err)
+	}
+
+	records, err := ParseZoneContents(main.String(), domain, zonefile)
+	if err != nil {
+		return nil, err
+	}
+	for _, include := range includes {
+		// Only the files that writeZoneFiles includes can be read back:
+		// the records of any other file would be moved into it.
+		filename := c.localPath(include)
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(filename), filepath.Base(zonefile)+"."), ".inc")
+		if !includeName.MatchString(name) || filepath.Clean(filename) != filepath.Clean(includeFilename(zonefile, name)) {
+			return nil, fmt.Errorf("%s: can't manage $INCLUDE %q, only %q and the like", zonefile, include, c.serverPath(includeFilename(zonefile, "NAME")))
+		}
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("can't open %s, included by %s: %w", filename, zonefile, err)
+		}
+		included, err := ParseZoneContents(string(content), domain, filename)
+		if err != nil {
+			return nil, err
+		}
+		for _, rc := range included {
+			if rc.Metadata == nil {
+				rc.Metadata = map[string]string{}
+			}
+			rc.Metadata[metaInclude] = name
+		}
+		records = append(records, included...)
+	}
+	return records, nil
+}
+
+// localPath is the reverse of serverPath.
+func (c *bindProvider) localPath(filename string) string {
+	if c.serverdirectory == "" {
+		return filepath.FromSlash(filename)
+	}
+	rel, err := filepath.Rel(c.serverdirectory, filepath.FromSlash(filename))
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.FromSlash(filename)
+	}
+	return filepath.Join(c.directory, rel)
+}
+
+// writeZoneFiles writes the zone file of records, with an $INCLUDE line
+// for each included file, and the included files. The included files
+// of found that are no longer used are removed.
+func (c *bindProvider) writeZoneFiles(zonefile string, records, found models.Records, origin string, comments []string) error {
+	groups := map[string]models.Records{}
+	for _, rc := range records {
+		name := rc.Metadata[metaInclude]
+		groups[name] = append(groups[name], rc)
+	}
+	var names []string
+	for name := range groups {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		filename := includeFilename(zonefile, name)
+		includeComments := append(comments[:len(comments):len(comments)], "included by "+filepath.Base(zonefile))
+		if err := writeZoneFile(filename, groups[name], origin, includeComments); err != nil {
+			return err
+		}
+	}
+
+	if err := writeZoneFile(zonefile, groups[""], origin, comments); err != nil {
+		return err
+	}
+	if len(names) != 0 {
+		f, err := os.OpenFile(zonefile, os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			fmt.Fprintf(f, "$INCLUDE %q\n", c.serverPath(includeFilename(zonefile, name)))
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("closing: %w", err)
+		}
+	}
+
+	for _, rc := range found {
+		name := rc.Metadata[metaInclude]
+		if _, ok := groups[name]; !ok {
+			groups[name] = nil
+			if err := os.Remove(includeFilename(zonefile, name)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeZoneFile writes the records in a zone file.
+func writeZoneFile(filename string, records models.Records, origin string, comments []string) error {
+	fname, err := preprocessFilename(filename)
+	if err != nil {
+		return fmt.Errorf("could not create zonefile: %w", err)
+	}
+	zf, err := os.Create(fname)
+	if err != nil {
+		return fmt.Errorf("could not create zonefile: %w", err)
+	}
+	// Beware that if there are any fake types, then they will
+	// be commented out on write, but we don't reverse that when
+	// reading, so there will be a diff on every invocation.
+	err = prettyzone.WriteZoneFileRC(zf, records, origin, 0, comments)
+	if err != nil {
+		zf.Close()
+		return fmt.Errorf("failed WriteZoneFile: %w", err)
+	}
+	if err := zf.Close(); err != nil {
+		return fmt.Errorf("closing: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package bind
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+)
+
+func TestIncludes(t *testing.T) {
+	dir := t.TempDir()
+	c := &bindProvider{directory: dir, filenameformat: "%U.zone", serverdirectory: "/var/named"}
+	zonefile := filepath.Join(dir, "example.com.zone")
+
+	rec := func(label, target, include string) *models.RecordConfig {
+		rc := &models.RecordConfig{Type: "A", TTL: 300}
+		rc.SetLabel(label, "example.com")
+		if err := rc.SetTarget(target); err != nil {
+			t.Fatal(err)
+		}
+		if include != "" {
+			rc.Metadata = map[string]string{metaInclude: include}
+		}
+		return rc
+	}
+	soa := &models.RecordConfig{Type: "SOA", TTL: 300}
+	soa.SetLabel("@", "example.com")
+	if err := soa.SetTargetSOA("ns.example.com.", "hostmaster.example.com.", 1, 3600, 600, 604800, 300); err != nil {
+		t.Fatal(err)
+	}
+
+	records := models.Records{soa, rec("www", "192.0.2.1", ""), rec("web1", "10.0.0.1", "hosts"), rec("db1", "10.0.1.1", "db")}
+	if err := c.writeZoneFiles(zonefile, records, nil, "example.com", []string{"test"}); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(zonefile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(content), "$INCLUDE \"/var/named/example.com.zone.db.inc\"\n$INCLUDE \"/var/named/example.com.zone.hosts.inc\"\n") {
+		t.Errorf("the zone file doesn't include the other files:\n%s", content)
+	}
+
+	found, err := c.readZoneFile(zonefile, "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	includes := map[string]string{}
+	for _, rc := range found {
+		includes[rc.GetLabel()] = rc.Metadata[metaInclude]
+	}
+	if want := map[string]string{"@": "", "www": "", "web1": "hosts", "db1": "db"}; !reflect.DeepEqual(includes, want) {
+		t.Errorf("readZoneFile() includes = %v, want %v", includes, want)
+	}
+
+	// A hand-written $INCLUDE can't be managed.
+	for _, include := range []string{"/etc/bind/common.inc", "/var/named/example.com.zone.../x.inc", "/var/named/example.com.zone.hosts.inc.bak"} {
+		if err := os.WriteFile(zonefile, append(content, []byte("$INCLUDE \""+include+"\"\n")...), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.readZoneFile(zonefile, "example.com"); err == nil {
+			t.Errorf("readZoneFile() accepts $INCLUDE %q", include)
+		}
+	}
+	if err := os.WriteFile(zonefile, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The included files that are no longer used are removed.
+	if err := c.writeZoneFiles(zonefile, records[:3], found, "example.com", []string{"test"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(includeFilename(zonefile, "db")); !os.IsNotExist(err) {
+		t.Errorf("the unused included file wasn't removed: %v", err)
+	}
+
+	zones, err := c.ListZones()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zones) != 1 || zones[0] != "example.com" {
+		t.Errorf("ListZones() = %v, want example.com", zones)
+	}
+}
+
+func TestCheckIncludes(t *testing.T) {
+	rc := &models.RecordConfig{Type: "A", Metadata: map[string]string{metaInclude: "../etc"}}
+	rc.SetLabel("www", "example.com")
+	if err := checkIncludes(models.Records{rc}); err == nil {
+		t.Error("checkIncludes() accepted a path")
+	}
+}
@@ -0,0 +1,332 @@
+package bind
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/StackExchange/dnscontrol/v4/pkg/printer"
+)
+
+// Server is the DNS server that the zone files are for. It sets the
+// format of the server configuration file that the provider maintains.
+type Server int
+
+// The servers.
+const (
+	ServerBIND Server = iota // named.conf, which PowerDNS's bind backend also reads.
+	ServerNSD                // nsd.conf.
+	ServerKnot               // knot.conf.
+)
+
+// confKey returns the key of creds.json that sets the server
+// configuration file.
+func (s Server) confKey() string {
+	switch s {
+	case ServerNSD:
+		return "nsdconf"
+	case ServerKnot:
+		return "knotconf"
+	default:
+		return "namedconf"
+	}
+}
+
+// namedconfMu serializes the updates of the named.conf file, which is
+// shared by all zones.
+var namedconfMu sync.Mutex
+
+// namedconfZone matches the zone stanzas of a generated named.conf
+// file, which end with a comment that has the zone's unique name
+// (name!tag). The "in-view" stanzas don't have one. The zones that no
+// view can have are commented out.
+var namedconfZone = regexp.MustCompile(`^\s*(?:# )?zone "[^"]*" \{.* file "([^"]*)";.*\}; // (\S+)$`)
+
+// The zone names and files of generated nsd.conf and knot.conf files.
+var (
+	nsdName  = regexp.MustCompile(`^\s*name: "([^"]*)"$`)
+	nsdFile  = regexp.MustCompile(`^\s*zonefile: "([^"]*)"$`)
+	knotName = regexp.MustCompile(`^\s*- domain: "([^"]*)"$`)
+	knotFile = regexp.MustCompile(`^\s*file: "([^"]*)"$`)
+)
+
+// serverPath returns the path of a file of the directory as the DNS
+// server sees it, which is set with `serverdirectory`.
+func (c *bindProvider) serverPath(filename string) string {
+	if c.serverdirectory == "" {
+		return filename
+	}
+	rel, err := filepath.Rel(c.directory, filename)
+	if err != nil {
+		return filename
+	}
+	return filepath.ToSlash(filepath.Join(c.serverdirectory, rel))
+}
+
+// readNamedConf returns the zones of the generated server
+// configuration file, as a map from their unique names to their zone
+// files.
+func (c *bindProvider) readNamedConf() (map[string]string, error) {
+	zones := map[string]string{}
+	f, err := os.Open(c.namedconf)
+	if os.IsNotExist(err) {
+		return zones, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't open named.conf: %w", err)
+	}
+	defer f.Close()
+
+	nameRE, fileRE := nsdName, nsdFile
+	if c.server == ServerKnot {
+		nameRE, fileRE = knotName, knotFile
+	}
+	var name string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if c.server == ServerBIND {
+			if m := namedconfZone.FindStringSubmatch(line); m != nil {
+				zones[m[2]] = m[1]
+			}
+		} else if m := nameRE.FindStringSubmatch(line); m != nil {
+			name = m[1]
+		} else if m := fileRE.FindStringSubmatch(line); m != nil && name != "" {
+			zones[name] = m[1]
+			name = ""
+		}
+	}
+	return zones, scanner.Err()
+}
+
+// writeNamedConf writes the named.conf file of zones (as returned by
+// readNamedConf).
+func (c *bindProvider) writeNamedConf(zones map[string]string) error {
+	printer.Printf("WRITING SERVER CONFIGURATION: %v\n", c.namedconf)
+	fname, err := preprocessFilename(c.namedconf)
+	if err != nil {
+		return fmt.Errorf("could not create %s: %w", c.namedconf, err)
+	}
+	return os.WriteFile(fname, []byte(c.generateNamedConf(zones)), 0o644)
+}
+
+// generateNamedConf returns the contents of the named.conf file of
+// zones. If some of them have tags, the zones are put in views named
+// after the tags, and the zones without a tag are in every view.
+func (c *bindProvider) generateNamedConf(zones map[string]string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# generated with dnscontrol %s. Do not edit.\n", time.Now().Format(time.RFC3339))
+	if c.server != ServerBIND {
+		c.generateZoneList(&b, zones)
+		return b.String()
+	}
+
+	byTag := map[string][]string{}
+	for unique := range zones {
+		_, tag, _ := strings.Cut(unique, "!")
+		byTag[tag] = append(byTag[tag], unique)
+	}
+	for _, uniques := range byTag {
+		sort.Strings(uniques)
+	}
+	views := c.viewOrder(byTag)
+
+	if len(views) == 0 {
+		for _, unique := range byTag[""] {
+			b.WriteString(c.zoneStanza(unique, zones[unique], "") + "\n")
+		}
+		return b.String()
+	}
+
+	// A zone without a tag is in every view, except those that have
+	// a tagged zone of the same name (example.com!internal hides
+	// example.com). A zone can't be in two views, so it is defined in
+	// the first view that has it, and the later ones refer to that one.
+	tagged := map[string]bool{}
+	for unique := range zones {
+		tagged[unique] = true
+	}
+	home := map[string]string{}
+	for _, unique := range byTag[""] {
+		for _, view := range views {
+			if !tagged[unique+"!"+view] {
+				home[unique] = view
+				break
+			}
+		}
+	}
+
+	for _, view := range views {
+		fmt.Fprintf(&b, "\nview %q {\n", view)
+		if options := c.viewOptions[view]; options != "" {
+			fmt.Fprintf(&b, "\t%s\n", options)
+		}
+		for _, unique := range byTag[view] {
+			b.WriteString("\t" + c.zoneStanza(unique, zones[unique], "") + "\n")
+		}
+		for _, unique := range byTag[""] {
+			if tagged[unique+"!"+view] {
+				continue
+			}
+			inView := ""
+			if home[unique] != view {
+				inView = home[unique]
+			}
+			b.WriteString("\t" + c.zoneStanza(unique, zones[unique], inView) + "\n")
+		}
+		b.WriteString("};\n")
+	}
+
+	// Still list the others, so that they aren't forgotten.
+	var hidden []string
+	for _, unique := range byTag[""] {
+		if home[unique] == "" {
+			hidden = append(hidden, unique)
+		}
+	}
+	if len(hidden) != 0 {
+		b.WriteString("\n# Every view has a tagged zone of the same name as these:\n")
+		for _, unique := range hidden {
+			b.WriteString("# " + c.zoneStanza(unique, zones[unique], "") + "\n")
+		}
+	}
+	return b.String()
+}
+
+// generateZoneList writes the zone section of an nsd.conf or knot.conf
+// file. They don't have views, so the zones don't have tags.
+func (c *bindProvider) generateZoneList(b *strings.Builder, zones map[string]string) {
+	var names []string
+	for name := range zones {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	indent := "\t"
+	if c.server == ServerKnot {
+		// YAML, in which the items of the zone list are indented.
+		indent = "    "
+		b.WriteString("zone:\n")
+	}
+	for _, name := range names {
+		if c.server == ServerKnot {
+			fmt.Fprintf(b, "  - domain: %q\n", name)
+			fmt.Fprintf(b, "%sfile: %q\n", indent, zones[name])
+		} else {
+			fmt.Fprintf(b, "\nzone:\n%sname: %q\n", indent, name)
+			fmt.Fprintf(b, "%szonefile: %q\n", indent, zones[name])
+		}
+		for _, option := range strings.Split(c.zoneOptions, "\n") {
+			if option = strings.TrimSpace(option); option != "" {
+				b.WriteString(indent + option + "\n")
+			}
+		}
+	}
+}
+
+// viewOrder returns the views of the tags of byTag: first the ones of
+// `namedconf-views`, then the others, sorted.
+func (c *bindProvider) viewOrder(byTag map[string][]string) []string {
+	var others []string
+	for tag := range byTag {
+		if tag != "" && !slices.Contains(c.views, tag) {
+			others = append(others, tag)
+		}
+	}
+	if len(others) == 0 && slices.IndexFunc(c.views, func(v string) bool { return len(byTag[v]) != 0 }) == -1 {
+		return nil
+	}
+	sort.Strings(others)
+	return append(slices.Clone(c.views), others...)
+}
+
+// zoneStanza returns the zone statement of a zone, on one line. If
+// inView is set, the zone is the one of that view.
+func (c *bindProvider) zoneStanza(unique, file, inView string) string {
+	name, _, _ := strings.Cut(unique, "!")
+	if inView != "" {
+		return fmt.Sprintf("zone %q { in-view %q; };", name, inView)
+	}
+	options := ""
+	if c.zoneOptions != "" {
+		options = c.zoneOptions + " "
+	}
+	return fmt.Sprintf("zone %q { type primary; file %q; %s}; // %s", name, file, options, unique)
+}
+
+// namedConfCorrection returns a correction that adds the zone to the
+// named.conf file, or nil if it is there already.
+func (c *bindProvider) namedConfCorrection(dc *models.DomainConfig, zonefile string) (*models.Correction, error) {
+	unique := dc.Metadata[models.DomainUniqueName]
+	if unique == "" {
+		unique = dc.Name
+	}
+	if c.server != ServerBIND && dc.Metadata[models.DomainTag] != "" {
+		return nil, fmt.Errorf("%s: %s has no views, so split horizon zones need a provider per tag, without %s", unique, c.server, c.server.confKey())
+	}
+	file := c.serverPath(zonefile)
+
+	namedconfMu.Lock()
+	zones, err := c.readNamedConf()
+	namedconfMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if zones[unique] == file {
+		return nil, nil
+	}
+	return &models.Correction{
+		Msg: fmt.Sprintf("ADD zone %s to %s", unique, c.namedconf),
+		F: func() error {
+			namedconfMu.Lock()
+			defer namedconfMu.Unlock()
+			zones, err := c.readNamedConf()
+			if err != nil {
+				return err
+			}
+			zones[unique] = file
+			return c.writeNamedConf(zones)
+		},
+	}, nil
+}
+
+// removeFromNamedConf removes the zone, with any tag, from the named.conf
+// file. It reports whether it was there.
+func (c *bindProvider) removeFromNamedConf(domain string) (bool, error) {
+	namedconfMu.Lock()
+	defer namedconfMu.Unlock()
+	zones, err := c.readNamedConf()
+	if err != nil {
+		return false, err
+	}
+	found := false
+	for unique := range zones {
+		if name, _, _ := strings.Cut(unique, "!"); name == domain {
+			delete(zones, unique)
+			found = true
+		}
+	}
+	if !found {
+		return false, nil
+	}
+	return true, c.writeNamedConf(zones)
+}
+
+func (s Server) String() string {
+	switch s {
+	case ServerNSD:
+		return "NSD"
+	case ServerKnot:
+		return "Knot"
+	default:
+		return "BIND"
+	}
+}
@@ -0,0 +1,178 @@
+package bind
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerateNamedConf(t *testing.T) {
+	c := &bindProvider{
+		directory:       "zones",
+		serverdirectory: "/var/named/zones",
+		views:           []string{"internal", "external"},
+		viewOptions:     map[string]string{"internal": "match-clients { 10.0.0.0/8; };"},
+		zoneOptions:     "allow-transfer { none; };",
+	}
+	zones := map[string]string{
+		"example.com!internal": c.serverPath("zones/example.com!internal.zone"),
+		"example.com!external": c.serverPath("zones/example.com!external.zone"),
+		"example.net":          c.serverPath("zones/example.net.zone"),
+	}
+	got := c.generateNamedConf(zones)
+	got = got[strings.Index(got, "\n")+1:] // Skip the timestamp.
+	want := `
+view "internal" {
+	match-clients { 10.0.0.0/8; };
+	zone "example.com" { type primary; file "/var/named/zones/example.com!internal.zone"; allow-transfer { none; }; }; // example.com!internal
+	zone "example.net" { type primary; file "/var/named/zones/example.net.zone"; allow-transfer { none; }; }; // example.net
+};
+
+view "external" {
+	zone "example.com" { type primary; file "/var/named/zones/example.com!external.zone"; allow-transfer { none; }; }; // example.com!external
+	zone "example.net" { in-view "internal"; };
+};
+`
+	if got != want {
+		t.Errorf("generateNamedConf() =\n%s\nwant:\n%s", got, want)
+	}
+
+	// A tagged zone replaces the zone without a tag in its view, so the
+	// other views refer to the next view that has it.
+	zones = map[string]string{
+		"example.com!internal": "example.com!internal.zone",
+		"example.com":          "example.com.zone",
+	}
+	got = c.generateNamedConf(zones)
+	want = `
+view "internal" {
+	match-clients { 10.0.0.0/8; };
+	zone "example.com" { type primary; file "example.com!internal.zone"; allow-transfer { none; }; }; // example.com!internal
+};
+
+view "external" {
+	zone "example.com" { type primary; file "example.com.zone"; allow-transfer { none; }; }; // example.com
+};
+`
+	if got[strings.Index(got, "\n")+1:] != want {
+		t.Errorf("generateNamedConf() =\n%s\nwant:\n%s", got, want)
+	}
+
+	// Without tags, there are no views.
+	got = c.generateNamedConf(map[string]string{"example.net": "example.net.zone"})
+	want = `zone "example.net" { type primary; file "example.net.zone"; allow-transfer { none; }; }; // example.net
+`
+	if got[strings.Index(got, "\n")+1:] != want {
+		t.Errorf("generateNamedConf() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestNamedConf(t *testing.T) {
+	dir := t.TempDir()
+	c := &bindProvider{directory: dir, namedconf: filepath.Join(dir, "named.conf.zones")}
+	zones := map[string]string{
+		"example.com!internal": "zones/example.com!internal.zone",
+		"example.com":          "zones/example.com.zone",
+		"example.net":          "zones/example.net.zone",
+	}
+	if err := c.writeNamedConf(zones); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(c.namedconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The only view has example.com!internal, so example.com is in none.
+	want := `
+view "internal" {
+	zone "example.com" { type primary; file "zones/example.com!internal.zone"; }; // example.com!internal
+	zone "example.net" { type primary; file "zones/example.net.zone"; }; // example.net
+};
+
+# Every view has a tagged zone of the same name as these:
+# zone "example.com" { type primary; file "zones/example.com.zone"; }; // example.com
+`
+	if got := string(content[bytes.IndexByte(content, '\n')+1:]); got != want {
+		t.Errorf("named.conf =\n%s\nwant:\n%s", got, want)
+	}
+	got, err := c.readNamedConf()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, zones) {
+		t.Errorf("readNamedConf() = %v, want %v", got, zones)
+	}
+
+	if err := c.DeleteZone("example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteZone("example.com"); err == nil {
+		t.Error("DeleteZone() of a deleted zone succeeded")
+	}
+	got, err = c.readNamedConf()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"example.net": "zones/example.net.zone"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("readNamedConf() = %v, want %v", got, want)
+	}
+}
+
+func TestGenerateZoneList(t *testing.T) {
+	zones := map[string]string{
+		"example.net": "/var/lib/zones/example.net.zone",
+		"example.com": "/var/lib/zones/example.com.zone",
+	}
+	tests := []struct {
+		server Server
+		want   string
+	}{
+		{ServerNSD, `
+zone:
+	name: "example.com"
+	zonefile: "/var/lib/zones/example.com.zone"
+	provide-xfr: 192.0.2.1 NOKEY
+
+zone:
+	name: "example.net"
+	zonefile: "/var/lib/zones/example.net.zone"
+	provide-xfr: 192.0.2.1 NOKEY
+`},
+		{ServerKnot, `zone:
+  - domain: "example.com"
+    file: "/var/lib/zones/example.com.zone"
+    acl: transfer
+  - domain: "example.net"
+    file: "/var/lib/zones/example.net.zone"
+    acl: transfer
+`},
+	}
+	options := map[Server]string{
+		ServerNSD:  "provide-xfr: 192.0.2.1 NOKEY",
+		ServerKnot: "acl: transfer",
+	}
+	for _, tt := range tests {
+		t.Run(tt.server.String(), func(t *testing.T) {
+			dir := t.TempDir()
+			c := &bindProvider{server: tt.server, directory: dir, namedconf: filepath.Join(dir, tt.server.confKey()), zoneOptions: options[tt.server]}
+			got := c.generateNamedConf(zones)
+			if got = got[strings.Index(got, "\n")+1:]; got != tt.want {
+				t.Errorf("generateNamedConf() =\n%s\nwant:\n%s", got, tt.want)
+			}
+
+			if err := c.writeNamedConf(zones); err != nil {
+				t.Fatal(err)
+			}
+			read, err := c.readNamedConf()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(read, zones) {
+				t.Errorf("readNamedConf() = %v, want %v", read, zones)
+			}
+		})
+	}
+}
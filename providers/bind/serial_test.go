@@ -3,6 +3,9 @@ package bind
 import (
 	"testing"
 	"time"
+
+	"github.com/StackExchange/dnscontrol/v4/pkg/bindserial"
+	"github.com/StackExchange/dnscontrol/v4/pkg/soaserial"
 )
 
 func Test_generate_serial_1(t *testing.T) {
@@ -46,9 +49,22 @@ func Test_generate_serial_1(t *testing.T) {
 		nowFunc = func() time.Time {
 			return tst.Today
 		}
-		found := generateSerial(tst.Given)
+		found := generateSerial(soaserial.DateSerial, tst.Given, nil)
 		if expected != found {
 			t.Fatalf("Test:%d/%v: Expected (%d) got (%d)\n", i, tst.Given, expected, found)
 		}
 	}
 }
+
+func TestNextSerial(t *testing.T) {
+	c := &bindProvider{serialPolicy: soaserial.Increment}
+	if got := c.NextSerial(41, nil); got != 42 {
+		t.Errorf("NextSerial(41) = %d, want 42", got)
+	}
+	// check-serials must see the serial that push --bindserial gives.
+	bindserial.ForcedValue = 7
+	defer func() { bindserial.ForcedValue = 0 }()
+	if got := c.NextSerial(41, nil); got != 7 {
+		t.Errorf("NextSerial(41) with --bindserial = %d, want 7", got)
+	}
+}
@@ -7,7 +7,10 @@ import (
 	"github.com/StackExchange/dnscontrol/v4/pkg/soautil"
 )
 
-func makeSoa(origin string, defSoa *SoaDefaults, existing, desired *models.RecordConfig) (*models.RecordConfig, uint32) {
+// makeSoa returns the SOA of a zone, from desired, existing, defSoa and
+// hardcoded defaults. Its serial number is the one to be incremented
+// (see generateSerial).
+func makeSoa(origin string, defSoa *SoaDefaults, existing, desired *models.RecordConfig) *models.RecordConfig {
 	// Create a SOA record.  Take data from desired, existing, default,
 	// or hardcoded defaults.
 	soaRec := models.RecordConfig{}
@@ -43,7 +46,7 @@ func makeSoa(origin string, defSoa *SoaDefaults, existing, desired *models.Recor
 		panic(err) // Should never happen.
 	}
 
-	return &soaRec, generateSerial(soaRec.SoaSerial)
+	return &soaRec
 }
 
 func firstNonNull(items ...string) string {
@@ -12,7 +12,7 @@ type Capability uint32
 const (
 	// Keep this list sorted.
 	// If you add something here, you probably want to also add it to
-	// pkg/normalize/validate.go checkProviderCapabilities() or
+	// pkg/normalize/validate.go CheckProviderCapabilities() or
 	// somewhere near there.
 
 	// CanAutoDNSSEC indicates that the provider can automatically handle DNSSEC,
@@ -40,6 +40,10 @@ const (
 	// CanUseCAA indicates the provider can handle CAA records
 	CanUseCAA
 
+	// CanUseComments indicates the provider can store a comment with each
+	// record (set with COMMENT()), and returns it with the record.
+	CanUseComments
+
 	// CanUseDHCID indicates the provider can handle DHCID records
 	CanUseDHCID
 
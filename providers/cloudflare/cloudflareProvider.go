@@ -49,6 +49,7 @@ var features = providers.DocumentationNotes{
 	providers.CanConcur:              providers.Can(),
 	providers.CanUseAlias:            providers.Can("CF automatically flattens CNAME records into A records dynamically"),
 	providers.CanUseCAA:              providers.Can(),
+	providers.CanUseComments:         providers.Can(),
 	providers.CanUseDNSKEY:           providers.Cannot(),
 	providers.CanUseDSForChildren:    providers.Can(),
 	providers.CanUseHTTPS:            providers.Can(),
@@ -209,7 +210,7 @@ func (c *cloudflareProvider) GetZoneRecordsCorrections(dc *models.DomainConfig,
 	var corrections []*models.Correction
 
 	// Cloudflare is a "ByRecord" API.
-	instructions, actualChangeCount, err := diff2.ByRecord(records, dc, genComparable)
+	instructions, actualChangeCount, err := diff2.ByRecord(records, dc, diff2.WithComments(genComparable))
 	if err != nil {
 		return nil, 0, err
 	}
@@ -842,6 +843,9 @@ func (c *cloudflareProvider) nativeToRecord(domain string, cr cloudflare.DNSReco
 		Metadata: map[string]string{},
 	}
 	rc.SetLabelFromFQDN(cr.Name, domain)
+	if cr.Comment != "" {
+		rc.Metadata[models.RecordComment] = cr.Comment
+	}
 
 	if cr.Type == "A" || cr.Type == "AAAA" || cr.Type == "CNAME" {
 		if cr.Proxied != nil {
@@ -174,6 +174,7 @@ func (c *cloudflareProvider) createRecDiff2(rec *models.RecordConfig, domainID s
 				TTL:      int(rec.TTL),
 				Content:  content,
 				Priority: &rec.MxPreference,
+				Comment:  rec.Metadata[models.RecordComment],
 			}
 			if rec.Type == "SRV" {
 				cf.Data = cfSrvData(rec)
@@ -219,6 +220,7 @@ func (c *cloudflareProvider) modifyRecord(domainID, recID string, proxied bool,
 		return errors.New("cannot modify record if domain or record id are empty")
 	}
 
+	comment := rec.Metadata[models.RecordComment]
 	r := cloudflare.UpdateDNSRecordParams{
 		ID:       recID,
 		Proxied:  &proxied,
@@ -227,6 +229,7 @@ func (c *cloudflareProvider) modifyRecord(domainID, recID string, proxied bool,
 		Content:  rec.GetTargetField(),
 		Priority: &rec.MxPreference,
 		TTL:      int(rec.TTL),
+		Comment:  &comment, // "" removes the comment.
 	}
 	if rec.Type == "TXT" {
 		r.Content = rec.GetTargetTXTJoined()
@@ -0,0 +1,19 @@
+// Package knot writes zone files and a knot.conf file for Knot DNS. It
+// shares the zone file generation of the BIND provider.
+package knot
+
+import (
+	"github.com/StackExchange/dnscontrol/v4/providers"
+	"github.com/StackExchange/dnscontrol/v4/providers/bind"
+)
+
+func init() {
+	const providerName = "KNOT"
+	const providerMaintainer = "NEEDS VOLUNTEER"
+	fns := providers.DspFuncs{
+		Initializer:   bind.NewInitializer(bind.ServerKnot),
+		RecordAuditor: bind.AuditRecords,
+	}
+	providers.RegisterDomainServiceProviderType(providerName, fns, bind.Features())
+	providers.RegisterMaintainer(providerName, providerMaintainer)
+}
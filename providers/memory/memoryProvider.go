@@ -0,0 +1,157 @@
+package memory
+
+/*
+
+memory -
+  A reference DNS provider that keeps its zones in memory.
+
+	Every rtype and capability that isn't tied to a specific vendor is
+	supported. The zones live for the lifetime of the process. If
+	`filename` is set in creds.json, the zones are loaded from (and
+	saved to) that JSON file so that state survives between runs.
+
+	This is useful for unit-testing dnsconfig.js macros, for demos, and
+	as the backing store for local simulations of "push".
+
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/StackExchange/dnscontrol/v4/pkg/diff2"
+	"github.com/StackExchange/dnscontrol/v4/providers"
+)
+
+var features = providers.DocumentationNotes{
+	// The default for unlisted capabilities is 'Cannot'.
+	// See providers/capabilities.go for the entire list of capabilities.
+	providers.CanAutoDNSSEC:          providers.Can("Just records that DNSSEC was requested"),
+	providers.CanConcur:              providers.Can(),
+	providers.CanGetZones:            providers.Can(),
+	providers.CanUseAlias:            providers.Can(),
+	providers.CanUseCAA:              providers.Can(),
+	providers.CanUseComments:         providers.Can(),
+	providers.CanUseDHCID:            providers.Can(),
+	providers.CanUseDNAME:            providers.Can(),
+	providers.CanUseDNSKEY:           providers.Can(),
+	providers.CanUseDS:               providers.Can(),
+	providers.CanUseDSForChildren:    providers.Can(),
+	providers.CanUseHTTPS:            providers.Can(),
+	providers.CanUseLOC:              providers.Can(),
+	providers.CanUseNAPTR:            providers.Can(),
+	providers.CanUsePTR:              providers.Can(),
+	providers.CanUseSOA:              providers.Can(),
+	providers.CanUseSRV:              providers.Can(),
+	providers.CanUseSSHFP:            providers.Can(),
+	providers.CanUseSVCB:             providers.Can(),
+	providers.CanUseTLSA:             providers.Can(),
+	providers.DocCreateDomains:       providers.Can(),
+	providers.DocDualHost:            providers.Can(),
+	providers.DocOfficiallySupported: providers.Cannot(),
+	// Vendor-specific rtypes are registered for their own provider only.
+	providers.CanUseAKAMAICDN:    providers.Cannot(),
+	providers.CanUseAzureAlias:   providers.Cannot(),
+	providers.CanUseRoute53Alias: providers.Cannot(),
+}
+
+func init() {
+	const providerName = "MEMORY"
+	const providerMaintainer = "NEEDS VOLUNTEER"
+	fns := providers.DspFuncs{
+		Initializer:   initMemory,
+		RecordAuditor: AuditRecords,
+	}
+	providers.RegisterDomainServiceProviderType(providerName, fns, features)
+	providers.RegisterMaintainer(providerName, providerMaintainer)
+}
+
+// memoryProvider is the provider handle for the MEMORY driver.
+type memoryProvider struct {
+	DefaultNS   []string `json:"default_ns"`
+	nameservers []*models.Nameserver
+	store       *zoneStore
+}
+
+func initMemory(config map[string]string, providermeta json.RawMessage) (providers.DNSServiceProvider, error) {
+	// config -- the key/values from creds.json
+	// providermeta -- the json blob from NewReq('name', 'TYPE', providermeta)
+	api := &memoryProvider{}
+	if len(providermeta) != 0 {
+		if err := json.Unmarshal(providermeta, api); err != nil {
+			return nil, err
+		}
+	}
+
+	var nss []string
+	if config["nameservers"] != "" {
+		nss = strings.Split(config["nameservers"], ",")
+	}
+	for i, ns := range api.DefaultNS {
+		if ns == "" {
+			return nil, fmt.Errorf("empty string in default_ns[%d]", i)
+		}
+		// If it contains a ".", it must end in a ".".
+		if strings.ContainsRune(ns, '.') && ns[len(ns)-1] != '.' {
+			return nil, fmt.Errorf("default_ns (%v) must end with a (.) [https://docs.dnscontrol.org/language-reference/why-the-dot]", ns)
+		}
+		nss = append(nss, strings.TrimSuffix(ns, "."))
+	}
+	var err error
+	api.nameservers, err = models.ToNameservers(nss)
+	if err != nil {
+		return nil, err
+	}
+
+	api.store, err = newZoneStore(config["filename"])
+	if err != nil {
+		return nil, err
+	}
+	return api, nil
+}
+
+// GetNameservers returns the nameservers for a domain.
+func (c *memoryProvider) GetNameservers(string) ([]*models.Nameserver, error) {
+	return c.nameservers, nil
+}
+
+// ListZones returns all the zones in the store.
+func (c *memoryProvider) ListZones() ([]string, error) {
+	return c.store.zoneNames(), nil
+}
+
+// EnsureZoneExists creates a zone if it does not exist.
+func (c *memoryProvider) EnsureZoneExists(domain string) error {
+	return c.store.createZone(domain)
+}
+
+// GetZoneRecords gets the records of a zone and returns them in RecordConfig format.
+func (c *memoryProvider) GetZoneRecords(domain string, meta map[string]string) (models.Records, error) {
+	// Like BIND, a zone that doesn't exist yet is simply empty.
+	return c.store.getRecords(domain)
+}
+
+// GetZoneRecordsCorrections returns a list of corrections that will turn existing records into dc.Records.
+func (c *memoryProvider) GetZoneRecordsCorrections(dc *models.DomainConfig, existing models.Records) ([]*models.Correction, int, error) {
+	result, err := diff2.ByZone(existing, dc, diff2.WithComments(nil))
+	if err != nil {
+		return nil, 0, err
+	}
+	if !result.HasChanges {
+		return nil, 0, nil
+	}
+
+	zone := dc.Name
+	desired := result.DesiredPlus
+	corrections := []*models.Correction{
+		{
+			Msg: strings.Join(result.Msgs, "\n"),
+			F: func() error {
+				return c.store.setRecords(zone, desired)
+			},
+		},
+	}
+	return corrections, result.ActualChangeCount, nil
+}
@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+)
+
+// zoneStore holds the zones managed by a MEMORY provider. If filename
+// is not empty, the zones are persisted to that file as JSON after
+// every change.
+type zoneStore struct {
+	sync.Mutex
+	filename string
+	zones    map[string]models.Records
+}
+
+// newZoneStore creates a store, loading filename if it exists.
+func newZoneStore(filename string) (*zoneStore, error) {
+	s := &zoneStore{
+		filename: filename,
+		zones:    map[string]models.Records{},
+	}
+	if filename == "" {
+		return s, nil
+	}
+
+	content, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		// Not an error. It will be created on the first write.
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("memory: can't read %q: %w", filename, err)
+	}
+	if err := json.Unmarshal(content, &s.zones); err != nil {
+		return nil, fmt.Errorf("memory: can't parse %q: %w", filename, err)
+	}
+	// NameFQDN isn't stored in the JSON. Recompute it.
+	for zone, recs := range s.zones {
+		for _, rec := range recs {
+			rec.SetLabel(rec.GetLabel(), zone)
+		}
+	}
+	return s, nil
+}
+
+// zoneNames returns the names of all zones, sorted.
+func (s *zoneStore) zoneNames() []string {
+	s.Lock()
+	defer s.Unlock()
+
+	names := make([]string, 0, len(s.zones))
+	for name := range s.zones {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// createZone adds an empty zone if it does not already exist.
+func (s *zoneStore) createZone(zone string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if _, ok := s.zones[zone]; ok {
+		return nil
+	}
+	s.zones[zone] = models.Records{}
+	return s.save()
+}
+
+// getRecords returns a copy of the records of a zone.
+func (s *zoneStore) getRecords(zone string) (models.Records, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.zones[zone].Copy()
+}
+
+// setRecords replaces the records of a zone (creating it if needed).
+func (s *zoneStore) setRecords(zone string, recs models.Records) error {
+	s.Lock()
+	defer s.Unlock()
+
+	c, err := recs.Copy()
+	if err != nil {
+		return err
+	}
+	// Provider-specific pointers make no sense once the record is
+	// stored, and a real provider wouldn't know where it was defined.
+	for _, rec := range c {
+		rec.Original = nil
+		rec.Source = ""
+	}
+	s.zones[zone] = c
+	return s.save()
+}
+
+// save writes the store to its file, if it has one. The caller must
+// hold the lock.
+func (s *zoneStore) save() error {
+	if s.filename == "" {
+		return nil
+	}
+
+	content, err := json.MarshalIndent(s.zones, "", "  ")
+	if err != nil {
+		return fmt.Errorf("memory: can't encode zones: %w", err)
+	}
+
+	// Write to a temp file and rename it into place so that a crash
+	// never leaves a half-written file behind.
+	tmp, err := os.CreateTemp(filepath.Dir(s.filename), filepath.Base(s.filename)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("memory: can't write %q: %w", s.filename, err)
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("memory: can't write %q: %w", s.filename, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("memory: can't write %q: %w", s.filename, err)
+	}
+	return os.Rename(tmp.Name(), s.filename)
+}
@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+)
+
+func makeRec(zone, label, typ, target string) *models.RecordConfig {
+	r := &models.RecordConfig{Type: typ, TTL: 300}
+	r.SetLabel(label, zone)
+	if err := r.SetTarget(target); err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func Test_zoneStore_persist(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "zones.json")
+
+	s, err := newZoneStore(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.createZone("example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.createZone("empty.com"); err != nil {
+		t.Fatal(err)
+	}
+	recs := models.Records{
+		makeRec("example.com", "@", "A", "1.2.3.4"),
+		makeRec("example.com", "www", "CNAME", "example.com."),
+	}
+	if err := s.setRecords("example.com", recs); err != nil {
+		t.Fatal(err)
+	}
+
+	// Re-read the file into a new store.
+	s2, err := newZoneStore(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s2.zoneNames(), []string{"empty.com", "example.com"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("zoneNames() = %v, want %v", got, want)
+	}
+	got, err := s2.getRecords("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(recs) {
+		t.Fatalf("getRecords() returned %d records, want %d", len(got), len(recs))
+	}
+	for i := range recs {
+		if got[i].NameFQDN != recs[i].NameFQDN || got[i].ToComparableNoTTL() != recs[i].ToComparableNoTTL() {
+			t.Errorf("record %d = %v, want %v", i, got[i], recs[i])
+		}
+	}
+}
+
+func Test_zoneStore_isolation(t *testing.T) {
+	s, err := newZoneStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.setRecords("example.com", models.Records{makeRec("example.com", "@", "A", "1.2.3.4")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Modifying what was returned must not modify the store.
+	got, _ := s.getRecords("example.com")
+	got[0].TTL = 999
+	again, _ := s.getRecords("example.com")
+	if again[0].TTL != 300 {
+		t.Errorf("store was modified through a returned record: TTL=%d", again[0].TTL)
+	}
+
+	// Unknown zones are empty, not an error.
+	if recs, err := s.getRecords("unknown.com"); err != nil || len(recs) != 0 {
+		t.Errorf("getRecords(unknown) = %v, %v; want empty, nil", recs, err)
+	}
+}
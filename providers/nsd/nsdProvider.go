@@ -0,0 +1,19 @@
+// Package nsd writes zone files and an nsd.conf file for NSD. It
+// shares the zone file generation of the BIND provider.
+package nsd
+
+import (
+	"github.com/StackExchange/dnscontrol/v4/providers"
+	"github.com/StackExchange/dnscontrol/v4/providers/bind"
+)
+
+func init() {
+	const providerName = "NSD"
+	const providerMaintainer = "NEEDS VOLUNTEER"
+	fns := providers.DspFuncs{
+		Initializer:   bind.NewInitializer(bind.ServerNSD),
+		RecordAuditor: bind.AuditRecords,
+	}
+	providers.RegisterDomainServiceProviderType(providerName, fns, bind.Features())
+	providers.RegisterMaintainer(providerName, providerMaintainer)
+}
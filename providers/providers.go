@@ -7,6 +7,7 @@ import (
 	"log"
 
 	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/StackExchange/dnscontrol/v4/pkg/soaserial"
 )
 
 // Registrar is an interface for a domain registrar. It can return a list of needed corrections to be applied in the future. Implement this only if the provider is a "registrar" (i.e. can update the NS records of the parent to a domain).
@@ -32,6 +33,22 @@ type ZoneLister interface {
 	ListZones() ([]string, error)
 }
 
+// ZoneDeleter should be implemented by providers that have the
+// ability to delete zones. This facilitates the "delete-zones" command.
+type ZoneDeleter interface {
+	DeleteZone(domain string) error
+}
+
+// SerialPolicier should be implemented by providers that choose the
+// serial numbers of the SOA records themselves. This facilitates the
+// "check-serials" command.
+type SerialPolicier interface {
+	SerialPolicy() soaserial.Policy
+	// NextSerial returns the serial number that follows oldSerial
+	// when the zone's records become content (see soaserial.Content).
+	NextSerial(oldSerial uint32, content []byte) uint32
+}
+
 // RegistrarInitializer is a function to create a registrar. Function will be passed the unprocessed json payload from the configuration file for the given provider.
 type RegistrarInitializer func(map[string]string) (Registrar, error)
 
@@ -0,0 +1,281 @@
+package tinydns
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/miekg/dns"
+)
+
+// The defaults of tinydns-data for the fields of an SOA ("Z") line.
+const (
+	defaultRefresh = 16384
+	defaultRetry   = 2048
+	defaultExpire  = 1048576
+	defaultMinttl  = 2560
+	defaultSoaTTL  = 2560
+	defaultNsTTL   = 259200
+	defaultTTL     = 86400
+)
+
+// maxTXTSegment is the length of the segments that tinydns-data splits
+// the text of a "'" line into.
+const maxTXTSegment = 127
+
+// formatLine returns the line of a tinydns data file for rec. If lo is
+// not empty, the record is only served to the clients of that location.
+func formatLine(rec *models.RecordConfig, lo string) (string, error) {
+	rr := rec.ToRR()
+	if rr == nil {
+		return "", fmt.Errorf("tinydns: can't convert %s %s", rec.Type, rec.GetLabelFQDN())
+	}
+	name := escape(fqdn(rr.Header().Name))
+	ttl := rr.Header().Ttl
+	switch r := rr.(type) {
+	case *dns.A:
+		return fmt.Sprintf("+%s:%s", name, r.A) + tail(ttl, lo), nil
+	case *dns.NS:
+		return fmt.Sprintf("&%s::%s", name, escape(fqdn(r.Ns))) + tail(ttl, lo), nil
+	case *dns.MX:
+		return fmt.Sprintf("@%s::%s:%d", name, escape(fqdn(r.Mx)), r.Preference) + tail(ttl, lo), nil
+	case *dns.CNAME:
+		return fmt.Sprintf("C%s:%s", name, escape(fqdn(r.Target))) + tail(ttl, lo), nil
+	case *dns.PTR:
+		return fmt.Sprintf("^%s:%s", name, escape(fqdn(r.Ptr))) + tail(ttl, lo), nil
+	case *dns.TXT:
+		// tinydns-data splits the text into segments itself, so only a
+		// single short segment survives the round trip.
+		if len(r.Txt) == 1 && len(r.Txt[0]) <= maxTXTSegment {
+			return fmt.Sprintf("'%s:%s", name, escape(r.Txt[0])) + tail(ttl, lo), nil
+		}
+	case *dns.SOA:
+		serial := ""
+		if r.Serial != 0 {
+			// Without a serial, tinydns uses the time of the data file.
+			serial = strconv.FormatUint(uint64(r.Serial), 10)
+		}
+		return fmt.Sprintf("Z%s:%s:%s:%s:%d:%d:%d:%d", name, escape(fqdn(r.Ns)), escape(fqdn(r.Mbox)),
+			serial, r.Refresh, r.Retry, r.Expire, r.Minttl) + tail(ttl, lo), nil
+	}
+
+	// Any other record is written in the generic format, with its
+	// rdata in wire format.
+	generic := new(dns.RFC3597)
+	if err := generic.ToRFC3597(rr); err != nil {
+		return "", fmt.Errorf("tinydns: can't encode %s %s: %w", rec.Type, rec.GetLabelFQDN(), err)
+	}
+	rdata, err := hex.DecodeString(generic.Rdata)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(":%s:%d:%s", name, rr.Header().Rrtype, escape(string(rdata))) + tail(ttl, lo), nil
+}
+
+// defaultSoaLine returns the "Z" line of a zone without SOA(). The
+// fields that are left empty get the defaults of tinydns-data, and a
+// zero serial is left empty too.
+func defaultSoaLine(zone, ns string, serial uint32, lo string) string {
+	line := fmt.Sprintf("Z%s:%s:%s", escape(zone), escape(fqdn(ns)), escape("hostmaster."+zone))
+	if serial != 0 {
+		line += fmt.Sprintf(":%d", serial)
+		if lo != "" {
+			line += strings.Repeat(":", 7) + lo
+		}
+	} else if lo != "" {
+		line += strings.Repeat(":", 8) + lo
+	}
+	return line
+}
+
+// tail returns the ttl, timestamp and location fields of a line.
+func tail(ttl uint32, lo string) string {
+	if lo == "" {
+		return fmt.Sprintf(":%d", ttl)
+	}
+	return fmt.Sprintf(":%d::%s", ttl, lo)
+}
+
+// parseLine returns the record of a line of a tinydns data file, or nil
+// if the line has none. Lines that define several records, like "."
+// and "=", only give the record of the zone's own name.
+func parseLine(line, origin string) (*models.RecordConfig, error) {
+	if line == "" {
+		return nil, nil
+	}
+	fields := strings.Split(line[1:], ":")
+	field := func(i int) string {
+		if i < len(fields) {
+			return string(unescape(fields[i]))
+		}
+		return ""
+	}
+	number := func(i int, def uint32) (uint32, error) {
+		if field(i) == "" {
+			return def, nil
+		}
+		n, err := strconv.ParseUint(field(i), 10, 32)
+		return uint32(n), err
+	}
+	// A host name without a dot is relative to the record's name, in
+	// the ".ns." or ".mx." subdomain.
+	host := func(i int, sub string) string {
+		if x := field(i); strings.Contains(x, ".") {
+			return dns.Fqdn(x)
+		}
+		return dns.Fqdn(field(i) + "." + sub + "." + field(0))
+	}
+
+	hdr := dns.RR_Header{Name: dns.Fqdn(field(0)), Class: dns.ClassINET}
+	var rr dns.RR
+	var err error
+	switch line[0] {
+	case '#', '%', '-':
+		// Comments, locations and disabled records.
+		return nil, nil
+	case '+', '=':
+		hdr.Rrtype = dns.TypeA
+		hdr.Ttl, err = number(2, defaultTTL)
+		rr = &dns.A{Hdr: hdr, A: net.ParseIP(field(1))}
+	case '3', '6':
+		hdr.Rrtype = dns.TypeAAAA
+		hdr.Ttl, err = number(2, defaultTTL)
+		ip, ipErr := hex.DecodeString(field(1))
+		if ipErr != nil || len(ip) != net.IPv6len {
+			err = fmt.Errorf("bad IPv6 address %q", field(1))
+		}
+		rr = &dns.AAAA{Hdr: hdr, AAAA: ip}
+	case '&', '.':
+		hdr.Rrtype = dns.TypeNS
+		hdr.Ttl, err = number(3, defaultNsTTL)
+		rr = &dns.NS{Hdr: hdr, Ns: host(2, "ns")}
+	case '@':
+		hdr.Rrtype = dns.TypeMX
+		var pref uint32
+		if pref, err = number(3, 0); err == nil {
+			hdr.Ttl, err = number(4, defaultTTL)
+		}
+		rr = &dns.MX{Hdr: hdr, Mx: host(2, "mx"), Preference: uint16(pref)}
+	case 'C':
+		hdr.Rrtype = dns.TypeCNAME
+		hdr.Ttl, err = number(2, defaultTTL)
+		rr = &dns.CNAME{Hdr: hdr, Target: dns.Fqdn(field(1))}
+	case '^':
+		hdr.Rrtype = dns.TypePTR
+		hdr.Ttl, err = number(2, defaultTTL)
+		rr = &dns.PTR{Hdr: hdr, Ptr: dns.Fqdn(field(1))}
+	case '\'':
+		hdr.Rrtype = dns.TypeTXT
+		hdr.Ttl, err = number(2, defaultTTL)
+		rr = &dns.TXT{Hdr: hdr, Txt: []string{field(1)}}
+	case 'Z':
+		hdr.Rrtype = dns.TypeSOA
+		soa := &dns.SOA{Hdr: hdr, Ns: dns.Fqdn(field(1)), Mbox: dns.Fqdn(field(2))}
+		for _, f := range []struct {
+			p   *uint32
+			i   int
+			def uint32
+		}{
+			{&soa.Serial, 3, 0},
+			{&soa.Refresh, 4, defaultRefresh},
+			{&soa.Retry, 5, defaultRetry},
+			{&soa.Expire, 6, defaultExpire},
+			{&soa.Minttl, 7, defaultMinttl},
+			{&soa.Hdr.Ttl, 8, defaultSoaTTL},
+		} {
+			if *f.p, err = number(f.i, f.def); err != nil {
+				break
+			}
+		}
+		rr = soa
+	case ':':
+		rr, err = parseGeneric(hdr, field(1), []byte(field(2)), field(3))
+	default:
+		return nil, fmt.Errorf("unknown line type %q", line[0])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w", line, err)
+	}
+	rec, err := models.RRtoRC(rr, origin)
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// parseGeneric returns the record of a ":" line, which has the type
+// number and the wire format of the rdata.
+func parseGeneric(hdr dns.RR_Header, rrtype string, rdata []byte, ttl string) (dns.RR, error) {
+	t, err := strconv.ParseUint(rrtype, 10, 16)
+	if err != nil {
+		return nil, err
+	}
+	hdr.Rrtype = uint16(t)
+	hdr.Ttl = defaultTTL
+	if ttl != "" {
+		n, err := strconv.ParseUint(ttl, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		hdr.Ttl = uint32(n)
+	}
+
+	// Pack the record as an unknown one, and unpack it as its type.
+	generic := &dns.RFC3597{Hdr: hdr, Rdata: hex.EncodeToString(rdata)}
+	msg := make([]byte, dns.Len(generic)+len(rdata))
+	off, err := dns.PackRR(generic, msg, 0, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	rr, _, err := dns.UnpackRR(msg[:off], 0)
+	return rr, err
+}
+
+// fqdn returns name without the trailing dot, as tinydns writes names.
+func fqdn(name string) string {
+	return strings.TrimSuffix(name, ".")
+}
+
+// escape returns s with the bytes that can't be in a field of a data
+// file as octal escapes.
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c > '~' || c == ':' || c == '\\' {
+			fmt.Fprintf(&b, "\\%03o", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// unescape reverses escape. Like tinydns-data, it accepts escapes of
+// one to three octal digits.
+func unescape(s string) []byte {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b = append(b, s[i])
+			continue
+		}
+		var c byte
+		n := 0
+		for ; n < 3 && i+1+n < len(s) && s[i+1+n] >= '0' && s[i+1+n] <= '7'; n++ {
+			c = c<<3 | (s[i+1+n] - '0')
+		}
+		if n == 0 {
+			// An escaped character is itself.
+			b = append(b, s[i+1])
+			i++
+			continue
+		}
+		b = append(b, c)
+		i += n
+	}
+	return b
+}
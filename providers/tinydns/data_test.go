@@ -0,0 +1,111 @@
+package tinydns
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/miekg/dns"
+)
+
+func TestFormatLine(t *testing.T) {
+	tests := []struct {
+		rr   string
+		lo   string
+		want string
+	}{
+		{"example.com. 300 IN A 192.0.2.1", "", "+example.com:192.0.2.1:300"},
+		{"www.example.com. 300 IN A 192.0.2.1", "in", "+www.example.com:192.0.2.1:300::in"},
+		{"example.com. 3600 IN NS ns1.example.net.", "", "&example.com::ns1.example.net:3600"},
+		{"example.com. 300 IN MX 10 mail.example.com.", "", "@example.com::mail.example.com:10:300"},
+		{"www.example.com. 300 IN CNAME example.com.", "", "Cwww.example.com:example.com:300"},
+		{"1.2.0.192.in-addr.arpa. 300 IN PTR host.example.com.", "", "^1.2.0.192.in-addr.arpa:host.example.com:300"},
+		{`example.com. 300 IN TXT "v=spf1 a:mail.example.com -all"`, "", `'example.com:v=spf1\040a\072mail.example.com\040-all:300`},
+		{"example.com. 300 IN SOA ns1.example.com. hostmaster.example.com. 0 3600 600 604800 300", "", "Zexample.com:ns1.example.com:hostmaster.example.com::3600:600:604800:300:300"},
+		{"example.com. 300 IN AAAA 2001:db8::1", "", `:example.com:28:\040\001\015\270\000\000\000\000\000\000\000\000\000\000\000\001:300`},
+		{`example.com. 300 IN TXT "` + strings.Repeat("x", 130) + `"`, "ex", `:example.com:16:\202` + strings.Repeat("x", 130) + `:300::ex`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rr, func(t *testing.T) {
+			rr, err := dns.NewRR(tt.rr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec, err := models.RRtoRC(rr, "example.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := formatLine(&rec, tt.lo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("formatLine() = %s, want %s", got, tt.want)
+			}
+
+			// The line gives the record back.
+			parsed, err := parseLine(got, "example.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed.ToRR().String() != rr.String() {
+				t.Errorf("parseLine(%s) = %s, want %s", got, parsed.ToRR(), rr)
+			}
+		})
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{".example.com:192.0.2.53:a", "example.com.\t259200\tIN\tNS\ta.ns.example.com."},
+		{"@example.com:192.0.2.25:mx1:5", "example.com.\t86400\tIN\tMX\t5 mx1.mx.example.com."},
+		{"=www.example.com:192.0.2.80", "www.example.com.\t86400\tIN\tA\t192.0.2.80"},
+		{"6www.example.com:20010db8000000000000000000000001:60", "www.example.com.\t60\tIN\tAAAA\t2001:db8::1"},
+		{"Zexample.com:ns1.example.com.:hostmaster.example.com.", "example.com.\t2560\tIN\tSOA\tns1.example.com. hostmaster.example.com. 0 16384 2048 1048576 2560"},
+	}
+	for _, tt := range tests {
+		rec, err := parseLine(tt.line, "example.com")
+		if err != nil {
+			t.Errorf("parseLine(%s): %v", tt.line, err)
+			continue
+		}
+		if got := rec.ToRR().String(); got != tt.want {
+			t.Errorf("parseLine(%s) = %s, want %s", tt.line, got, tt.want)
+		}
+	}
+
+	for _, line := range []string{"", "# comment", "%in:10", "-example.com:192.0.2.1"} {
+		if rec, err := parseLine(line, "example.com"); rec != nil || err != nil {
+			t.Errorf("parseLine(%s) = %v, %v; want nothing", line, rec, err)
+		}
+	}
+	if _, err := parseLine("!example.com", "example.com"); err == nil {
+		t.Error("parseLine() of an unknown line type succeeded")
+	}
+}
+
+func TestDefaultSoaLine(t *testing.T) {
+	tests := []struct {
+		serial uint32
+		lo     string
+		want   string
+	}{
+		{0, "", "Zexample.com:ns1.example.com:hostmaster.example.com"},
+		{0, "in", "Zexample.com:ns1.example.com:hostmaster.example.com::::::::in"},
+		{2024030500, "", "Zexample.com:ns1.example.com:hostmaster.example.com:2024030500"},
+		{2024030500, "in", "Zexample.com:ns1.example.com:hostmaster.example.com:2024030500:::::::in"},
+	}
+	for _, tt := range tests {
+		got := defaultSoaLine("example.com", "ns1.example.com.", tt.serial, tt.lo)
+		if got != tt.want {
+			t.Errorf("defaultSoaLine(%d, %q) = %s, want %s", tt.serial, tt.lo, got, tt.want)
+		}
+		rec, err := parseLine(got, "example.com")
+		if err != nil || rec.SoaSerial != tt.serial {
+			t.Errorf("parseLine(%s) = %v, %v; want serial %d", got, rec, err, tt.serial)
+		}
+	}
+}
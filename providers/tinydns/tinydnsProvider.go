@@ -0,0 +1,371 @@
+package tinydns
+
+/*
+
+tinydns -
+  Generate data files suitable for tinydns (djbdns).
+
+	Each zone is written to its own file, <directory>/<uniquename>.data.
+	After a zone changes, the files of all zones are combined, with the
+	location lines, into the single data file that tinydns-data
+	compiles into data.cdb.
+
+	A zone with a tag is the view of the zone for the clients of the
+	location of the same name: its records are only served to them.
+
+*/
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+	"github.com/StackExchange/dnscontrol/v4/pkg/diff2"
+	"github.com/StackExchange/dnscontrol/v4/pkg/printer"
+	"github.com/StackExchange/dnscontrol/v4/pkg/soaserial"
+	"github.com/StackExchange/dnscontrol/v4/providers"
+)
+
+var features = providers.DocumentationNotes{
+	// The default for unlisted capabilities is 'Cannot'.
+	// See providers/capabilities.go for the entire list of capabilities.
+	providers.CanConcur:              providers.Can(),
+	providers.CanGetZones:            providers.Can(),
+	providers.CanUseCAA:              providers.Can(),
+	providers.CanUseDHCID:            providers.Can(),
+	providers.CanUseDNAME:            providers.Can(),
+	providers.CanUseDS:               providers.Can(),
+	providers.CanUseDNSKEY:           providers.Can(),
+	providers.CanUseHTTPS:            providers.Can(),
+	providers.CanUseLOC:              providers.Can(),
+	providers.CanUseNAPTR:            providers.Can(),
+	providers.CanUsePTR:              providers.Can(),
+	providers.CanUseSOA:              providers.Can("A default SOA is written unless SOA() is used. The serial follows the `serial-policy`"),
+	providers.CanUseSRV:              providers.Can(),
+	providers.CanUseSSHFP:            providers.Can(),
+	providers.CanUseSVCB:             providers.Can(),
+	providers.CanUseTLSA:             providers.Can(),
+	providers.DocCreateDomains:       providers.Can("Driver just maintains list of data files. It should automatically add missing ones."),
+	providers.DocDualHost:            providers.Can(),
+	providers.DocOfficiallySupported: providers.Cannot(),
+}
+
+func init() {
+	const providerName = "TINYDNS"
+	const providerMaintainer = "NEEDS VOLUNTEER"
+	fns := providers.DspFuncs{
+		Initializer:   initTinydns,
+		RecordAuditor: AuditRecords,
+	}
+	providers.RegisterDomainServiceProviderType(providerName, fns, features)
+	providers.RegisterMaintainer(providerName, providerMaintainer)
+}
+
+// dataMu serializes the updates of the combined data file, which is
+// shared by all zones.
+var dataMu sync.Mutex
+
+// tinydnsProvider is the provider handle for the TINYDNS driver.
+type tinydnsProvider struct {
+	DefaultNS    []string `json:"default_ns"`
+	nameservers  []*models.Nameserver
+	directory    string
+	data         string              // The combined data file.
+	locations    map[string][]string // The IP prefixes of each location.
+	serialPolicy soaserial.Policy    // Empty: tinydns uses the time of the data file.
+}
+
+func initTinydns(config map[string]string, providermeta json.RawMessage) (providers.DNSServiceProvider, error) {
+	// config -- the key/values from creds.json
+	// providermeta -- the json blob from NewReq('name', 'TYPE', providermeta)
+	api := &tinydnsProvider{
+		directory: config["directory"],
+		data:      config["data"],
+		locations: map[string][]string{},
+	}
+	if api.directory == "" {
+		api.directory = "zones"
+	}
+	var err error
+	api.serialPolicy, err = soaserial.ParsePolicy(config["serial-policy"], "")
+	if err != nil {
+		return nil, fmt.Errorf("TINYDNS: %w", err)
+	}
+	if api.data == "" {
+		api.data = filepath.Join(api.directory, "data")
+	}
+	for key, value := range config {
+		lo, ok := strings.CutPrefix(key, "location-")
+		if !ok {
+			continue
+		}
+		if !validLocation(lo) {
+			return nil, fmt.Errorf("tinydns: %s: a location is 1 or 2 letters or digits", key)
+		}
+		// An empty list of prefixes matches every client.
+		api.locations[lo] = []string{}
+		for _, prefix := range strings.Split(value, ",") {
+			if prefix = strings.TrimSpace(prefix); prefix != "" {
+				api.locations[lo] = append(api.locations[lo], prefix)
+			}
+		}
+	}
+	if len(providermeta) != 0 {
+		if err := json.Unmarshal(providermeta, api); err != nil {
+			return nil, err
+		}
+	}
+
+	var nss []string
+	for i, ns := range api.DefaultNS {
+		if ns == "" {
+			return nil, fmt.Errorf("empty string in default_ns[%d]", i)
+		}
+		// If it contains a ".", it must end in a ".".
+		if strings.ContainsRune(ns, '.') && ns[len(ns)-1] != '.' {
+			return nil, fmt.Errorf("default_ns (%v) must end with a (.) [https://docs.dnscontrol.org/language-reference/why-the-dot]", ns)
+		}
+		nss = append(nss, strings.TrimSuffix(ns, "."))
+	}
+	api.nameservers, err = models.ToNameservers(nss)
+	return api, err
+}
+
+// validLocation reports whether lo can be a location code of tinydns.
+func validLocation(lo string) bool {
+	if lo == "" || len(lo) > 2 {
+		return false
+	}
+	for _, c := range lo {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// GetNameservers returns the nameservers for a domain.
+func (c *tinydnsProvider) GetNameservers(string) ([]*models.Nameserver, error) {
+	return c.nameservers, nil
+}
+
+// SerialPolicy returns the policy of the SOA serial numbers. It is
+// empty if the serial numbers are left to tinydns.
+func (c *tinydnsProvider) SerialPolicy() soaserial.Policy {
+	return c.serialPolicy
+}
+
+// NextSerial returns the serial number that follows oldSerial.
+func (c *tinydnsProvider) NextSerial(oldSerial uint32, content []byte) uint32 {
+	return soaserial.Next(c.serialPolicy, oldSerial, time.Now(), content)
+}
+
+// zoneFilename returns the data file of a zone, given its unique name.
+func (c *tinydnsProvider) zoneFilename(unique string) string {
+	return filepath.Join(c.directory, unique+".data")
+}
+
+// ListZones returns the zones that have a data file.
+func (c *tinydnsProvider) ListZones() ([]string, error) {
+	filenames, err := filepath.Glob(filepath.Join(c.directory, "*.data"))
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var zones []string
+	for _, filename := range filenames {
+		zone, _, _ := strings.Cut(strings.TrimSuffix(filepath.Base(filename), ".data"), "!")
+		if !seen[zone] {
+			seen[zone] = true
+			zones = append(zones, zone)
+		}
+	}
+	sort.Strings(zones)
+	return zones, nil
+}
+
+// EnsureZoneExists does nothing: the data file of a zone is written
+// when its records are.
+func (c *tinydnsProvider) EnsureZoneExists(domain string) error {
+	return nil
+}
+
+// GetZoneRecords gets the records of a zone and returns them in RecordConfig format.
+func (c *tinydnsProvider) GetZoneRecords(domain string, meta map[string]string) (models.Records, error) {
+	unique := meta[models.DomainUniqueName]
+	if unique == "" {
+		unique = domain
+	}
+	records, err := readZoneFile(c.zoneFilename(unique), domain)
+	if os.IsNotExist(err) {
+		// Like BIND, a zone that doesn't exist yet is simply empty.
+		return nil, nil
+	}
+	return records, err
+}
+
+// readZoneFile returns the records of a zone's data file.
+func readZoneFile(filename, origin string) (models.Records, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records models.Records
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		rec, err := parseLine(strings.TrimSpace(scanner.Text()), origin)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		if rec != nil {
+			records = append(records, rec)
+		}
+	}
+	return records, scanner.Err()
+}
+
+// GetZoneRecordsCorrections returns a list of corrections that will turn existing records into dc.Records.
+func (c *tinydnsProvider) GetZoneRecordsCorrections(dc *models.DomainConfig, foundRecords models.Records) ([]*models.Correction, int, error) {
+	lo := dc.Metadata[models.DomainTag]
+	if lo != "" {
+		if _, ok := c.locations[lo]; !ok {
+			return nil, 0, fmt.Errorf("tinydns: the tag of %s!%s must be a location; set location-%s in creds.json", dc.Name, lo, lo)
+		}
+	}
+	unique := dc.Metadata[models.DomainUniqueName]
+	if unique == "" {
+		unique = dc.Name
+	}
+
+	// The SOA is only managed if it is in dnsconfig.js. Otherwise the
+	// zone gets a default one.
+	var foundSerial uint32
+	for _, r := range foundRecords {
+		if r.Type == "SOA" {
+			foundSerial = r.SoaSerial
+		}
+	}
+	hasSOA := false
+	for _, r := range dc.Records {
+		if r.Type == "SOA" {
+			hasSOA = true
+		}
+	}
+	if !hasSOA {
+		var existing models.Records
+		for _, r := range foundRecords {
+			if r.Type != "SOA" {
+				existing = append(existing, r)
+			}
+		}
+		foundRecords = existing
+	}
+
+	result, err := diff2.ByZone(foundRecords, dc, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !result.HasChanges {
+		return nil, 0, nil
+	}
+
+	// Without a serial policy, the serial is left empty.
+	var serial uint32
+	if c.serialPolicy != "" {
+		serial = c.NextSerial(foundSerial, soaserial.Content(dc.Records))
+	}
+
+	var lines []string
+	var soa string
+	for _, rec := range result.DesiredPlus {
+		if rec.Type == "SOA" {
+			soaRec := *rec
+			soaRec.SoaSerial = serial
+			rec = &soaRec
+		}
+		line, err := formatLine(rec, lo)
+		if err != nil {
+			return nil, 0, err
+		}
+		if rec.Type == "SOA" {
+			soa = line
+		} else {
+			lines = append(lines, line)
+		}
+	}
+	if soa == "" {
+		ns := dc.Name
+		if len(dc.Nameservers) != 0 {
+			ns = dc.Nameservers[0].Name
+		}
+		soa = defaultSoaLine(dc.Name, ns, serial, lo)
+	}
+	sort.Strings(lines)
+	content := fmt.Sprintf("# generated with dnscontrol %s\n%s\n",
+		time.Now().Format(time.RFC3339), strings.Join(append([]string{soa}, lines...), "\n"))
+
+	filename := c.zoneFilename(unique)
+	return []*models.Correction{
+		{
+			Msg: strings.Join(result.Msgs, "\n"),
+			F: func() error {
+				printer.Printf("WRITING DATA FILE: %v\n", filename)
+				if err := os.MkdirAll(c.directory, 0o750); err != nil {
+					return err
+				}
+				if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+					return err
+				}
+				return c.writeData()
+			},
+		},
+	}, result.ActualChangeCount, nil
+}
+
+// writeData writes the combined data file: the location lines,
+// followed by the data files of all zones.
+func (c *tinydnsProvider) writeData() error {
+	dataMu.Lock()
+	defer dataMu.Unlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# generated with dnscontrol %s. Do not edit.\n", time.Now().Format(time.RFC3339))
+	var los []string
+	for lo := range c.locations {
+		los = append(los, lo)
+	}
+	sort.Strings(los)
+	for _, lo := range los {
+		if len(c.locations[lo]) == 0 {
+			fmt.Fprintf(&b, "%%%s\n", lo)
+		}
+		for _, prefix := range c.locations[lo] {
+			fmt.Fprintf(&b, "%%%s:%s\n", lo, prefix)
+		}
+	}
+
+	// Glob sorts the files, so the zones are always in the same order.
+	filenames, err := filepath.Glob(filepath.Join(c.directory, "*.data"))
+	if err != nil {
+		return err
+	}
+	for _, filename := range filenames {
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		b.Write(content)
+	}
+
+	printer.Printf("WRITING DATA FILE: %v\n", c.data)
+	return os.WriteFile(c.data, []byte(b.String()), 0o644)
+}
@@ -0,0 +1,114 @@
+package tinydns
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v4/models"
+)
+
+func makeRec(zone, label, typ, target string) *models.RecordConfig {
+	r := &models.RecordConfig{Type: typ, TTL: 300}
+	r.SetLabel(label, zone)
+	if err := r.SetTarget(target); err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func TestCorrections(t *testing.T) {
+	dir := t.TempDir()
+	p, err := initTinydns(map[string]string{
+		"directory":   dir,
+		"location-in": "10, 192.168",
+		"location-ex": "",
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := p.(*tinydnsProvider)
+
+	zones := []struct {
+		unique string
+		tag    string
+		target string
+	}{
+		{"example.com!in", "in", "10.0.0.1"},
+		{"example.com!ex", "ex", "192.0.2.1"},
+		{"example.net", "", "192.0.2.2"},
+	}
+	for _, zone := range zones {
+		name, _, _ := strings.Cut(zone.unique, "!")
+		dc := &models.DomainConfig{
+			Name: name,
+			Metadata: map[string]string{
+				models.DomainUniqueName: zone.unique,
+				models.DomainTag:        zone.tag,
+			},
+			Records: models.Records{makeRec(name, "www", "A", zone.target)},
+		}
+		found, err := c.GetZoneRecords(name, dc.Metadata)
+		if err != nil {
+			t.Fatal(err)
+		}
+		corrections, n, err := c.GetZoneRecordsCorrections(dc, found)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 {
+			t.Errorf("%s: %d changes, want 1", zone.unique, n)
+		}
+		for _, correction := range corrections {
+			if err := correction.F(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Once written, there is nothing to do.
+		found, err = c.GetZoneRecords(name, dc.Metadata)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if corrections, _, err = c.GetZoneRecordsCorrections(dc, found); err != nil || len(corrections) != 0 {
+			t.Errorf("%s: corrections after writing = %v, %v; want none", zone.unique, corrections, err)
+		}
+	}
+
+	if got, err := c.ListZones(); err != nil || !reflect.DeepEqual(got, []string{"example.com", "example.net"}) {
+		t.Errorf("ListZones() = %v, %v; want example.com and example.net", got, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" && line[0] != '#' {
+			lines = append(lines, line)
+		}
+	}
+	want := []string{
+		"%ex",
+		"%in:10",
+		"%in:192.168",
+		"Zexample.com:example.com:hostmaster.example.com::::::::ex",
+		"+www.example.com:192.0.2.1:300::ex",
+		"Zexample.com:example.com:hostmaster.example.com::::::::in",
+		"+www.example.com:10.0.0.1:300::in",
+		"Zexample.net:example.net:hostmaster.example.net",
+		"+www.example.net:192.0.2.2:300",
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("data =\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+
+	// A tag must be a location.
+	dc := &models.DomainConfig{Name: "example.org", Metadata: map[string]string{models.DomainTag: "xx"}}
+	if _, _, err := c.GetZoneRecordsCorrections(dc, nil); err == nil {
+		t.Error("GetZoneRecordsCorrections() of a zone with an unknown location succeeded")
+	}
+}